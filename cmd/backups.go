@@ -0,0 +1,100 @@
+/**
+ * Copyright © 2021-present Sveltin contributors <github@sveltin.io>
+ *
+ * Use of this source code is governed by Apache 2.0 license
+ * that can be found in the LICENSE file.
+ */
+
+package cmd
+
+import (
+	"path/filepath"
+	"time"
+
+	"github.com/spf13/cobra"
+	sveltinerr "github.com/sveltinio/sveltin/internal/errors"
+	"github.com/sveltinio/sveltin/internal/ftpfs"
+	"github.com/sveltinio/sveltin/resources"
+	"github.com/sveltinio/sveltin/utils"
+)
+
+// LatestBackup is the name to refer to the most recent backup archive.
+const LatestBackup string = "latest"
+
+//=============================================================================
+
+var backupsCmd = &cobra.Command{
+	Use:   "backups",
+	Short: "Manage the backup archives created by the deploy command",
+	Long: resources.GetASCIIArt() + `
+Command used to list, inspect, prune and delete the backup archives
+created by the deploy command through its own subcommands.
+
+Deploy applies the retention policy set in the "backups" section of sveltin.json
+after every backup: "keep" is the number of archives to be kept and "maxAge"
+the max age for an archive (e.g. 30d). A zero value disables the rule.
+
+Run 'sveltin backups -h' for further details.
+`,
+	ValidArgs:             []string{"list", "inspect", "prune", "delete"},
+	Args:                  cobra.MatchAll(cobra.ExactArgs(1), cobra.OnlyValidArgs),
+	DisableFlagsInUseLine: true,
+}
+
+func init() {
+	rootCmd.AddCommand(backupsCmd)
+}
+
+//=============================================================================
+
+// listProjectBackups returns the backup archives for the project and the environment, newest first.
+func listProjectBackups() (string, []ftpfs.BackupArchive) {
+	backupsFolderPath := environmentBackupsFolder(withEnv)
+	pathToPkgFile := filepath.Join(cfg.pathMaker.GetRootFolder(), "package.json")
+	projectName, err := utils.RetrieveProjectName(cfg.fs, pathToPkgFile)
+	utils.ExitIfError(err)
+
+	archives, err := ftpfs.ListBackups(cfg.fs, backupsFolderPath, projectName)
+	utils.ExitIfError(err)
+	return backupsFolderPath, archives
+}
+
+// findBackup returns the archive by its name, or the most recent one for "latest".
+func findBackup(backupsFolderPath string, archives []ftpfs.BackupArchive, name string) *ftpfs.BackupArchive {
+	if len(archives) == 0 {
+		utils.ExitIfError(sveltinerr.NewBackupNotFoundError(backupsFolderPath))
+	}
+	if name == LatestBackup {
+		return &archives[0]
+	}
+	for i := range archives {
+		if archives[i].Name == name {
+			return &archives[i]
+		}
+	}
+	utils.ExitIfError(sveltinerr.NewFileNotFoundError(filepath.Join(backupsFolderPath, name)))
+	return nil
+}
+
+// applyBackupsRetention deletes the backup archives exceeding the retention policy set in sveltin.json.
+func applyBackupsRetention(dryRun bool) error {
+	retention := cfg.projectSettings.Backups
+	maxAge, err := ftpfs.ParseMaxAge(retention.MaxAge)
+	if err != nil {
+		return err
+	}
+	if retention.Keep <= 0 && maxAge == 0 {
+		return nil
+	}
+
+	_, archives := listProjectBackups()
+	toPrune := ftpfs.BackupsToPrune(archives, retention.Keep, maxAge, time.Now())
+	if len(toPrune) == 0 {
+		return nil
+	}
+	cfg.log.Infof("Pruning %d backup archives as per the retention policy", len(toPrune))
+	if dryRun {
+		return nil
+	}
+	return ftpfs.DeleteBackups(cfg.fs, toPrune)
+}
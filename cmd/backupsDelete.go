@@ -0,0 +1,66 @@
+/**
+ * Copyright © 2021-present Sveltin contributors <github@sveltin.io>
+ *
+ * Use of this source code is governed by Apache 2.0 license
+ * that can be found in the LICENSE file.
+ */
+
+package cmd
+
+import (
+	"github.com/spf13/cobra"
+	"github.com/sveltinio/prompti/confirm"
+	"github.com/sveltinio/sveltin/internal/ftpfs"
+	"github.com/sveltinio/sveltin/internal/markup"
+	"github.com/sveltinio/sveltin/tui/feedbacks"
+	"github.com/sveltinio/sveltin/utils"
+)
+
+var backupsDeleteCmd = &cobra.Command{
+	Use:     "delete [name...]",
+	Aliases: []string{"rm"},
+	Short:   "Delete backup archives",
+	Long: `Command used to delete one or more backup archives by name.
+
+Use 'latest' as name for the most recent backup archive.
+`,
+	Args: cobra.MinimumNArgs(1),
+	Run:  RunBackupsDeleteCmd,
+}
+
+// RunBackupsDeleteCmd is the actual work function.
+func RunBackupsDeleteCmd(cmd *cobra.Command, args []string) {
+	// Exit if running sveltin commands either from a not valid directory or not latest sveltin version.
+	isValidProject(true)
+
+	cfg.log.Plain(markup.H1("Deleting backup archives"))
+
+	backupsFolderPath, archives := listProjectBackups()
+	toDelete := []ftpfs.BackupArchive{}
+	for _, name := range args {
+		toDelete = append(toDelete, *findBackup(backupsFolderPath, archives, name))
+	}
+	feedbacks.ShowBackupsList(backupsFolderPath, toDelete)
+
+	isConfirm := isYes
+	if !isConfirm {
+		var err error
+		isConfirm, err = confirm.Run(&confirm.Config{Question: "Continue?"})
+		utils.ExitIfError(err)
+	}
+
+	if isConfirm {
+		utils.ExitIfError(ftpfs.DeleteBackups(cfg.fs, toDelete))
+		cfg.log.Success("Done\n")
+	}
+}
+
+func backupsDeleteCmdFlags(cmd *cobra.Command) {
+	cmd.Flags().StringVar(&withEnv, "env", DefaultEnvironment, "name of the environment the backups belong to")
+	cmd.Flags().BoolVarP(&isYes, "yes", "y", false, "do not prompt for confirmation")
+}
+
+func init() {
+	backupsDeleteCmdFlags(backupsDeleteCmd)
+	backupsCmd.AddCommand(backupsDeleteCmd)
+}
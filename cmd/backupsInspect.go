@@ -0,0 +1,51 @@
+/**
+ * Copyright © 2021-present Sveltin contributors <github@sveltin.io>
+ *
+ * Use of this source code is governed by Apache 2.0 license
+ * that can be found in the LICENSE file.
+ */
+
+package cmd
+
+import (
+	"github.com/spf13/cobra"
+	"github.com/sveltinio/sveltin/internal/ftpfs"
+	"github.com/sveltinio/sveltin/internal/markup"
+	"github.com/sveltinio/sveltin/tui/feedbacks"
+	"github.com/sveltinio/sveltin/utils"
+)
+
+var backupsInspectCmd = &cobra.Command{
+	Use:   "inspect [name]",
+	Short: "List the files within a backup archive",
+	Long: `Command used to list the files within a backup archive.
+
+Use 'latest' as name for the most recent backup archive.
+`,
+	Args: cobra.ExactArgs(1),
+	Run:  RunBackupsInspectCmd,
+}
+
+// RunBackupsInspectCmd is the actual work function.
+func RunBackupsInspectCmd(cmd *cobra.Command, args []string) {
+	// Exit if running sveltin commands either from a not valid directory or not latest sveltin version.
+	isValidProject(true)
+
+	cfg.log.Plain(markup.H1("Inspecting the backup archive"))
+
+	backupsFolderPath, archives := listProjectBackups()
+	archive := findBackup(backupsFolderPath, archives, args[0])
+
+	entries, err := ftpfs.InspectBackup(cfg.fs, archive.Path)
+	utils.ExitIfError(err)
+	feedbacks.ShowBackupContent(archive, entries)
+}
+
+func backupsInspectCmdFlags(cmd *cobra.Command) {
+	cmd.Flags().StringVar(&withEnv, "env", DefaultEnvironment, "name of the environment the backups belong to")
+}
+
+func init() {
+	backupsInspectCmdFlags(backupsInspectCmd)
+	backupsCmd.AddCommand(backupsInspectCmd)
+}
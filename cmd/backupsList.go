@@ -0,0 +1,49 @@
+/**
+ * Copyright © 2021-present Sveltin contributors <github@sveltin.io>
+ *
+ * Use of this source code is governed by Apache 2.0 license
+ * that can be found in the LICENSE file.
+ */
+
+package cmd
+
+import (
+	"github.com/spf13/cobra"
+	"github.com/sveltinio/sveltin/internal/markup"
+	"github.com/sveltinio/sveltin/tui/feedbacks"
+)
+
+var backupsListCmd = &cobra.Command{
+	Use:     "list",
+	Aliases: []string{"ls"},
+	Short:   "List the backup archives",
+	Long: `Command used to list the backup archives created by the deploy command, newest first.
+`,
+	DisableFlagsInUseLine: true,
+	Args:                  cobra.ExactArgs(0),
+	Run:                   RunBackupsListCmd,
+}
+
+// RunBackupsListCmd is the actual work function.
+func RunBackupsListCmd(cmd *cobra.Command, args []string) {
+	// Exit if running sveltin commands either from a not valid directory or not latest sveltin version.
+	isValidProject(true)
+
+	cfg.log.Plain(markup.H1("Listing the backup archives"))
+
+	backupsFolderPath, archives := listProjectBackups()
+	if len(archives) == 0 {
+		cfg.log.Important("No backup archives found")
+		return
+	}
+	feedbacks.ShowBackupsList(backupsFolderPath, archives)
+}
+
+func backupsListCmdFlags(cmd *cobra.Command) {
+	cmd.Flags().StringVar(&withEnv, "env", DefaultEnvironment, "name of the environment the backups belong to")
+}
+
+func init() {
+	backupsListCmdFlags(backupsListCmd)
+	backupsCmd.AddCommand(backupsListCmd)
+}
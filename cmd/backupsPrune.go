@@ -0,0 +1,87 @@
+/**
+ * Copyright © 2021-present Sveltin contributors <github@sveltin.io>
+ *
+ * Use of this source code is governed by Apache 2.0 license
+ * that can be found in the LICENSE file.
+ */
+
+package cmd
+
+import (
+	"time"
+
+	"github.com/spf13/cobra"
+	"github.com/sveltinio/sveltin/internal/ftpfs"
+	"github.com/sveltinio/sveltin/internal/markup"
+	"github.com/sveltinio/sveltin/tui/feedbacks"
+	"github.com/sveltinio/sveltin/utils"
+)
+
+var (
+	withKeep      int
+	withOlderThan string
+)
+
+var backupsPruneCmd = &cobra.Command{
+	Use:   "prune",
+	Short: "Delete the backup archives by count or age",
+	Long: `Command used to delete the oldest backup archives.
+
+Without flags, the retention policy set in sveltin.json is applied.
+`,
+	DisableFlagsInUseLine: true,
+	Args:                  cobra.ExactArgs(0),
+	Run:                   RunBackupsPruneCmd,
+}
+
+// RunBackupsPruneCmd is the actual work function.
+func RunBackupsPruneCmd(cmd *cobra.Command, args []string) {
+	// Exit if running sveltin commands either from a not valid directory or not latest sveltin version.
+	isValidProject(true)
+
+	cfg.log.Plain(markup.H1("Pruning the backup archives"))
+
+	keep := cfg.projectSettings.Backups.Keep
+	if cmd.Flags().Changed("keep") {
+		keep = withKeep
+	}
+	olderThan := cfg.projectSettings.Backups.MaxAge
+	if cmd.Flags().Changed("older-than") {
+		olderThan = withOlderThan
+	}
+	maxAge, err := ftpfs.ParseMaxAge(olderThan)
+	utils.ExitIfError(err)
+
+	if keep <= 0 && maxAge == 0 {
+		cfg.log.Important("No retention policy set. Use --keep or --older-than, or set them in sveltin.json")
+		return
+	}
+
+	backupsFolderPath, archives := listProjectBackups()
+	toPrune := ftpfs.BackupsToPrune(archives, keep, maxAge, time.Now())
+	if len(toPrune) == 0 {
+		cfg.log.Info("Nothing to prune")
+		return
+	}
+
+	if isDryRun {
+		feedbacks.ShowDryRunMessage()
+	}
+	feedbacks.ShowBackupsList(backupsFolderPath, toPrune)
+	if !isDryRun {
+		utils.ExitIfError(ftpfs.DeleteBackups(cfg.fs, toPrune))
+	}
+	cfg.log.Successf("Done! %d backup archives deleted\n", len(toPrune))
+}
+
+func backupsPruneCmdFlags(cmd *cobra.Command) {
+	cmd.Flags().StringVar(&withEnv, "env", DefaultEnvironment, "name of the environment the backups belong to")
+	cmd.Flags().IntVarP(&withKeep, "keep", "k", 0, "number of the most recent backup archives to be kept")
+	cmd.Flags().StringVar(&withOlderThan, "older-than", "", "delete the backup archives older than this, e.g. 30d or 12h")
+	cmd.Flags().BoolVarP(&isDryRun, "dryRun", "d", false, "dry run")
+}
+
+func init() {
+	backupsPruneCmdFlags(backupsPruneCmd)
+	backupsCmd.AddCommand(backupsPruneCmd)
+}
@@ -14,6 +14,7 @@ import (
 	"github.com/spf13/cobra"
 	"github.com/sveltinio/sveltin/helpers"
 	"github.com/sveltinio/sveltin/internal/markup"
+	"github.com/sveltinio/sveltin/internal/shell"
 	"github.com/sveltinio/sveltin/resources"
 	"github.com/sveltinio/sveltin/tui/activehelps"
 	"github.com/sveltinio/sveltin/utils"
@@ -32,13 +33,17 @@ It wraps vite build command.
 
 Ensure to edit env.production and .sveltin.toml files to reflect
 your production environment.
+
+Use --env <name> to build with the VITE_PUBLIC_BASE_PATH set in the .env.<name> file.
+
+The pre-build and post-build hooks set in sveltin.json run before and after the build.
 `,
 	DisableFlagsInUseLine: true,
 	Args:                  cobra.ExactArgs(0),
 	Run:                   RunBuildCmd,
 	ValidArgsFunction: func(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
 		var comps []string
-		comps = cobra.AppendActiveHelp(comps, activehelps.Hint("[WARN] This command does not take any argument."))
+		comps = cobra.AppendActiveHelp(comps, activehelps.Hint("[WARN] This command does not take any argument but accepts flags."))
 		return comps, cobra.ShellCompDirectiveDefault
 	},
 }
@@ -50,17 +55,33 @@ func RunBuildCmd(cmd *cobra.Command, args []string) {
 
 	cfg.log.Plain(markup.H1("Building the Sveltin project"))
 
+	// without --env, the base path is read from the .env.production file, if any
+	if cmd.Flags().Changed("env") {
+		loadEnvironment(withEnv, false)
+	}
+
 	pathToPkgFile := filepath.Join(cfg.pathMaker.GetRootFolder(), "package.json")
 	npmClient, err := utils.RetrievePackageManagerFromPkgJSON(cfg.fs, pathToPkgFile)
 	utils.ExitIfError(err)
 
+	err = runHook(shell.PreBuildHook, cfg.projectSettings.Hooks.PreBuild, os.Stdout)
+	utils.ExitIfError(err)
+
 	os.Setenv("VITE_PUBLIC_BASE_PATH", cfg.prodData.BaseURL)
 	err = helpers.RunPMCommand(npmClient.Name, "build", "", nil, false)
 	utils.ExitIfError(err)
 
+	err = runHook(shell.PostBuildHook, cfg.projectSettings.Hooks.PostBuild, os.Stdout)
+	utils.ExitIfError(err)
+
 	cfg.log.Success("Done\n")
 }
 
+func buildCmdFlags(cmd *cobra.Command) {
+	cmd.Flags().StringVar(&withEnv, "env", DefaultEnvironment, "name of the environment to build for, loaded from the .env.<name> file")
+}
+
 func init() {
+	buildCmdFlags(buildCmd)
 	rootCmd.AddCommand(buildCmd)
 }
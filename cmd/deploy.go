@@ -8,18 +8,24 @@
 package cmd
 
 import (
+	"errors"
 	"fmt"
+	"io"
 	"os"
 	"path/filepath"
 	"sort"
 	"strings"
+	"time"
 
 	"github.com/spf13/afero"
 	"github.com/spf13/cobra"
 	"github.com/sveltinio/prompti/confirm"
 	"github.com/sveltinio/sveltin/common"
+	sveltinerr "github.com/sveltinio/sveltin/internal/errors"
 	"github.com/sveltinio/sveltin/internal/ftpfs"
 	"github.com/sveltinio/sveltin/internal/markup"
+	"github.com/sveltinio/sveltin/internal/secrets"
+	"github.com/sveltinio/sveltin/internal/shell"
 	"github.com/sveltinio/sveltin/internal/tpltypes"
 	"github.com/sveltinio/sveltin/tui/activehelps"
 	"github.com/sveltinio/sveltin/tui/feedbacks"
@@ -35,18 +41,46 @@ const (
 	EntryTypeFile   EntryType = 1
 )
 
+// Suffixes for the sibling folders used by the atomic deploy.
+const (
+	StagingFolderSuffix  string = ftpfs.StagingFolderSuffix
+	PreviousFolderSuffix string = ftpfs.PreviousFolderSuffix
+)
+
 var (
 	isDryRun        bool
 	isBackup        bool
+	isIncremental   bool
+	isAtomic        bool
 	withExclude     []string
 	withExcludeFile string
+	withParallel    int
+	withTarget      string
+	isResume        bool
+	withRetries     int
+	isVerify        bool
+	isChecksums     bool
+	isPreview       bool
 )
 
 var deployCmd = &cobra.Command{
 	Use:     "deploy",
 	Aliases: []string{"publish"},
-	Short:   "Deploy your website over FTP",
-	Long: `Command used to deploy the project on your hosting platform over FTP.
+	Short:   "Deploy your website over FTP, SFTP, to an S3 bucket or a git branch",
+	Long: `Command used to deploy the project on your hosting platform over FTP or SFTP, to an S3-compatible bucket
+or to a branch of the project git repository (e.g. gh-pages).
+
+Set DEPLOY_PROTOCOL in the .env.production file to choose the protocol (default: ftp).
+Use --env <name> to deploy using the settings from the .env.<name> file, e.g. --env staging.
+Use --yes to skip the confirmation prompt and --report json to get a JSON report on stdout,
+which implies --yes.
+Use --resume to continue a failed deploy from the last uploaded file.
+The pre-deploy and post-deploy hooks set in sveltin.json run before and after the deploy, except on dry-run.
+The changes to the remote folder are previewed as a tree before confirming, unless --preview=false.
+Use --verify to compare the remote folder with the local build once done, or run 'sveltin deploy verify'.
+Use --target dir:<path> to sync the build output to a local or mounted folder instead.
+Use --target git:<branch> to commit the build output to the branch and push it to GIT_REMOTE instead.
+Keep files like CNAME in the branch with --exclude CNAME.
 `,
 	DisableFlagsInUseLine: true,
 	Args:                  cobra.ExactArgs(0),
@@ -63,124 +97,359 @@ func DeployCmdRun(cmd *cobra.Command, args []string) {
 	// Exit if running sveltin commands either from a not valid directory or not latest sveltin version.
 	isValidProject(true)
 
-	cfg.log.Plain(markup.H1("Deploy your website to the FTP server"))
+	setupNonInteractiveMode()
 
-	// if --excludeFile is set, combines its lines with values from the --exclude flag.
-	if len(withExcludeFile) != 0 {
-		lines, err := common.ReadFileLineByLine(cfg.fs, withExcludeFile)
-		utils.ExitIfError(err)
-		withExclude = common.Union(withExclude, lines)
-	}
+	loadEnvironment(withEnv, true)
+	loadExcludeList()
 
-	ftpConnectionConfig := newFTPConnectionConfig(cfg.prodData)
+	cfg.log.Plain(markup.H1("Deploy your website to " + deployTargetLabel()))
 
-	ftpConn := ftpfs.NewFTPServerConnection(ftpConnectionConfig)
-	ftpConn.SetRootFolder(cfg.prodData.FTPServerFolder)
-	ftpConn.SetLogger(cfg.log)
+	exitIfDeployError(checkDeployFlags())
 
-	err := ftpfs.DialAction(ftpConn).Run()
-	utils.ExitIfError(err)
+	remoteServer, noOpAction := connectRemoteServer()
 
-	err = ftpfs.LoginAction(ftpConn).Run()
-	utils.ExitIfError(err)
-
-	// prevent the remote FTP server to close the idle connection
-	noOpAction := ftpfs.IdleAction(ftpConn)
-	err = noOpAction.Run()
-	utils.ExitIfError(err)
+	if deployReport == nil {
+		feedbacks.ShowDeployCommandWarningMessages(isBackup, isIncremental, isAtomic)
 
-	feedbacks.ShowDeployCommandWarningMessages(isBackup)
+		if isDryRun {
+			feedbacks.ShowDryRunMessage()
+		}
 
-	if isDryRun {
-		feedbacks.ShowDryRunMessage()
+		// a resumed deploy only uploads the files left by the failed one
+		if isPreview && !isResume {
+			showDeployPreview(remoteServer)
+		}
 	}
 
-	isConfirm, err := confirm.Run(&confirm.Config{Question: "Continue?"})
-	utils.ExitIfError(err)
+	isConfirm := isYes
+	if !isConfirm {
+		var err error
+		isConfirm, err = confirm.Run(&confirm.Config{Question: "Continue?"})
+		exitIfDeployError(err)
+	}
 
 	if isConfirm {
-		// create a local tar archive as backup for the remote folder content
-		if isBackup {
-			backupsFolderPath := filepath.Join(cfg.pathMaker.GetRootFolder(), BackupsFolder)
-			utils.ExitIfError(common.MkDir(cfg.fs, backupsFolderPath))
+		if !isDryRun {
+			exitIfDeployError(recordDeployEnvironment(withEnv))
+		}
+
+		kitPagesFolder := cfg.projectSettings.SvelteKit.Adapter.Pages
+		kitAssetsFolder := cfg.projectSettings.SvelteKit.Adapter.Assets
+
+		runDeployHook(shell.PreDeployHook, cfg.projectSettings.Hooks.PreDeploy)
+
+		// compute size and checksum for the files to be deployed
+		localManifest, err := makeLocalManifest(cfg.fs, kitPagesFolder, kitAssetsFolder)
+		exitIfDeployError(err)
+
+		var checkpoint *ftpfs.Checkpoint
+		if isResume {
+			checkpoint = resumeCheckpoint(localManifest)
+		}
+
+		// create a local tar archive as backup for the remote folder content.
+		// When resuming, the remote folder content is the partial deploy.
+		if isBackup && checkpoint == nil {
+			backupsFolderPath := environmentBackupsFolder(withEnv)
+			exitIfDeployError(common.MkDir(cfg.fs, backupsFolderPath))
 			pathToPkgFile := filepath.Join(cfg.pathMaker.GetRootFolder(), "package.json")
 			projectName, err := utils.RetrieveProjectName(cfg.fs, pathToPkgFile)
-			utils.ExitIfError(err)
-			err = ftpfs.BackupAction(ftpConn, cfg.fs, filepath.Join(backupsFolderPath, projectName), isDryRun).Run()
-			utils.ExitIfError(err)
+			exitIfDeployError(err)
+			err = ftpfs.BackupAction(remoteServer, cfg.fs, filepath.Join(backupsFolderPath, projectName), isDryRun).Run()
+			exitIfDeployError(err)
+			exitIfDeployError(applyBackupsRetention(isDryRun))
+		}
+
+		if isAtomic {
+			runAtomicDeploy(remoteServer, noOpAction, localManifest, kitPagesFolder, kitAssetsFolder)
+		} else {
+			isDeployed := false
+			if isIncremental {
+				isDeployed = runIncrementalDeploy(remoteServer, noOpAction, localManifest, kitPagesFolder, kitAssetsFolder)
+			}
+			if !isDeployed {
+				runFullDeploy(remoteServer, noOpAction, localManifest, checkpoint, kitPagesFolder, kitAssetsFolder)
+			}
+			saveManifest(remoteServer, localManifest)
+			// the deploy is completed, nothing to resume
+			if !isDryRun {
+				exitIfDeployError(removeCheckpoint())
+			}
 		}
 
+		var verifyErr error
+		if isVerify {
+			if isDryRun {
+				cfg.log.Important("Nothing to verify on dry-run")
+			} else {
+				verifyErr = verifyDeploy(remoteServer, localManifest)
+			}
+		}
+
+		// close the connection
+		err = ftpfs.LogoutAction(remoteServer).Run()
+		exitIfDeployError(err)
+		exitIfDeployError(verifyErr)
+
+		runDeployHook(shell.PostDeployHook, cfg.projectSettings.Hooks.PostDeploy)
+
+		cfg.log.Success("Done\n")
+		writeDeployReport(nil)
+	}
+}
+
+// showDeployPreview prints the changes the deploy makes to the remote folder, computed from the remote
+// listing and the local build, so that a wrong remote folder can be caught before confirming.
+func showDeployPreview(server ftpfs.RemoteServer) {
+	localManifest, err := makeLocalManifest(cfg.fs, cfg.projectSettings.SvelteKit.Adapter.Pages, cfg.projectSettings.SvelteKit.Adapter.Assets)
+	exitIfDeployError(err)
+	remoteFiles, err := server.ListFiles()
+	exitIfDeployError(err)
+
+	// the incremental deploy falls back to a full one when there is no manifest
+	var previousManifest *ftpfs.Manifest
+	if isIncremental {
+		previousManifest, _ = ftpfs.FetchManifest(server)
+	}
+
+	remoteFolder := deployFolder()
+	if isGitDeploy() {
+		remoteFolder = fmt.Sprintf("the '%s' branch", newGitConnectionConfig(cfg.prodData).Branch)
+	}
+	preview := ftpfs.NewDeployPreview(remoteFiles, localManifest, previousManifest, ftpfs.NewExcludeRules(withExclude))
+	feedbacks.ShowDeployPreview(remoteFolder, preview)
+}
+
+// runDeployHook runs the deploy hook unless on dry-run. With --report, the output
+// of the hook commands is sent to stderr so that stdout only gets the final report.
+func runDeployHook(hook string, commands []string) {
+	if len(commands) == 0 {
+		return
+	}
+	if isDryRun {
+		cfg.log.Importantf("Skipping the %s hook on dry-run", hook)
+		return
+	}
+	out := io.Writer(os.Stdout)
+	if deployReport != nil {
+		out = os.Stderr
+	}
+	exitIfDeployError(runHook(hook, commands, out))
+}
+
+// runFullDeploy deletes the existing content from the remote folder and uploads all the files.
+// When resuming from a checkpoint, the content is not deleted and the folders and files
+// already logged by the checkpoint are skipped. Otherwise a new checkpoint is started.
+func runFullDeploy(server ftpfs.RemoteServer, noOpAction *ftpfs.Client, localManifest *ftpfs.Manifest, checkpoint *ftpfs.Checkpoint, kitPagesFolder, kitAssetsFolder string) {
+	if checkpoint != nil {
+		cfg.log.Infof("Resuming the deploy started on %s", checkpoint.Header.StartedAt.Format("2006-01-02 15:04:05"))
+	} else {
 		// delete content from the remote folder with exclude list
-		cfg.log.Important(fmt.Sprintf("If present, the following files will not be deleted from the remote folder: %s", strings.Join(withExclude, ", ")))
-		err = ftpfs.DeleteAllAction(ftpConn, withExclude, isDryRun).Run()
-		utils.ExitIfError(err)
+		cfg.log.Important(fmt.Sprintf("The remote files and folders matching the following exclude rules will not be deleted: %s", strings.Join(withExclude, ", ")))
+		err := ftpfs.DeleteAllAction(server, withExclude, isDryRun).Run()
+		exitIfDeployError(err)
+		checkpoint = newCheckpoint(localManifest)
+	}
 
-		// create and update content from "kit.adapter.pages" folder
-		kitPagesFolder := cfg.projectSettings.SvelteKit.Adapter.Pages
-		pagesFoldersList, err := walkLocal(cfg.fs, EntryTypeFolder, kitPagesFolder, true)
-		utils.ExitIfError(err)
+	ftpfs.SetCheckpoint(checkpoint)
+	defer ftpfs.SetCheckpoint(nil)
+	uploadAll(server, noOpAction, checkpoint, kitPagesFolder, kitAssetsFolder)
+}
+
+// runAtomicDeploy uploads all the files into a staging folder next to the remote one and,
+// once every upload succeeded, swaps it into place keeping the replaced content as previous release.
+func runAtomicDeploy(server ftpfs.RemoteServer, noOpAction *ftpfs.Client, localManifest *ftpfs.Manifest, kitPagesFolder, kitAssetsFolder string) {
+	liveFolder := strings.TrimSuffix(deployFolder(), "/")
+	stagingFolder := liveFolder + StagingFolderSuffix
+	previousFolder := liveFolder + PreviousFolderSuffix
+
+	// remove leftovers from a previously failed atomic deploy
+	err := ftpfs.RemoveDirAction(server, stagingFolder, isDryRun).Run()
+	exitIfDeployError(err)
+
+	// files matching the exclude rules must survive the swap
+	preservedFiles := map[string][]byte{}
+	rules := ftpfs.NewExcludeRules(withExclude)
+	if !rules.IsEmpty() {
+		remoteFiles, err := server.ListFiles()
+		exitIfDeployError(err)
+		for _, remoteFile := range remoteFiles {
+			if !rules.Excluded(remoteFile.Path, false) {
+				continue
+			}
+			content, err := server.ReadFile(remoteFile.Path)
+			exitIfDeployError(err)
+			preservedFiles[remoteFile.Path] = content
+		}
+	}
 
-		cfg.log.Infof("Creating remote folders structure for '%s'", kitPagesFolder)
-		err = ftpfs.MakeDirsAction(ftpConn, pagesFoldersList, isDryRun).Run()
-		utils.ExitIfError(err)
+	cfg.log.Infof("Uploading to the staging folder '%s'", stagingFolder)
+	server.SetRootFolder(stagingFolder)
+	uploadAll(server, noOpAction, nil, kitPagesFolder, kitAssetsFolder)
 
-		// prevent the remote FTP server to close the idle connection
-		err = noOpAction.Run()
-		utils.ExitIfError(err)
+	preservedNames := make([]string, 0, len(preservedFiles))
+	for name := range preservedFiles {
+		preservedNames = append(preservedNames, name)
+	}
+	sort.Strings(preservedNames)
+	if dirs := ftpfs.MissingDirs(preservedNames, localManifest); len(dirs) > 0 {
+		err = ftpfs.MakeDirsAction(server, dirs, isDryRun).Run()
+		exitIfDeployError(err)
+	}
+	for _, name := range preservedNames {
+		content := preservedFiles[name]
+		cfg.log.Infof("Copying '%s' to the staging folder", name)
+		err = ftpfs.WriteFileAction(server, name, content, isDryRun).Run()
+		exitIfDeployError(err)
+	}
+	saveManifest(server, localManifest)
+
+	server.SetRootFolder(liveFolder)
+	cfg.log.Infof("Swapping the staging folder into place. The replaced content is kept as '%s'", previousFolder)
+	err = ftpfs.SwapAction(server, stagingFolder, previousFolder, isDryRun).Run()
+	exitIfDeployError(err)
+}
 
-		cfg.log.Infof("Uploading files to the remote folder '%s'", kitPagesFolder)
-		pagesFilesList, err := walkLocal(cfg.fs, EntryTypeFile, kitPagesFolder, true)
-		utils.ExitIfError(err)
+// uploadAll creates the folders structure and uploads all the files from the adapter pages and assets folders.
+// The folders and files already logged by the checkpoint, if any, are skipped.
+func uploadAll(server ftpfs.RemoteServer, noOpAction *ftpfs.Client, checkpoint *ftpfs.Checkpoint, kitPagesFolder, kitAssetsFolder string) {
+	// create and update content from "kit.adapter.pages" folder
+	pagesFoldersList, err := walkLocal(cfg.fs, EntryTypeFolder, kitPagesFolder, true)
+	exitIfDeployError(err)
+	pagesFoldersList = pendingFolders(checkpoint, pagesFoldersList)
 
-		err = ftpfs.UploadAction(ftpConn, cfg.fs, kitPagesFolder, pagesFilesList, true, isDryRun).Run()
-		utils.ExitIfError(err)
+	cfg.log.Infof("Creating remote folders structure for '%s'", kitPagesFolder)
+	err = ftpfs.MakeDirsAction(server, pagesFoldersList, isDryRun).Run()
+	exitIfDeployError(err)
+
+	// prevent the remote FTP server to close the idle connection
+	err = noOpAction.Run()
+	exitIfDeployError(err)
+
+	cfg.log.Infof("Uploading files to the remote folder '%s'", kitPagesFolder)
+	pagesFilesList, err := walkLocal(cfg.fs, EntryTypeFile, kitPagesFolder, true)
+	exitIfDeployError(err)
+	pagesFilesList = pendingFiles(checkpoint, pagesFilesList)
+
+	err = ftpfs.UploadAction(server, cfg.fs, kitPagesFolder, pagesFilesList, true, isDryRun).Run()
+	exitIfDeployError(err)
+
+	// prevent the remote FTP server to close the idle connection
+	err = noOpAction.Run()
+	exitIfDeployError(err)
+
+	/**
+	* Check if pages and assets props for adapter-static are differents.
+	* If true, upload the entire kit.adapter.assets folder.
+	**/
+	if kitPagesFolder != kitAssetsFolder {
+		assetsFoldersList, err := walkLocal(cfg.fs, EntryTypeFolder, kitAssetsFolder, false)
+		exitIfDeployError(err)
+		assetsFoldersList = pendingFolders(checkpoint, assetsFoldersList)
+
+		cfg.log.Infof("Creating remote folders structure for '%s'", kitAssetsFolder)
+		err = ftpfs.MakeDirsAction(server, assetsFoldersList, isDryRun).Run()
+		exitIfDeployError(err)
 
 		// prevent the remote FTP server to close the idle connection
 		err = noOpAction.Run()
-		utils.ExitIfError(err)
-
-		/**
-		* Check if pages and assets props for adapter-static are differents.
-		* If true, upload the entire kit.adapter.assets folder.
-		**/
-		kitAssetsFolder := cfg.projectSettings.SvelteKit.Adapter.Assets
-		if kitPagesFolder != kitAssetsFolder {
-			assetsFoldersList, err := walkLocal(cfg.fs, EntryTypeFolder, kitAssetsFolder, false)
-			utils.ExitIfError(err)
+		exitIfDeployError(err)
 
-			cfg.log.Infof("Creating remote folders structure for '%s'", kitAssetsFolder)
-			err = ftpfs.MakeDirsAction(ftpConn, assetsFoldersList, isDryRun).Run()
-			utils.ExitIfError(err)
+		cfg.log.Infof("Uploading files to the remote folder '%s'", kitAssetsFolder)
+		assetsFilesList, err := walkLocal(cfg.fs, EntryTypeFile, kitAssetsFolder, false)
+		exitIfDeployError(err)
+		assetsFilesList = pendingFiles(checkpoint, assetsFilesList)
 
-			// prevent the remote FTP server to close the idle connection
-			err = noOpAction.Run()
-			utils.ExitIfError(err)
+		err = ftpfs.UploadAction(server, cfg.fs, kitPagesFolder, assetsFilesList, false, isDryRun).Run()
+		exitIfDeployError(err)
 
-			cfg.log.Infof("Uploading files to the remote folder '%s'", kitAssetsFolder)
-			assetsFilesList, err := walkLocal(cfg.fs, EntryTypeFile, kitAssetsFolder, false)
-			utils.ExitIfError(err)
+		// prevent the remote FTP server to close the idle connection
+		err = noOpAction.Run()
+		exitIfDeployError(err)
+	}
+}
 
-			err = ftpfs.UploadAction(ftpConn, cfg.fs, kitPagesFolder, assetsFilesList, false, isDryRun).Run()
-			utils.ExitIfError(err)
+// runIncrementalDeploy uploads the added and changed files and deletes the removed ones
+// by comparing the local build with the manifest saved on the remote folder by the previous deploy.
+// It returns false when the remote manifest is not available.
+func runIncrementalDeploy(server ftpfs.RemoteServer, noOpAction *ftpfs.Client, localManifest *ftpfs.Manifest, kitPagesFolder, kitAssetsFolder string) bool {
+	remoteManifest, err := ftpfs.FetchManifest(server)
+	if err != nil {
+		cfg.log.Important("No manifest found on the remote folder. Running a full deploy")
+		return false
+	}
 
-			// prevent the remote FTP server to close the idle connection
-			err = noOpAction.Run()
-			utils.ExitIfError(err)
+	diff := localManifest.Diff(remoteManifest)
+	if deployReport != nil {
+		deployReport.AddSkipped(diff.Unchanged...)
+	}
+	cfg.log.Infof("Compared to the previous deploy: %d added, %d changed, %d removed, %d unchanged",
+		len(diff.Added), len(diff.Changed), len(diff.Removed), len(diff.Unchanged))
+
+	// delete files removed from the local build, except the excluded ones
+	rules := ftpfs.NewExcludeRules(withExclude)
+	filesToDelete := []string{}
+	for _, file := range diff.Removed {
+		if !rules.Excluded(file, false) {
+			filesToDelete = append(filesToDelete, file)
 		}
+	}
+	if len(filesToDelete) > 0 {
+		cfg.log.Infof("Deleting %d files from the remote folder", len(filesToDelete))
+		err = ftpfs.DeleteFilesAction(server, filesToDelete, isDryRun).Run()
+		exitIfDeployError(err)
+	}
 
-		// close the connection
-		err = ftpfs.LogoutAction(ftpConn).Run()
-		utils.ExitIfError(err)
+	filesToUpload := append(diff.Added, diff.Changed...)
+	if dirs := ftpfs.MissingDirs(filesToUpload, remoteManifest); len(dirs) > 0 {
+		cfg.log.Infof("Creating %d missing remote folders", len(dirs))
+		err = ftpfs.MakeDirsAction(server, dirs, isDryRun).Run()
+		exitIfDeployError(err)
+	}
 
-		cfg.log.Success("Done\n")
+	// prevent the remote FTP server to close the idle connection
+	err = noOpAction.Run()
+	exitIfDeployError(err)
+
+	pagesFilesList, assetsFilesList := splitLocalFiles(localManifest.LocalFiles(filesToUpload), kitPagesFolder, kitAssetsFolder)
+	if len(pagesFilesList) > 0 {
+		cfg.log.Infof("Uploading %d files to the remote folder '%s'", len(pagesFilesList), kitPagesFolder)
+		err = ftpfs.UploadAction(server, cfg.fs, kitPagesFolder, pagesFilesList, true, isDryRun).Run()
+		exitIfDeployError(err)
+	}
+	if len(assetsFilesList) > 0 {
+		cfg.log.Infof("Uploading %d files to the remote folder '%s'", len(assetsFilesList), kitAssetsFolder)
+		err = ftpfs.UploadAction(server, cfg.fs, kitPagesFolder, assetsFilesList, false, isDryRun).Run()
+		exitIfDeployError(err)
 	}
+
+	// prevent the remote FTP server to close the idle connection
+	err = noOpAction.Run()
+	exitIfDeployError(err)
+
+	return true
 }
 
 func deployCmdFlags(cmd *cobra.Command) {
 	cmd.Flags().BoolVarP(&isBackup, "backup", "b", true, "create a tar archive for the existing content on the remote FTP server")
 	cmd.Flags().BoolVarP(&isDryRun, "dryRun", "d", false, "dry run")
-	cmd.Flags().StringArrayVarP(&withExclude, "exclude", "e", []string{".htaccess"}, "list of files to not be deleted from the FTP server. Default: .htaccess")
-	cmd.Flags().StringVar(&withExcludeFile, "withExcludeFile", "", "path to the file containing the list of files to not be deleted from the FTP server")
+	cmd.Flags().BoolVarP(&isIncremental, "incremental", "i", false, "upload changed files only and delete the removed ones, based on the manifest saved by the previous deploy")
+	cmd.Flags().BoolVarP(&isAtomic, "atomic", "a", false, "upload into a staging folder next to the remote one and swap it into place once done, keeping the previous release")
+	cmd.MarkFlagsMutuallyExclusive("atomic", "incremental")
+	cmd.Flags().StringArrayVarP(&withExclude, "exclude", "e", []string{".htaccess"}, "gitignore-style pattern of files and folders to not be deleted from the remote server (repeatable). Default: .htaccess")
+	cmd.Flags().StringVar(&withExcludeFile, "withExcludeFile", "", "path to the file with the gitignore-style patterns of files and folders to not be deleted from the remote server. Default: .sveltinignore")
+	cmd.Flags().IntVarP(&withParallel, "parallel", "p", 1, "number of concurrent connections used to upload the files")
+	cmd.Flags().StringVarP(&withTarget, "target", "t", "", "deploy target overriding DEPLOY_PROTOCOL, e.g. dir:/srv/www/site to sync a local or mounted folder or git:gh-pages to commit to a branch")
+	cmd.Flags().StringVar(&withEnv, "env", DefaultEnvironment, "name of the environment to deploy to, loaded from the .env.<name> file")
+	cmd.Flags().BoolVarP(&isYes, "yes", "y", false, "do not prompt for confirmation and print plain progress lines, e.g. when running in CI")
+	cmd.Flags().BoolVar(&isResume, "resume", false, "continue a failed deploy from the last uploaded file instead of starting over")
+	cmd.MarkFlagsMutuallyExclusive("resume", "atomic")
+	cmd.MarkFlagsMutuallyExclusive("resume", "incremental")
+	cmd.Flags().IntVar(&withRetries, "retries", 3, "number of times an FTP operation failed with a transient error is retried after reconnecting")
+	cmd.Flags().StringVar(&withReport, "report", "", "print a machine-readable report at the end of the deploy, implies --yes. Valid values: json")
+	cmd.Flags().BoolVar(&isVerify, "verify", false, "compare the remote folder with the local build once the deploy is done and exit with an error on drift")
+	cmd.Flags().BoolVar(&isChecksums, "checksums", false, "with --verify, download the remote files to compare their checksums too")
+	cmd.Flags().BoolVar(&isPreview, "preview", true, "list the files to be added, overwritten, deleted and preserved on the remote folder before confirming")
 }
 
 func init() {
@@ -190,15 +459,405 @@ func init() {
 
 //=============================================================================
 
-func newFTPConnectionConfig(data tpltypes.EnvProductionData) *ftpfs.FTPConnectionConfig {
+// remoteServerFactory returns the RemoteServer the commands connect to.
+// Tests replace it to deploy to an in-memory server.
+var remoteServerFactory = newRemoteServer
+
+// newRemoteServer returns the RemoteServer implementation for the --target flag when set,
+// otherwise for the protocol set as DEPLOY_PROTOCOL in the env file. Defaults to FTP when not set.
+func newRemoteServer(data tpltypes.EnvProductionData, target string) (ftpfs.RemoteServer, error) {
+	if target != "" {
+		kind, location, err := parseTarget(target)
+		if err != nil {
+			return nil, err
+		}
+		switch kind {
+		case ftpfs.DirProtocol:
+			if err := checkDirTarget(location); err != nil {
+				return nil, err
+			}
+			return ftpfs.NewLocalDirConnection(cfg.fs), nil
+		case ftpfs.GitProtocol:
+			return ftpfs.NewGitConnection(newGitConnectionConfig(data)), nil
+		}
+	}
+
+	switch strings.ToLower(data.DeployProtocol) {
+	case "", ftpfs.FTPProtocol:
+		config, err := newFTPConnectionConfig(data)
+		if err != nil {
+			return nil, err
+		}
+		return ftpfs.NewFTPServerConnection(config), nil
+	case ftpfs.SFTPProtocol:
+		config, err := newSFTPConnectionConfig(data)
+		if err != nil {
+			return nil, err
+		}
+		return ftpfs.NewSFTPServerConnection(config), nil
+	case ftpfs.S3Protocol:
+		return ftpfs.NewS3ServerConnection(newS3ConnectionConfig(data)), nil
+	case ftpfs.GitProtocol:
+		return ftpfs.NewGitConnection(newGitConnectionConfig(data)), nil
+	default:
+		return nil, sveltinerr.NewOptionNotValidError(data.DeployProtocol, []string{ftpfs.FTPProtocol, ftpfs.SFTPProtocol, ftpfs.S3Protocol, ftpfs.GitProtocol})
+	}
+}
+
+// parseTarget splits the --target flag value (e.g. dir:/srv/www/site or git:gh-pages) into its kind and location.
+func parseTarget(target string) (string, string, error) {
+	validTargets := []string{ftpfs.DirProtocol, ftpfs.GitProtocol}
+	parts := strings.SplitN(target, ":", 2)
+	if len(parts) != 2 || parts[1] == "" || !common.Contains(validTargets, parts[0]) {
+		return "", "", sveltinerr.NewOptionNotValidError(target, []string{"dir:<path>", "git:<branch>"})
+	}
+	return parts[0], parts[1], nil
+}
+
+// checkDeployFlags returns an error when the flags cannot be used with the deploy target.
+// The atomic deploy needs a parent folder for the staging and previous folders next to the
+// remote one, otherwise they resolve to the server working directory within the live content.
+// The S3 bucket root is fine, its objects are listed without the staging and previous folders.
+func checkDeployFlags() error {
+	if isGitDeploy() && (isAtomic || isResume) {
+		return sveltinerr.NewDefaultError(errors.New("--atomic and --resume cannot be used to deploy to a git branch, a commit is already atomic"))
+	}
+	if isAtomic && strings.Trim(deployFolder(), "/") == "" && !isS3Deploy() {
+		return sveltinerr.NewDefaultError(errors.New("--atomic cannot be used to deploy to the server root folder, set a remote folder"))
+	}
+	return nil
+}
+
+// checkDirTarget returns an error when the dir target is the project root, one of its ancestors
+// or holds the build or backups folders: deleting the remote content would wipe them out.
+func checkDirTarget(location string) error {
+	target, err := filepath.Abs(location)
+	if err != nil {
+		return err
+	}
+	rootFolder, err := filepath.Abs(cfg.pathMaker.GetRootFolder())
+	if err != nil {
+		return err
+	}
+	protectedFolders := []string{
+		rootFolder,
+		filepath.Join(rootFolder, cfg.projectSettings.SvelteKit.Adapter.Pages),
+		filepath.Join(rootFolder, cfg.projectSettings.SvelteKit.Adapter.Assets),
+		filepath.Join(rootFolder, BackupsFolder),
+	}
+	for _, folder := range protectedFolders {
+		if isWithinFolder(folder, target) {
+			return sveltinerr.NewDefaultError(fmt.Errorf("the target folder '%s' cannot be used, it contains the project folder '%s'", location, folder))
+		}
+	}
+	return nil
+}
+
+// isWithinFolder reports whether name is the folder itself or is nested within it.
+func isWithinFolder(name, folder string) bool {
+	rel, err := filepath.Rel(folder, name)
+	return err == nil && rel != ".." && !strings.HasPrefix(rel, ".."+string(filepath.Separator))
+}
+
+// deployTargetKind returns the kind of the deploy target: the one set with --target or
+// DEPLOY_PROTOCOL, ftp when not set.
+func deployTargetKind() string {
+	if withTarget != "" {
+		kind, _, _ := parseTarget(withTarget)
+		return kind
+	}
+	if protocol := strings.ToLower(cfg.prodData.DeployProtocol); protocol != "" {
+		return protocol
+	}
+	return ftpfs.FTPProtocol
+}
+
+// deployTargetLabel returns the name of the deploy target shown to the user, e.g. the S3 bucket.
+func deployTargetLabel() string {
+	switch deployTargetKind() {
+	case ftpfs.FTPProtocol:
+		return "the FTP server"
+	case ftpfs.SFTPProtocol:
+		return "the SFTP server"
+	case ftpfs.S3Protocol:
+		return "the S3 bucket"
+	case ftpfs.DirProtocol:
+		return "the local folder"
+	case ftpfs.GitProtocol:
+		return "the git branch"
+	default:
+		return "the remote server"
+	}
+}
+
+// isGitDeploy reports whether the deploy target is a git branch, set with --target or DEPLOY_PROTOCOL.
+func isGitDeploy() bool {
+	return deployTargetKind() == ftpfs.GitProtocol
+}
+
+// isS3Deploy reports whether the deploy target is an S3 bucket, set with DEPLOY_PROTOCOL.
+func isS3Deploy() bool {
+	return deployTargetKind() == ftpfs.S3Protocol
+}
+
+// deployFolder returns the destination folder: the location set with --target
+// or FTP_SERVER_FOLDER from the env file. The git branch root for a git target.
+func deployFolder() string {
+	if isGitDeploy() {
+		return ""
+	}
+	if withTarget != "" {
+		if _, location, err := parseTarget(withTarget); err == nil {
+			return location
+		}
+	}
+	return cfg.prodData.FTPServerFolder
+}
+
+// newGitConnectionConfig returns the git branch settings from the env file,
+// the branch being the one set with --target git:<branch> when used.
+func newGitConnectionConfig(data tpltypes.EnvProductionData) *ftpfs.GitConnectionConfig {
+	branch := data.GitBranch
+	if withTarget != "" {
+		if _, location, err := parseTarget(withTarget); err == nil {
+			branch = location
+		}
+	}
+	return &ftpfs.GitConnectionConfig{
+		RepoPath:      cfg.pathMaker.GetRootFolder(),
+		Remote:        data.GitRemote,
+		Branch:        branch,
+		CommitMessage: data.GitCommitMessage,
+		DryRun:        isDryRun,
+	}
+}
+
+// newFTPConnectionConfig returns the FTP connection settings from the env file,
+// with the credentials resolved as described by resolveCredentials.
+func newFTPConnectionConfig(data tpltypes.EnvProductionData) (*ftpfs.FTPConnectionConfig, error) {
+	credentials, err := resolveCredentials(data)
+	if err != nil {
+		return nil, err
+	}
+	if credentials.User == "" || credentials.Password == "" {
+		return nil, sveltinerr.NewCredentialsNotFoundError(data.FTPHost)
+	}
 	return &ftpfs.FTPConnectionConfig{
-		Host:     data.FTPHost,
-		Port:     data.FTPPort,
-		User:     data.FTPUser,
-		Password: data.FTPPassword,
-		Timeout:  data.FTPDialTimeout,
-		IsEPSV:   data.FTPEPSVMode,
+		Host:          data.FTPHost,
+		Port:          data.FTPPort,
+		User:          credentials.User,
+		Password:      credentials.Password,
+		Timeout:       data.FTPDialTimeout,
+		IsEPSV:        data.FTPEPSVMode,
+		TLSMode:       data.FTPTLSMode,
+		TLSCAFile:     data.FTPTLSCAFile,
+		TLSSkipVerify: data.FTPTLSSkipVerify,
+		Parallel:      withParallel,
+		Retries:       withRetries,
+	}, nil
+}
+
+// newSFTPConnectionConfig returns the SFTP connection settings from the env file. The password,
+// optional when a private key is set, is resolved as described by resolveCredentials.
+func newSFTPConnectionConfig(data tpltypes.EnvProductionData) (*ftpfs.SFTPConnectionConfig, error) {
+	port := data.FTPPort
+	if port == 0 {
+		port = 22
+	}
+	credentials := &secrets.Credentials{User: data.FTPUser}
+	if data.SFTPPrivateKey == "" {
+		var err error
+		if credentials, err = resolveCredentials(data); err != nil {
+			return nil, err
+		}
+		if credentials.Password == "" {
+			return nil, sveltinerr.NewCredentialsNotFoundError(data.FTPHost)
+		}
+	}
+	if credentials.User == "" || credentials.User == placeholderValue {
+		return nil, sveltinerr.NewCredentialsNotFoundError(data.FTPHost)
+	}
+	return &ftpfs.SFTPConnectionConfig{
+		Host:                 data.FTPHost,
+		Port:                 port,
+		User:                 credentials.User,
+		Password:             credentials.Password,
+		PrivateKeyFile:       data.SFTPPrivateKey,
+		PrivateKeyPassphrase: data.SFTPPrivateKeyPassphrase,
+		KnownHostsFile:       data.SFTPKnownHosts,
+		IgnoreHostKey:        data.SFTPIgnoreHostKey,
+		Timeout:              data.FTPDialTimeout,
+		Parallel:             withParallel,
+	}, nil
+}
+
+// loadExcludeList appends the gitignore-style rules from the file set by --withExcludeFile, or from
+// the .sveltinignore file in the project root when the flag is not set, to the values from the --exclude flag.
+func loadExcludeList() {
+	excludeFile := withExcludeFile
+	if len(excludeFile) == 0 {
+		pathToIgnoreFile := filepath.Join(cfg.pathMaker.GetRootFolder(), ftpfs.ExcludeFilename)
+		if exists, _ := afero.Exists(cfg.fs, pathToIgnoreFile); exists {
+			excludeFile = pathToIgnoreFile
+		}
+	}
+	if len(excludeFile) != 0 {
+		lines, err := common.ReadFileLineByLine(cfg.fs, excludeFile)
+		exitIfDeployError(err)
+		// the order matters, the last matching rule wins
+		withExclude = append(withExclude, lines...)
+	}
+}
+
+// connectRemoteServer dials and logs in the remote server set in the env file.
+// It returns the server and the action used to prevent it to close the idle connection.
+func connectRemoteServer() (ftpfs.RemoteServer, *ftpfs.Client) {
+	remoteServer, err := remoteServerFactory(cfg.prodData, withTarget)
+	exitIfDeployError(err)
+	remoteServer.SetRootFolder(deployFolder())
+	remoteServer.SetLogger(cfg.log)
+
+	err = ftpfs.DialAction(remoteServer).Run()
+	exitIfDeployError(err)
+	// e.g. the git worktree is removed when any later step fails
+	if cleaner, ok := remoteServer.(ftpfs.Cleaner); ok {
+		onDeployError(cleaner.Cleanup)
+	}
+
+	err = ftpfs.LoginAction(remoteServer).Run()
+	exitIfDeployError(err)
+
+	// prevent the remote FTP server to close the idle connection
+	noOpAction := ftpfs.IdleAction(remoteServer)
+	err = noOpAction.Run()
+	exitIfDeployError(err)
+
+	return remoteServer, noOpAction
+}
+
+func newS3ConnectionConfig(data tpltypes.EnvProductionData) *ftpfs.S3ConnectionConfig {
+	return &ftpfs.S3ConnectionConfig{
+		Endpoint:     data.S3Endpoint,
+		Region:       data.S3Region,
+		Bucket:       data.S3Bucket,
+		AccessKey:    data.S3AccessKey,
+		SecretKey:    data.S3SecretKey,
+		UseSSL:       data.S3UseSSL,
+		CacheControl: data.S3CacheControl,
+		Parallel:     withParallel,
+	}
+}
+
+// saveManifest writes the manifest on the remote folder, used by the next incremental deploy.
+func saveManifest(server ftpfs.RemoteServer, manifest *ftpfs.Manifest) {
+	manifestContent, err := manifest.Bytes()
+	exitIfDeployError(err)
+	err = ftpfs.WriteFileAction(server, ftpfs.ManifestFilename, manifestContent, isDryRun).Run()
+	exitIfDeployError(err)
+}
+
+// checkpointHeader returns the header identifying the deploy of the local build.
+func checkpointHeader(localManifest *ftpfs.Manifest) ftpfs.CheckpointHeader {
+	checksum, err := localManifest.Checksum()
+	exitIfDeployError(err)
+	return ftpfs.CheckpointHeader{
+		Environment:   withEnv,
+		RemoteFolder:  deployFolder(),
+		BuildChecksum: checksum,
+		StartedAt:     time.Now(),
+	}
+}
+
+// checkpointPath returns the path to the checkpoint file for the environment.
+func checkpointPath() string {
+	return filepath.Join(cfg.pathMaker.GetRootFolder(), StateFolder, "deploy-"+withEnv+".checkpoint")
+}
+
+// newCheckpoint creates the checkpoint for the deploy. No checkpoint is created on dry-run.
+func newCheckpoint(localManifest *ftpfs.Manifest) *ftpfs.Checkpoint {
+	if isDryRun {
+		return nil
+	}
+	checkpoint, err := ftpfs.NewCheckpoint(cfg.fs, checkpointPath(), checkpointHeader(localManifest))
+	exitIfDeployError(err)
+	return checkpoint
+}
+
+// removeCheckpoint deletes the checkpoint file for the environment, if any.
+func removeCheckpoint() error {
+	exists, err := afero.Exists(cfg.fs, checkpointPath())
+	if err != nil || !exists {
+		return err
+	}
+	return cfg.fs.Remove(checkpointPath())
+}
+
+// resumeCheckpoint returns the checkpoint left by a failed deploy of the same build.
+// It returns nil when there is nothing to resume from.
+func resumeCheckpoint(localManifest *ftpfs.Manifest) *ftpfs.Checkpoint {
+	checkpoint, err := ftpfs.LoadCheckpoint(cfg.fs, checkpointPath())
+	if err != nil {
+		cfg.log.Important("No checkpoint found. Running a full deploy")
+		return nil
+	}
+	if !checkpoint.Matches(checkpointHeader(localManifest)) {
+		cfg.log.Important("The checkpoint does not match the current build or remote folder. Running a full deploy")
+		return nil
+	}
+	return checkpoint
+}
+
+func pendingFolders(checkpoint *ftpfs.Checkpoint, folders []string) []string {
+	if checkpoint == nil {
+		return folders
+	}
+	return checkpoint.PendingFolders(folders)
+}
+
+func pendingFiles(checkpoint *ftpfs.Checkpoint, files []string) []string {
+	if checkpoint == nil {
+		return files
+	}
+	pending := checkpoint.PendingFiles(files)
+	if skipped := len(files) - len(pending); skipped > 0 {
+		cfg.log.Infof("%d files already uploaded by the previous run", skipped)
+	}
+	return pending
+}
+
+// makeLocalManifest returns the manifest for the files within the adapter pages and assets folders.
+func makeLocalManifest(fs afero.Fs, kitPagesFolder, kitAssetsFolder string) (*ftpfs.Manifest, error) {
+	manifest := ftpfs.NewManifest()
+	pagesFilesList, err := walkLocal(fs, EntryTypeFile, kitPagesFolder, true)
+	if err != nil {
+		return nil, err
+	}
+	if err := manifest.AddFiles(fs, kitPagesFolder, pagesFilesList, true); err != nil {
+		return nil, err
+	}
+
+	if kitPagesFolder != kitAssetsFolder {
+		assetsFilesList, err := walkLocal(fs, EntryTypeFile, kitAssetsFolder, false)
+		if err != nil {
+			return nil, err
+		}
+		if err := manifest.AddFiles(fs, kitAssetsFolder, assetsFilesList, false); err != nil {
+			return nil, err
+		}
+	}
+	return manifest, nil
+}
+
+// splitLocalFiles groups the local files by the adapter folder they belong to.
+func splitLocalFiles(files []string, kitPagesFolder, kitAssetsFolder string) (pagesFiles, assetsFiles []string) {
+	for _, file := range files {
+		if kitPagesFolder != kitAssetsFolder && strings.HasPrefix(file, kitAssetsFolder+"/") {
+			assetsFiles = append(assetsFiles, file)
+		} else {
+			pagesFiles = append(pagesFiles, file)
+		}
 	}
+	return
 }
 
 func walkLocal(fs afero.Fs, fType EntryType, dirname string, replaceBasePath bool) ([]string, error) {
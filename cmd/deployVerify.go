@@ -0,0 +1,95 @@
+/**
+ * Copyright © 2021-present Sveltin contributors <github@sveltin.io>
+ *
+ * Use of this source code is governed by Apache 2.0 license
+ * that can be found in the LICENSE file.
+ */
+
+package cmd
+
+import (
+	"github.com/spf13/cobra"
+	sveltinerr "github.com/sveltinio/sveltin/internal/errors"
+	"github.com/sveltinio/sveltin/internal/ftpfs"
+	"github.com/sveltinio/sveltin/internal/markup"
+	"github.com/sveltinio/sveltin/tui/feedbacks"
+)
+
+var deployVerifyCmd = &cobra.Command{
+	Use:   "verify",
+	Short: "Compare the remote folder with the local build",
+	Long: `Command used to check the remote folder matches the local build.
+
+The remote files are compared by path and size with the files in the adapter pages and assets folders.
+Use --checksums to download them and compare their SHA-256 checksums too.
+Missing, extra and mismatched files are listed and the command exits with an error on drift.
+The files matching the exclude rules (see --exclude and .sveltinignore) are not reported as extra.
+`,
+	DisableFlagsInUseLine: true,
+	Args:                  cobra.ExactArgs(0),
+	Run:                   RunDeployVerifyCmd,
+}
+
+// RunDeployVerifyCmd is the actual work function.
+func RunDeployVerifyCmd(cmd *cobra.Command, args []string) {
+	// Exit if running sveltin commands either from a not valid directory or not latest sveltin version.
+	isValidProject(true)
+
+	setupNonInteractiveMode()
+
+	cfg.log.Plain(markup.H1("Verify the remote folder against the local build"))
+
+	loadEnvironment(withEnv, true)
+	loadExcludeList()
+
+	kitPagesFolder := cfg.projectSettings.SvelteKit.Adapter.Pages
+	kitAssetsFolder := cfg.projectSettings.SvelteKit.Adapter.Assets
+	localManifest, err := makeLocalManifest(cfg.fs, kitPagesFolder, kitAssetsFolder)
+	exitIfDeployError(err)
+
+	remoteServer, _ := connectRemoteServer()
+	verifyErr := verifyDeploy(remoteServer, localManifest)
+
+	err = ftpfs.LogoutAction(remoteServer).Run()
+	exitIfDeployError(err)
+	exitIfDeployError(verifyErr)
+
+	cfg.log.Success("Done\n")
+	writeDeployReport(nil)
+}
+
+func deployVerifyCmdFlags(cmd *cobra.Command) {
+	cmd.Flags().StringVar(&withEnv, "env", DefaultEnvironment, "name of the environment to verify, loaded from the .env.<name> file")
+	cmd.Flags().StringVarP(&withTarget, "target", "t", "", "deploy target overriding DEPLOY_PROTOCOL, e.g. dir:/srv/www/site")
+	cmd.Flags().BoolVar(&isChecksums, "checksums", false, "download the remote files to compare their checksums too")
+	cmd.Flags().StringArrayVarP(&withExclude, "exclude", "e", []string{".htaccess"}, "gitignore-style pattern of remote files not to be reported as extra (repeatable). Default: .htaccess")
+	cmd.Flags().StringVar(&withExcludeFile, "withExcludeFile", "", "path to the file with the gitignore-style patterns of remote files not to be reported as extra. Default: .sveltinignore")
+	cmd.Flags().StringVar(&withReport, "report", "", "print a machine-readable report with the verification result. Valid values: json")
+}
+
+func init() {
+	deployVerifyCmdFlags(deployVerifyCmd)
+	deployCmd.AddCommand(deployVerifyCmd)
+}
+
+//=============================================================================
+
+// verifyDeploy compares the remote folder with the local build and shows the differences.
+// It returns an error when the remote folder does not match.
+func verifyDeploy(server ftpfs.RemoteServer, localManifest *ftpfs.Manifest) error {
+	cfg.log.Info("Comparing the remote folder with the local build")
+	result, err := ftpfs.Verify(server, localManifest, ftpfs.NewExcludeRules(withExclude), isChecksums)
+	if err != nil {
+		return err
+	}
+
+	if deployReport != nil {
+		deployReport.Verification = result
+	} else {
+		feedbacks.ShowVerifyResult(result)
+	}
+	if result.HasDrift() {
+		return sveltinerr.NewDeployDriftError(len(result.Missing), len(result.Extra), len(result.Mismatched))
+	}
+	return nil
+}
@@ -0,0 +1,106 @@
+/**
+ * Copyright © 2021-present Sveltin contributors <github@sveltin.io>
+ *
+ * Use of this source code is governed by Apache 2.0 license
+ * that can be found in the LICENSE file.
+ */
+
+package cmd
+
+import (
+	"os"
+
+	sveltinerr "github.com/sveltinio/sveltin/internal/errors"
+	"github.com/sveltinio/sveltin/internal/ftpfs"
+	"github.com/sveltinio/sveltin/utils"
+	logger "github.com/sveltinio/yinlog"
+)
+
+// JSONReport is the only format supported by the --report flag.
+const JSONReport string = "json"
+
+var (
+	isYes        bool
+	withReport   string
+	deployReport *ftpfs.Report
+	// deployCleanups release the local resources set up on dial when exiting on error.
+	deployCleanups []func() error
+)
+
+// setupNonInteractiveMode switches the progress bars to plain lines when running
+// with --yes or --report. --report implies --yes, the log and the progress lines are
+// sent to stderr so that stdout only gets the final report.
+func setupNonInteractiveMode() {
+	if withReport != "" && withReport != JSONReport {
+		utils.ExitIfError(sveltinerr.NewOptionNotValidError(withReport, []string{JSONReport}))
+	}
+	if withReport != "" {
+		isYes = true
+	}
+	if !isYes && withReport == "" {
+		return
+	}
+
+	if withReport == "" {
+		ftpfs.SetPlainProgress(os.Stdout)
+		return
+	}
+
+	useStderrLogger()
+	ftpfs.SetPlainProgress(os.Stderr)
+
+	deployReport = ftpfs.NewReport()
+	deployReport.Environment = withEnv
+	deployReport.Target = withTarget
+	deployReport.DryRun = isDryRun
+	ftpfs.SetReport(deployReport)
+}
+
+// useStderrLogger sends the logs to stderr with plain labels, so that stdout only gets the command output.
+func useStderrLogger() {
+	cfg.log.SetPrinter(&logger.TextPrinter{
+		Writer: os.Stderr,
+		Options: &logger.PrinterOptions{
+			Timestamp: false,
+			Colors:    false,
+			Labels:    true,
+			Icons:     false,
+		},
+	})
+}
+
+// writeDeployReport completes the deploy report, if requested, and writes it to stdout.
+func writeDeployReport(err error) {
+	if deployReport == nil {
+		return
+	}
+	deployReport.Finish(err)
+	if wErr := deployReport.WriteJSON(os.Stdout); wErr != nil {
+		utils.ExitIfError(wErr)
+	}
+}
+
+// exitIfDeployError writes the deploy report, if requested, and runs the cleanups before exiting on error.
+func exitIfDeployError(err error) {
+	if err != nil {
+		writeDeployReport(err)
+		runDeployCleanups()
+	}
+	utils.ExitIfError(err)
+}
+
+// onDeployError registers a cleanup run by exitIfDeployError before exiting.
+func onDeployError(cleanup func() error) {
+	deployCleanups = append(deployCleanups, cleanup)
+}
+
+// runDeployCleanups runs the registered cleanups, last registered first. Their errors
+// are only logged, the command is already failing.
+func runDeployCleanups() {
+	for i := len(deployCleanups) - 1; i >= 0; i-- {
+		if err := deployCleanups[i](); err != nil {
+			cfg.log.Errorf("Cleanup failed: %s", err)
+		}
+	}
+	deployCleanups = nil
+}
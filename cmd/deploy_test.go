@@ -0,0 +1,314 @@
+package cmd
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/matryer/is"
+	"github.com/spf13/afero"
+	"github.com/sveltinio/sveltin/internal/ftpfs"
+	"github.com/sveltinio/sveltin/internal/tpltypes"
+)
+
+const testSveltinJSON = `{
+	"name": "my-site",
+	"baseurl": "https://example.com",
+	"theme": {"style": "blank", "name": "sveltin_theme", "cssLib": "vanillacss"},
+	"sitemap": {"changeFreq": "weekly", "priority": 0.5},
+	"sveltekit": {"adapter": {"pages": "build", "assets": "%ASSETS%"}},
+	"sveltin": {"version": "0.10.0"},
+	"backups": {"keep": 10, "maxAge": ""},
+	"environments": ["production"]
+}`
+
+const testEnvFile = `VITE_PUBLIC_BASE_PATH=/
+DEPLOY_PROTOCOL = "ftp"
+FTP_HOST = "ftp.example.com"
+FTP_PORT = 21
+FTP_SERVER_FOLDER = "/www"
+`
+
+// setupTestProject creates the project files in a temporary folder, makes it the current one
+// and loads the project settings.
+func setupTestProject(t *testing.T, files map[string]string) {
+	t.Helper()
+	is := is.New(t)
+
+	yamlConfig, err := os.ReadFile(filepath.Join("..", "resources", "sveltin.yaml"))
+	is.NoErr(err)
+	YamlConfig = yamlConfig
+
+	cwd, err := os.Getwd()
+	is.NoErr(err)
+	dir := t.TempDir()
+	is.NoErr(os.Chdir(dir))
+	t.Cleanup(func() {
+		os.Chdir(cwd)
+	})
+
+	appFs := afero.NewOsFs()
+	for name, content := range files {
+		is.NoErr(appFs.MkdirAll(filepath.Dir(name), 0755))
+		is.NoErr(afero.WriteFile(appFs, name, []byte(content), 0644))
+	}
+
+	loadSveltinSettings()
+	initAppConfig()
+}
+
+// setupDeployProject creates a project with a build in a temporary folder, makes it the current
+// one and returns the in-memory server the deploy command connects to, with the remote folder set.
+func setupDeployProject(t *testing.T, assetsFolder string, remoteFiles map[string]string) *ftpfs.MemServerConnection {
+	t.Helper()
+	is := is.New(t)
+
+	files := map[string]string{
+		"package.json":                 `{"name": "my-site"}`,
+		ProjectSettingsFile:            strings.Replace(testSveltinJSON, "%ASSETS%", assetsFolder, 1),
+		DotEnvProdFile:                 testEnvFile,
+		"build/index.html":             "<h1>home</h1>",
+		"build/posts/first/index.html": "<h1>first</h1>",
+	}
+	if assetsFolder != "build" {
+		files[filepath.Join(assetsFolder, "app.css")] = "body {}"
+	}
+	setupTestProject(t, files)
+	t.Cleanup(func() {
+		remoteServerFactory = newRemoteServer
+		ftpfs.SetPlainProgress(nil)
+	})
+
+	isDryRun, isBackup, isIncremental, isAtomic, isResume, isVerify = false, true, false, false, false, false
+	withExclude, withExcludeFile, withTarget = []string{".htaccess"}, "", ""
+	withEnv, isYes, withReport, deployReport, isPreview = DefaultEnvironment, true, "", nil, true
+
+	server := ftpfs.NewMemServerConnection()
+	for name, content := range remoteFiles {
+		is.NoErr(afero.WriteFile(server.Fs, filepath.Join("/www", name), []byte(content), 0644))
+	}
+	remoteServerFactory = func(data tpltypes.EnvProductionData, target string) (ftpfs.RemoteServer, error) {
+		return server, nil
+	}
+	return server
+}
+
+// remoteFiles returns the paths of the files within the remote folder of the in-memory server.
+func remoteFiles(t *testing.T, server *ftpfs.MemServerConnection) []string {
+	t.Helper()
+	files, err := server.LocalDirConnection.ListFiles()
+	if err != nil {
+		t.Fatal(err)
+	}
+	paths := []string{}
+	for _, file := range files {
+		paths = append(paths, file.Path)
+	}
+	return paths
+}
+
+func backupArchives(t *testing.T) []string {
+	t.Helper()
+	archives, err := filepath.Glob(filepath.Join(BackupsFolder, "my-site_*.tar.gz"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	return archives
+}
+
+func TestDeployCmdRun(t *testing.T) {
+	is := is.New(t)
+	server := setupDeployProject(t, "build", map[string]string{
+		".htaccess": "Options -Indexes",
+		"old.html":  "<h1>old</h1>",
+	})
+
+	DeployCmdRun(deployCmd, []string{})
+
+	is.Equal(remoteFiles(t, server), []string{".htaccess", ftpfs.ManifestFilename, "index.html", "posts/first/index.html"})
+	content, err := afero.ReadFile(server.Fs, "/www/posts/first/index.html")
+	is.NoErr(err)
+	is.Equal(string(content), "<h1>first</h1>")
+
+	// the previous content is saved before being deleted
+	is.Equal(len(server.Recorded("doBackup")), 1)
+	is.Equal(len(backupArchives(t)), 1)
+
+	names := []string{}
+	for _, op := range server.Operations {
+		if op.Name != "idle" {
+			names = append(names, op.Name)
+		}
+	}
+	is.Equal(names, []string{"setRootFolder", "dial", "login", "listFiles", "doBackup", "deleteAll", "makeDirs", "uploadFiles", "writeFile", "logout"})
+}
+
+func TestDeployCmdRunDryRun(t *testing.T) {
+	is := is.New(t)
+	server := setupDeployProject(t, "build", map[string]string{"old.html": "<h1>old</h1>"})
+	isDryRun = true
+
+	DeployCmdRun(deployCmd, []string{})
+
+	is.Equal(remoteFiles(t, server), []string{"old.html"})
+	is.Equal(len(backupArchives(t)), 0)
+	for _, name := range []string{"doBackup", "deleteAll", "makeDirs", "uploadFiles", "writeFile"} {
+		for _, op := range server.Recorded(name) {
+			is.True(op.DryRun) // every change is a dry-run
+		}
+	}
+}
+
+func TestDeployCmdRunReport(t *testing.T) {
+	is := is.New(t)
+	server := setupDeployProject(t, "build", map[string]string{"old.html": "<h1>old</h1>"})
+	t.Cleanup(func() { ftpfs.SetReport(nil) })
+	// --report without --yes does not prompt, stdout only gets the report
+	isYes, withReport = false, JSONReport
+
+	out := captureStdout(t, func() {
+		DeployCmdRun(deployCmd, []string{})
+	})
+
+	is.True(isYes)
+	report := map[string]interface{}{}
+	is.NoErr(json.Unmarshal([]byte(out), &report))
+	is.Equal(report["environment"], DefaultEnvironment)
+	is.Equal(report["success"], true)
+	is.Equal(remoteFiles(t, server), []string{ftpfs.ManifestFilename, "index.html", "posts/first/index.html"})
+}
+
+func TestDeployCmdRunExclude(t *testing.T) {
+	is := is.New(t)
+	server := setupDeployProject(t, "build", map[string]string{
+		".htaccess":           "Options -Indexes",
+		"uploads/photo.jpg":   "jpg",
+		"contact.php":         "<?php",
+		"legacy/old.php":      "<?php",
+		"legacy/old.html":     "<h1>old</h1>",
+		"public/keep-me.html": "<h1>keep</h1>",
+	})
+	is.NoErr(afero.WriteFile(cfg.fs, ftpfs.ExcludeFilename, []byte("uploads/\n*.php\n!/legacy/*.php\n"), 0644))
+	withExclude = append(withExclude, "public/")
+	isBackup = false
+
+	DeployCmdRun(deployCmd, []string{})
+
+	deleteAll := server.Recorded("deleteAll")
+	is.Equal(len(deleteAll), 1)
+	// the --exclude rules come first, the last matching rule wins
+	is.Equal(deleteAll[0].Args, []string{".htaccess", "public/", "uploads/", "*.php", "!/legacy/*.php"})
+	is.Equal(remoteFiles(t, server), []string{
+		".htaccess", ftpfs.ManifestFilename, "contact.php", "index.html", "posts/first/index.html", "public/keep-me.html", "uploads/photo.jpg",
+	})
+	is.Equal(len(server.Recorded("doBackup")), 0)
+}
+
+func TestDeployCmdRunAssetsFolder(t *testing.T) {
+	is := is.New(t)
+	server := setupDeployProject(t, "static-out", nil)
+	isBackup = false
+
+	DeployCmdRun(deployCmd, []string{})
+
+	// the pages are uploaded to the remote folder root, the assets folder as a whole
+	uploads := server.Recorded("uploadFiles")
+	is.Equal(len(uploads), 2)
+	is.Equal(uploads[0].Args, []string{"build/index.html", "build/posts/first/index.html"})
+	is.Equal(uploads[1].Args, []string{"static-out/app.css"})
+	is.Equal(remoteFiles(t, server), []string{ftpfs.ManifestFilename, "index.html", "posts/first/index.html", "static-out/app.css"})
+}
+
+func TestCheckDeployFlagsAtomicRoot(t *testing.T) {
+	is := is.New(t)
+	setupDeployProject(t, "build", nil)
+	loadEnvironment(withEnv, true)
+	isAtomic = true
+
+	is.NoErr(checkDeployFlags()) // the remote folder is /www
+
+	for _, folder := range []string{"/", ""} {
+		cfg.prodData.FTPServerFolder = folder
+		is.True(checkDeployFlags() != nil) // the staging folder would be within the live one
+	}
+
+	cfg.prodData.DeployProtocol = ftpfs.S3Protocol
+	is.NoErr(checkDeployFlags()) // the bucket root
+
+	withTarget = "dir:/"
+	is.True(checkDeployFlags() != nil)
+	withTarget = "dir:/srv/www"
+	is.NoErr(checkDeployFlags())
+}
+
+func TestDeployTargetLabel(t *testing.T) {
+	is := is.New(t)
+	setupDeployProject(t, "build", nil)
+
+	tests := []struct {
+		protocol string
+		target   string
+		label    string
+	}{
+		{"", "", "the FTP server"},
+		{"ftp", "", "the FTP server"},
+		{"SFTP", "", "the SFTP server"},
+		{"s3", "", "the S3 bucket"},
+		{"git", "", "the git branch"},
+		{"s3", "dir:/srv/www", "the local folder"},
+		{"ftp", "git:gh-pages", "the git branch"},
+	}
+	for _, tt := range tests {
+		cfg.prodData.DeployProtocol, withTarget = tt.protocol, tt.target
+		is.Equal(deployTargetLabel(), tt.label)
+	}
+}
+
+func TestCheckDirTarget(t *testing.T) {
+	is := is.New(t)
+	setupDeployProject(t, "static-out", nil)
+	root, err := os.Getwd()
+	is.NoErr(err)
+
+	for _, location := range []string{".", root, filepath.Dir(root), "/", "build", "static-out", BackupsFolder} {
+		is.True(checkDirTarget(location) != nil) // the deploy would delete the project files
+	}
+	for _, location := range []string{"out", filepath.Join(t.TempDir(), "www")} {
+		is.NoErr(checkDirTarget(location))
+	}
+}
+
+func TestRollbackCmdRun(t *testing.T) {
+	is := is.New(t)
+	server := setupDeployProject(t, "build", map[string]string{"old.html": "<h1>old</h1>"})
+	DeployCmdRun(deployCmd, []string{})
+	is.Equal(len(backupArchives(t)), 1)
+
+	// restoring the latest backup with --yes does not prompt
+	isLatestBackup = true
+	t.Cleanup(func() { isLatestBackup = false })
+	RollbackCmdRun(rollbackCmd, []string{})
+
+	is.Equal(remoteFiles(t, server), []string{"old.html"})
+}
+
+func TestRecordEnvironment(t *testing.T) {
+	is := is.New(t)
+	fs := afero.NewMemMapFs()
+	settings := "{\n  \"name\": \"my-site\",\n  \"environments\": [\"production\"],\n  \"hooks\": {}\n}\n"
+	is.NoErr(afero.WriteFile(fs, ProjectSettingsFile, []byte(settings), 0644))
+
+	// only the environments list is changed, the user formatting is kept
+	is.NoErr(recordEnvironment(fs, ProjectSettingsFile, "staging"))
+	content, err := afero.ReadFile(fs, ProjectSettingsFile)
+	is.NoErr(err)
+	is.Equal(string(content), "{\n  \"name\": \"my-site\",\n  \"environments\": [\"production\",\"staging\"],\n  \"hooks\": {}\n}\n")
+
+	// a recorded environment leaves the file untouched
+	is.NoErr(recordEnvironment(fs, ProjectSettingsFile, "production"))
+	unchanged, err := afero.ReadFile(fs, ProjectSettingsFile)
+	is.NoErr(err)
+	is.Equal(unchanged, content)
+}
@@ -0,0 +1,159 @@
+/**
+ * Copyright © 2021-present Sveltin contributors <github@sveltin.io>
+ *
+ * Use of this source code is governed by Apache 2.0 license
+ * that can be found in the LICENSE file.
+ */
+
+package cmd
+
+import (
+	"encoding/json"
+	"net/url"
+	"path/filepath"
+	"regexp"
+	"strings"
+
+	"github.com/spf13/afero"
+	"github.com/sveltinio/sveltin/common"
+	sveltinerr "github.com/sveltinio/sveltin/internal/errors"
+	"github.com/sveltinio/sveltin/internal/ftpfs"
+	"github.com/sveltinio/sveltin/internal/tpltypes"
+	"github.com/sveltinio/sveltin/utils"
+	"github.com/tidwall/sjson"
+)
+
+// DefaultEnvironment is the environment used when --env is not set. It maps to the .env.production file.
+const DefaultEnvironment string = "production"
+
+// placeholderValue is the value set by the env file template for the props to be edited.
+const placeholderValue string = "<CHANGE_ME>"
+
+var withEnv string
+
+var envNameRegExp = regexp.MustCompile(`^[a-z0-9][a-z0-9_-]*$`)
+
+// envFileName returns the env file name for the environment, e.g. .env.staging
+func envFileName(name string) string {
+	return ".env." + name
+}
+
+// loadEnvironment loads the .env.<name> file as cfg.prodData and validates it.
+// The deploy settings are validated only when checkDeploySettings is true.
+func loadEnvironment(name string, checkDeploySettings bool) {
+	if !envNameRegExp.MatchString(name) {
+		utils.ExitIfError(sveltinerr.NewOptionNotValidError(name, []string{"lowercase letters, digits, '-' and '_'"}))
+	}
+
+	pathToEnvFile := filepath.Join(cfg.pathMaker.GetRootFolder(), envFileName(name))
+	exists, err := common.FileExists(cfg.fs, pathToEnvFile)
+	if err != nil || !exists {
+		utils.ExitIfError(sveltinerr.NewFileNotFoundError(pathToEnvFile))
+	}
+
+	cfg.prodData, err = loadEnvFile(envFileName(name))
+	utils.ExitIfError(err)
+
+	if missing := validateEnvironment(cfg.prodData, checkDeploySettings); len(missing) > 0 {
+		utils.ExitIfError(sveltinerr.NewNotValidEnvironmentError(name, envFileName(name), missing))
+	}
+}
+
+// recordDeployEnvironment records the environment deployed to in the project settings file.
+func recordDeployEnvironment(name string) error {
+	pathToSettingsFile := filepath.Join(cfg.pathMaker.GetRootFolder(), ProjectSettingsFile)
+	if err := recordEnvironment(cfg.fs, pathToSettingsFile, name); err != nil {
+		return err
+	}
+	if !common.Contains(cfg.projectSettings.Environments, name) {
+		cfg.projectSettings.Environments = append(cfg.projectSettings.Environments, name)
+	}
+	return nil
+}
+
+// validateEnvironment returns the list of props not set or not valid in the env file.
+// Connection props are checked for the DEPLOY_PROTOCOL in use unless a --target is set.
+// The FTP credentials are checked when connecting, once resolved from all the sources.
+func validateEnvironment(data tpltypes.EnvProductionData, checkDeploySettings bool) []string {
+	missing := []string{}
+	if !isValidBasePath(data.BaseURL) {
+		missing = append(missing, "VITE_PUBLIC_BASE_PATH (an URL or a path starting with '/')")
+	}
+	if !checkDeploySettings || withTarget != "" {
+		return missing
+	}
+
+	isNotSet := func(value string) bool {
+		return strings.TrimSpace(value) == "" || value == placeholderValue
+	}
+
+	switch strings.ToLower(data.DeployProtocol) {
+	case "", ftpfs.FTPProtocol, ftpfs.SFTPProtocol:
+		if isNotSet(data.FTPHost) {
+			missing = append(missing, "FTP_HOST")
+		}
+		if isNotSet(data.FTPServerFolder) {
+			missing = append(missing, "FTP_SERVER_FOLDER")
+		}
+		// FTP_USER and FTP_PASSWORD can be set elsewhere, see resolveCredentials
+		if strings.ToLower(data.DeployProtocol) != ftpfs.SFTPProtocol && data.FTPPort <= 0 {
+			missing = append(missing, "FTP_PORT")
+		}
+	case ftpfs.S3Protocol:
+		if isNotSet(data.S3Endpoint) {
+			missing = append(missing, "S3_ENDPOINT")
+		}
+		if isNotSet(data.S3Bucket) {
+			missing = append(missing, "S3_BUCKET")
+		}
+		if isNotSet(data.S3AccessKey) {
+			missing = append(missing, "S3_ACCESS_KEY")
+		}
+		if isNotSet(data.S3SecretKey) {
+			missing = append(missing, "S3_SECRET_KEY")
+		}
+	}
+	return missing
+}
+
+// environmentBackupsFolder returns the local folder for the backups of the environment.
+// The default environment keeps using the backups folder itself.
+func environmentBackupsFolder(name string) string {
+	backupsFolderPath := filepath.Join(cfg.pathMaker.GetRootFolder(), BackupsFolder)
+	if name == DefaultEnvironment {
+		return backupsFolderPath
+	}
+	return filepath.Join(backupsFolderPath, name)
+}
+
+// isValidBasePath returns true for an absolute URL or a path starting with '/'.
+func isValidBasePath(value string) bool {
+	if strings.HasPrefix(value, "/") {
+		return true
+	}
+	u, err := url.Parse(value)
+	return err == nil && u.Scheme != "" && u.Host != ""
+}
+
+// recordEnvironment adds the environment name to the environments list in the project settings file.
+// Only the list is changed, the rest of the file is kept as it is.
+func recordEnvironment(fs afero.Fs, pathToSettingsFile, name string) error {
+	content, err := afero.ReadFile(fs, pathToSettingsFile)
+	if err != nil {
+		return err
+	}
+
+	settings := tpltypes.ProjectSettings{}
+	if err := json.Unmarshal(content, &settings); err != nil {
+		return err
+	}
+	if common.Contains(settings.Environments, name) {
+		return nil
+	}
+
+	newContent, err := sjson.SetBytes(content, "environments.-1", name)
+	if err != nil {
+		return err
+	}
+	return afero.WriteFile(fs, pathToSettingsFile, newContent, 0666)
+}
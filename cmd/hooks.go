@@ -0,0 +1,72 @@
+/**
+ * Copyright © 2021-present Sveltin contributors <github@sveltin.io>
+ *
+ * Use of this source code is governed by Apache 2.0 license
+ * that can be found in the LICENSE file.
+ */
+
+package cmd
+
+import (
+	"io"
+	"path/filepath"
+	"sort"
+
+	"github.com/spf13/afero"
+	"github.com/subosito/gotenv"
+	"github.com/sveltinio/sveltin/internal/shell"
+)
+
+// runHook runs the commands set for the lifecycle hook in sveltin.json, in order and from the project root.
+// The commands get the variables from the .env.<name> file of the current environment, plus SVELTIN_ENV.
+// The output of the commands is written to out. It stops at the first command exiting with a non-zero code.
+func runHook(hook string, commands []string, out io.Writer) error {
+	if len(commands) == 0 {
+		return nil
+	}
+
+	env, err := hookEnv()
+	if err != nil {
+		return err
+	}
+	hookShell := shell.NewHookShell(cfg.pathMaker.GetRootFolder(), env)
+	hookShell.Stdout = out
+
+	for _, command := range commands {
+		cfg.log.Infof("Running the %s hook: %s", hook, command)
+		if err := hookShell.Run(hook, command); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// hookEnv returns the variables from the .env.<name> file, if any, as KEY=value.
+func hookEnv() ([]string, error) {
+	env := []string{"SVELTIN_ENV=" + withEnv}
+
+	pathToEnvFile := filepath.Join(cfg.pathMaker.GetRootFolder(), envFileName(withEnv))
+	exists, err := afero.Exists(cfg.fs, pathToEnvFile)
+	if err != nil || !exists {
+		return env, err
+	}
+	file, err := cfg.fs.Open(pathToEnvFile)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+	vars, err := gotenv.StrictParse(file)
+	if err != nil {
+		return nil, err
+	}
+
+	keys := make([]string, 0, len(vars))
+	for key := range vars {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	for _, key := range keys {
+		env = append(env, key+"="+vars[key])
+	}
+	return env, nil
+}
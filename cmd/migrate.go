@@ -8,20 +8,35 @@ package cmd
  */
 
 import (
+	"bytes"
+	"encoding/json"
 	"fmt"
 	"os"
 	"path"
+	"path/filepath"
 	"sort"
+	"time"
 
+	"github.com/spf13/afero"
 	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
 	"github.com/sveltinio/prompti/confirm"
+	sveltinerr "github.com/sveltinio/sveltin/internal/errors"
 	"github.com/sveltinio/sveltin/internal/markup"
 	"github.com/sveltinio/sveltin/internal/migrations"
+	"github.com/sveltinio/sveltin/internal/shell"
+	"github.com/sveltinio/sveltin/internal/tpltypes"
 	"github.com/sveltinio/sveltin/resources"
 	"github.com/sveltinio/sveltin/tui/feedbacks"
 	"github.com/sveltinio/sveltin/utils"
 )
 
+// Files and folders within the state folder used by the migrate command.
+const (
+	MigrationsJournalFile    string = "migrations.json"
+	MigrationSnapshotsFolder string = "snapshots"
+)
+
 //=============================================================================
 
 var migrateCmd = &cobra.Command{
@@ -29,6 +44,18 @@ var migrateCmd = &cobra.Command{
 	Short: "Migrate your project to the latest Sveltin version",
 	Long: resources.GetASCIIArt() + `
 Command used to migrate your project files to the latest Sveltin version.
+
+The migrations to run are planned from the sveltin version in sveltin.json to the CLI
+version and run step by step in version order. A project already on the CLI version
+runs nothing.
+
+Before running the migrations, the target files are saved to a snapshot folder within
+.sveltin/snapshots and the run is recorded in .sveltin/migrations.json. A migration
+failing restores its own target files and sveltin.json, so that the project stays on its
+version, 'sveltin migrate rollback' undoes the whole run.
+
+Use --dry-run to print the unified diff of each file the migrations would change,
+together with the migrations that would run, without writing anything to disk.
 `,
 	DisableFlagsInUseLine: true,
 	Args:                  cobra.ExactArgs(0),
@@ -40,79 +67,162 @@ func RunMigrateCmd(cmd *cobra.Command, args []string) {
 	// Exit if running sveltin commands from a not valid directory.
 	isValidProject(false)
 
+	// the migrations to run depend on the sveltin version the project is on.
+	projectCliVersion := cfg.projectSettings.Sveltin.Version
+	migrationPlan, err := migrations.NewMigrationPlan(projectCliVersion, CliVersion)
+	utils.ExitIfError(err)
+	isOlder, err := migrations.IsOlderVersion(projectCliVersion, CliVersion)
+	utils.ExitIfError(err)
+	if len(migrationPlan) == 0 && !isOlder {
+		cfg.log.Success(fmt.Sprintf("Nothing to migrate, your project is already on sveltin v%s\n", projectCliVersion))
+		return
+	}
+
 	feedbacks.ShowUpgradeCommandMessage()
 
-	isConfirm, err := confirm.Run(&confirm.Config{Question: "Continue?"})
-	utils.ExitIfError(err)
+	isConfirm := true
+	if isDryRun {
+		feedbacks.ShowDryRunMessage()
+	} else {
+		isConfirm, err = confirm.Run(&confirm.Config{Question: "Continue?"})
+		utils.ExitIfError(err)
+	}
 
 	if isConfirm {
 		cwd, _ := os.Getwd()
 		cfg.log.Plain(markup.H1(fmt.Sprintf("Migrating your project to sveltin v%s", CliVersion)))
 
+		// on dry-run the migrations write to memory, the changes of each one are tracked.
+		migrationFs := cfg.fs
+		var dryRunFs *migrations.DryRunFs
+		dryRunChanges := map[string]migrations.FileChange{}
+		changedFiles := map[string][]string{}
+		migrationNames := []string{migrations.ProjectSettings.String()}
+		if isDryRun {
+			dryRunFs = migrations.NewDryRunFs(cfg.fs)
+			migrationFs = dryRunFs
+		}
+
 		migrationManager := migrations.NewMigrationManager()
-		migrationServices := migrations.NewMigrationServices(cfg.fs, cfg.fsManager, cfg.pathMaker, cfg.log)
+		migrationServices := migrations.NewMigrationServices(migrationFs, cfg.fsManager, cfg.pathMaker, cfg.log)
 
+		// the target files are saved to a snapshot before running the migrations, the run is
+		// recorded in the journal even when a migration fails so that it can be rolled back.
+		startedAt := time.Now()
+		journalRun := &migrations.JournalRun{
+			ID:                startedAt.Format("20060102-150405"),
+			StartedAt:         startedAt,
+			CliVersion:        CliVersion,
+			ProjectCliVersion: projectCliVersion,
+		}
+		journalRun.Snapshot = filepath.Join(StateFolder, MigrationSnapshotsFolder, journalRun.ID)
+		snapshot := migrations.NewSnapshot(cfg.fs, cwd, filepath.Join(cwd, journalRun.Snapshot))
+		if !isDryRun {
+			migrationServices.SetSnapshot(snapshot)
+		}
+		journal, err := migrations.LoadJournal(cfg.fs, migrationsJournalPath())
+		utils.ExitIfError(err)
+		runner := &migrationRunner{
+			fs:       migrationFs,
+			cwd:      cwd,
+			manager:  migrationManager,
+			services: migrationServices,
+			journal:  journal,
+			run:      journalRun,
+		}
 		/** FILE: <project_root>/sveltin.json */
 		pathToFile := path.Join(cwd, ProjectSettingsFile)
+		exitIfMigrateError := func(err error) {
+			if err != nil && !isDryRun {
+				utils.ExitIfError(abortMigrateRun(snapshot, journalRun, pathToFile))
+			}
+			utils.ExitIfError(err)
+		}
+		if !isDryRun {
+			utils.ExitIfError(snapshot.Add(pathToFile))
+		}
 		migrationData := &migrations.MigrationData{
 			TargetPath:        pathToFile,
 			CliVersion:        CliVersion,
-			ProjectCliVersion: cfg.projectSettings.Sveltin.Version,
+			ProjectCliVersion: projectCliVersion,
 		}
-		migrationFactory, err := migrations.GetMigrationFactory(migrations.ProjectSettings)
-		utils.ExitIfError(err)
-		migration := migrationFactory.MakeMigration(migrationManager, migrationServices, migrationData)
 		// execute the migration.
-		err = migration.Migrate()
-		utils.ExitIfError(err)
+		err = runner.migrate(migrations.ProjectSettings, migrationData)
+		exitIfMigrateError(err)
+		if isDryRun {
+			changedFiles[migrations.ProjectSettings.String()], err = changedSince(dryRunFs, dryRunChanges)
+			utils.ExitIfError(err)
+		}
 
 		// Load project settings file after sveltin.json file creation
-		cfg.projectSettings, err = loadProjectSettings(ProjectSettingsFile)
-		utils.ExitIfError(err)
+		if isDryRun {
+			cfg.projectSettings, err = readProjectSettings(dryRunFs, pathToFile)
+		} else {
+			cfg.projectSettings, err = loadProjectSettings(ProjectSettingsFile)
+		}
+		exitIfMigrateError(err)
+
+		migrationIdPathToTargetMap := migrationTargets(cwd)
 
-		migrationIdPathToTargetMap := map[migrations.Migration]string{
-			migrations.DefaultsConfig:     path.Join(cwd, cfg.pathMaker.GetConfigFolder(), DefaultsConfigFile),
-			migrations.WebSiteTS:          path.Join(cwd, cfg.pathMaker.GetConfigFolder(), WebSiteTSFile),
-			migrations.MenuTS:             path.Join(cwd, cfg.pathMaker.GetConfigFolder(), MenuTSFile),
-			migrations.SveltinDTS:         path.Join(cwd, cfg.pathMaker.GetSrcFolder(), SveltinDTSFile),
-			migrations.ResourceLibs:       path.Join(cwd, cfg.pathMaker.GetLibFolder()),
-			migrations.Layout:             path.Join(cwd, cfg.pathMaker.GetRoutesFolder(), LayoutTSFile),
-			migrations.SvelteFiles:        path.Join(cwd, cfg.pathMaker.GetRoutesFolder()),
-			migrations.PageServerTS:       path.Join(cwd, cfg.pathMaker.GetRoutesFolder()),
-			migrations.SveltinioComponent: path.Join(cwd, cfg.pathMaker.GetRoutesFolder()),
-			migrations.ThemeConfig: path.Join(cwd, cfg.pathMaker.GetThemesFolder(),
-				cfg.projectSettings.Theme.Name, cfg.settings.GetThemeConfigFilename()),
-			migrations.ThemeSveltinioComponents: path.Join(cwd, cfg.pathMaker.GetThemesFolder()),
-			migrations.MDsveXConfig:             path.Join(cwd, MDsveXFile),
-			migrations.SvelteConfig:             path.Join(cwd, SvelteConfigFile),
-			migrations.DotEnv:                   path.Join(cwd, DotEnvProdFile),
-			migrations.ViteConfig:               path.Join(cwd, ViteConfigFile),
-			migrations.TSConfig:                 path.Join(cwd, TSConfigFile),
-			migrations.PackageJSON:              path.Join(cwd, PackageJSONFile),
-		}
-
-		// Ensure the migrations execution order
-		migrationKeys := sortedMigrationMap(migrationIdPathToTargetMap)
-
-		for _, k := range migrationKeys {
-			_id := migrations.Migration(k)
-			_pathToFile := migrationIdPathToTargetMap[_id]
-			migrationData := &migrations.MigrationData{
-				TargetPath: _pathToFile,
+		if !isDryRun {
+			for _, step := range migrationPlan {
+				for _, id := range step.Migrations {
+					exitIfMigrateError(snapshot.Add(migrationIdPathToTargetMap[id]))
+				}
 			}
-			migrationFactory, err := migrations.GetMigrationFactory(_id)
-			utils.ExitIfError(err)
-			migration := migrationFactory.MakeMigration(migrationManager, migrationServices, migrationData)
-			// execute the migration.
-			err = migration.Migrate()
-			utils.ExitIfError(err)
 		}
 
+		// the steps run in version order, each one upgrading the project from the previous version.
+		for _, step := range migrationPlan {
+			cfg.log.Plain(markup.H2(fmt.Sprintf("Upgrading to sveltin v%s", step.Version)))
+			for _, id := range step.Migrations {
+				migrationData := &migrations.MigrationData{
+					TargetPath:        migrationIdPathToTargetMap[id],
+					CliVersion:        step.Version,
+					ProjectCliVersion: projectCliVersion,
+				}
+				// execute the migration.
+				err = runner.migrate(id, migrationData)
+				exitIfMigrateError(err)
+				if isDryRun {
+					migrationNames = append(migrationNames, id.String())
+					changedFiles[id.String()], err = changedSince(dryRunFs, dryRunChanges)
+					utils.ExitIfError(err)
+				}
+			}
+		}
+
+		if isDryRun {
+			showMigrationsDryRun(dryRunFs, cwd, migrationNames, changedFiles)
+			return
+		}
+
+		err = recordMigrateRun(snapshot, journalRun)
+		utils.ExitIfError(err)
+		if len(journalRun.Files) > 0 {
+			cfg.log.Info(fmt.Sprintf("%d files saved to %s, run 'sveltin migrate rollback' to undo the changes", len(journalRun.Files), journalRun.Snapshot))
+		}
+
+		err = runHook(shell.PostMigrateHook, cfg.projectSettings.Hooks.PostMigrate, os.Stdout)
+		utils.ExitIfError(err)
+
 		cfg.log.Success(markup.Green(fmt.Sprintf("Your project is ready for sveltin v%s\n", CliVersion)))
 	}
 }
 
+func migrateCmdFlags(cmd *cobra.Command) {
+	cmd.Flags().BoolVarP(&isDryRun, "dryRun", "d", false, "print the changes without writing them to disk")
+	// --dry-run is an alias for --dryRun
+	cmd.Flags().SetNormalizeFunc(func(f *pflag.FlagSet, name string) pflag.NormalizedName {
+		if name == "dry-run" {
+			name = "dryRun"
+		}
+		return pflag.NormalizedName(name)
+	})
+}
+
 func init() {
+	migrateCmdFlags(migrateCmd)
 	rootCmd.AddCommand(migrateCmd)
 }
 
@@ -124,3 +234,193 @@ func sortedMigrationMap(m map[migrations.Migration]string) []int {
 	sort.Ints(keys)
 	return keys
 }
+
+// migrationRunner runs the migrations, recording each one to the journal run.
+type migrationRunner struct {
+	fs       afero.Fs
+	cwd      string
+	manager  *migrations.MigrationManager
+	services *migrations.MigrationServices
+	journal  *migrations.Journal
+	run      *migrations.JournalRun
+}
+
+// migrate runs the migration and records its outcome with the checksums of the target before and after it.
+func (r *migrationRunner) migrate(id migrations.Migration, data *migrations.MigrationData) error {
+	checksum, err := migrations.TargetChecksum(r.fs, data.TargetPath)
+	if err != nil {
+		return err
+	}
+	target, err := filepath.Rel(r.cwd, data.TargetPath)
+	if err != nil {
+		return err
+	}
+	entry := migrations.JournalEntry{
+		Migration:      id.String(),
+		Target:         target,
+		Timestamp:      time.Now(),
+		Version:        data.CliVersion,
+		CliVersion:     CliVersion,
+		BeforeChecksum: checksum,
+		AfterChecksum:  checksum,
+	}
+
+	if err := r.checkReapply(id, data, checksum); err != nil {
+		entry.Outcome, entry.Error = migrations.OutcomeRefused, err.Error()
+		r.run.Migrations = append(r.run.Migrations, entry)
+		return err
+	}
+
+	migrationFactory, err := migrations.GetMigrationFactory(id)
+	if err != nil {
+		return err
+	}
+	// a failing migration restores the target to its content before running, not to the snapshot
+	if err := r.services.Checkpoint(data.TargetPath); err != nil {
+		return err
+	}
+	migrateErr := migrationFactory.MakeMigration(r.manager, r.services, data).Migrate()
+	if entry.AfterChecksum, err = migrations.TargetChecksum(r.fs, data.TargetPath); err != nil {
+		return err
+	}
+	switch {
+	case migrateErr != nil:
+		entry.Outcome, entry.Error = migrations.OutcomeFailed, migrateErr.Error()
+	case entry.AfterChecksum != entry.BeforeChecksum:
+		entry.Outcome = migrations.OutcomeMigrated
+	default:
+		entry.Outcome = migrations.OutcomeUnchanged
+	}
+	r.run.Migrations = append(r.run.Migrations, entry)
+	return migrateErr
+}
+
+// checkReapply returns an error when the migration already migrated the target to the same
+// version, the target changed since it was logged and the migration would change it again.
+func (r *migrationRunner) checkReapply(id migrations.Migration, data *migrations.MigrationData, checksum string) error {
+	applied := r.journal.LastApplied(id.String(), data.CliVersion)
+	if applied == nil || applied.AfterChecksum == checksum {
+		return nil
+	}
+	state, err := migrations.CheckMigration(id, r.services, data)
+	if err != nil || state != migrations.StatePending {
+		return err
+	}
+	return sveltinerr.NewDefaultError(fmt.Errorf("%s has been changed since the %s migration was applied on %s and it would be migrated again. Restore it or run 'sveltin migrate rollback'",
+		applied.Target, applied.Migration, applied.Timestamp.Format(time.RFC822)))
+}
+
+// migrationTargets returns the path to the target file or folder of each migration but the
+// project settings one, the theme config depending on the project settings.
+func migrationTargets(cwd string) map[migrations.Migration]string {
+	return map[migrations.Migration]string{
+		migrations.DefaultsConfig:     path.Join(cwd, cfg.pathMaker.GetConfigFolder(), DefaultsConfigFile),
+		migrations.WebSiteTS:          path.Join(cwd, cfg.pathMaker.GetConfigFolder(), WebSiteTSFile),
+		migrations.MenuTS:             path.Join(cwd, cfg.pathMaker.GetConfigFolder(), MenuTSFile),
+		migrations.SveltinDTS:         path.Join(cwd, cfg.pathMaker.GetSrcFolder(), SveltinDTSFile),
+		migrations.ResourceLibs:       path.Join(cwd, cfg.pathMaker.GetLibFolder()),
+		migrations.Layout:             path.Join(cwd, cfg.pathMaker.GetRoutesFolder(), LayoutTSFile),
+		migrations.SvelteFiles:        path.Join(cwd, cfg.pathMaker.GetRoutesFolder()),
+		migrations.PageServerTS:       path.Join(cwd, cfg.pathMaker.GetRoutesFolder()),
+		migrations.SveltinioComponent: path.Join(cwd, cfg.pathMaker.GetRoutesFolder()),
+		migrations.ThemeConfig: path.Join(cwd, cfg.pathMaker.GetThemesFolder(),
+			cfg.projectSettings.Theme.Name, cfg.settings.GetThemeConfigFilename()),
+		migrations.ThemeSveltinioComponents: path.Join(cwd, cfg.pathMaker.GetThemesFolder()),
+		migrations.MDsveXConfig:             path.Join(cwd, MDsveXFile),
+		migrations.SvelteConfig:             path.Join(cwd, SvelteConfigFile),
+		migrations.DotEnv:                   path.Join(cwd, DotEnvProdFile),
+		migrations.ViteConfig:               path.Join(cwd, ViteConfigFile),
+		migrations.TSConfig:                 path.Join(cwd, TSConfigFile),
+		migrations.PackageJSON:              path.Join(cwd, PackageJSONFile),
+	}
+}
+
+// migrationsJournalPath returns the path to the journal of the migrations runs.
+func migrationsJournalPath() string {
+	return filepath.Join(cfg.pathMaker.GetRootFolder(), StateFolder, MigrationsJournalFile)
+}
+
+// recordMigrateRun adds the run with the files it modified to the journal. The snapshot
+// folder is removed when no file has been modified.
+func recordMigrateRun(snapshot *migrations.Snapshot, run *migrations.JournalRun) error {
+	files, err := snapshot.Commit()
+	if err != nil {
+		return err
+	}
+	run.Files = files
+	if len(files) == 0 {
+		if err := cfg.fs.RemoveAll(run.Snapshot); err != nil {
+			return err
+		}
+	}
+	journal, err := migrations.LoadJournal(cfg.fs, migrationsJournalPath())
+	if err != nil {
+		return err
+	}
+	journal.Add(run)
+	return journal.Save()
+}
+
+// abortMigrateRun restores sveltin.json when a migration fails, so that the project stays on its
+// version and the next run plans the same migrations, then records the run to the journal.
+func abortMigrateRun(snapshot *migrations.Snapshot, run *migrations.JournalRun, pathToSettings string) error {
+	if err := snapshot.RestoreWithin(pathToSettings); err != nil {
+		return err
+	}
+	return recordMigrateRun(snapshot, run)
+}
+
+// readProjectSettings reads the project settings file from the file system, used on dry-run
+// when the file is only in memory.
+func readProjectSettings(fs afero.Fs, pathToFile string) (prjConfig tpltypes.ProjectSettings, err error) {
+	content, err := afero.ReadFile(fs, pathToFile)
+	if err != nil {
+		return
+	}
+	err = json.Unmarshal(content, &prjConfig)
+	return
+}
+
+// changedSince returns the files changed on dry-run since the previous changes, and updates them.
+func changedSince(fs *migrations.DryRunFs, previous map[string]migrations.FileChange) ([]string, error) {
+	changes, err := fs.Changes()
+	if err != nil {
+		return nil, err
+	}
+	files := []string{}
+	for _, change := range changes {
+		prev, exists := previous[change.Path]
+		if !exists || (prev.After == nil) != (change.After == nil) || !bytes.Equal(prev.After, change.After) {
+			files = append(files, change.Path)
+		}
+		previous[change.Path] = change
+	}
+	return files, nil
+}
+
+// showMigrationsDryRun prints the unified diff of each file changed on dry-run and
+// the migrations that would run.
+func showMigrationsDryRun(fs *migrations.DryRunFs, cwd string, names []string, changedFiles map[string][]string) {
+	changes, err := fs.Changes()
+	utils.ExitIfError(err)
+
+	relPath := func(file string) string {
+		if rel, err := filepath.Rel(cwd, file); err == nil && filepath.IsAbs(file) {
+			return rel
+		}
+		return file
+	}
+	for _, change := range changes {
+		diff, err := change.UnifiedDiff(relPath(change.Path))
+		utils.ExitIfError(err)
+		feedbacks.ShowUnifiedDiff(diff)
+	}
+
+	for name, files := range changedFiles {
+		for i, file := range files {
+			files[i] = relPath(file)
+		}
+		changedFiles[name] = files
+	}
+	feedbacks.ShowMigrationsSummary(names, changedFiles)
+}
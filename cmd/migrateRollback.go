@@ -0,0 +1,80 @@
+/**
+ * Copyright © 2021-present Sveltin contributors <github@sveltin.io>
+ *
+ * Use of this source code is governed by Apache 2.0 license
+ * that can be found in the LICENSE file.
+ */
+
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/spf13/cobra"
+	"github.com/sveltinio/prompti/confirm"
+	"github.com/sveltinio/sveltin/internal/markup"
+	"github.com/sveltinio/sveltin/internal/migrations"
+	"github.com/sveltinio/sveltin/tui/feedbacks"
+	"github.com/sveltinio/sveltin/utils"
+)
+
+var migrateRollbackCmd = &cobra.Command{
+	Use:   "rollback",
+	Short: "Undo the last migrate run",
+	Long: `Command used to restore the files modified by the last run of 'sveltin migrate'.
+
+Before running the migrations, 'sveltin migrate' saves the target files to a snapshot
+folder within .sveltin/snapshots and records the run in .sveltin/migrations.json.
+The rollback restores exactly the files modified by the run to their previous content,
+and removes the ones it created. Running it again undoes the run before.
+`,
+	Args: cobra.ExactArgs(0),
+	Run:  RunMigrateRollbackCmd,
+}
+
+// RunMigrateRollbackCmd is the actual work function.
+func RunMigrateRollbackCmd(cmd *cobra.Command, args []string) {
+	// Exit if running sveltin commands from a not valid directory.
+	isValidProject(false)
+
+	journal, err := migrations.LoadJournal(cfg.fs, migrationsJournalPath())
+	utils.ExitIfError(err)
+	run := journal.LastRun()
+	if run == nil {
+		cfg.log.Info("Nothing to roll back")
+		return
+	}
+
+	cfg.log.Plain(markup.H1(fmt.Sprintf("Rolling back the migrate run of %s", run.StartedAt.Format(time.RFC822))))
+	feedbacks.ShowMigrateRollback(run)
+
+	isConfirm := isYes
+	if !isConfirm {
+		isConfirm, err = confirm.Run(&confirm.Config{Question: "Continue?"})
+		utils.ExitIfError(err)
+	}
+
+	if isConfirm {
+		cwd, _ := os.Getwd()
+		snapshot := migrations.NewSnapshot(cfg.fs, cwd, filepath.Join(cwd, run.Snapshot))
+		utils.ExitIfError(snapshot.Restore(run.Files))
+
+		rolledBackAt := time.Now()
+		run.RolledBackAt = &rolledBackAt
+		utils.ExitIfError(journal.Save())
+
+		cfg.log.Success(fmt.Sprintf("Done! Your project is back to sveltin v%s\n", run.ProjectCliVersion))
+	}
+}
+
+func migrateRollbackCmdFlags(cmd *cobra.Command) {
+	cmd.Flags().BoolVarP(&isYes, "yes", "y", false, "do not prompt for confirmation")
+}
+
+func init() {
+	migrateRollbackCmdFlags(migrateRollbackCmd)
+	migrateCmd.AddCommand(migrateRollbackCmd)
+}
@@ -0,0 +1,100 @@
+/**
+ * Copyright © 2021-present Sveltin contributors <github@sveltin.io>
+ *
+ * Use of this source code is governed by Apache 2.0 license
+ * that can be found in the LICENSE file.
+ */
+
+package cmd
+
+import (
+	"encoding/json"
+	"os"
+	"path"
+	"path/filepath"
+
+	"github.com/spf13/cobra"
+	sveltinerr "github.com/sveltinio/sveltin/internal/errors"
+	"github.com/sveltinio/sveltin/internal/migrations"
+	"github.com/sveltinio/sveltin/tui/feedbacks"
+	"github.com/sveltinio/sveltin/utils"
+)
+
+var migrateStatusCmd = &cobra.Command{
+	Use:   "status",
+	Short: "List the pending and applied migrations",
+	Long: `Command used to show which migrations still apply to the project.
+
+Each migration is checked against its target file or folder without modifying anything:
+it is pending when running it would change any file, applied when its target is already
+migrated and not-applicable when its target does not exist. The time the migration last
+migrated its target is read from the journal in .sveltin/migrations.json.
+Use --report json to print the status as JSON.
+`,
+	DisableFlagsInUseLine: true,
+	Args:                  cobra.ExactArgs(0),
+	Run:                   RunMigrateStatusCmd,
+}
+
+// RunMigrateStatusCmd is the actual work function.
+func RunMigrateStatusCmd(cmd *cobra.Command, args []string) {
+	// Exit if running sveltin commands from a not valid directory.
+	isValidProject(false)
+
+	if withReport != "" && withReport != JSONReport {
+		utils.ExitIfError(sveltinerr.NewOptionNotValidError(withReport, []string{JSONReport}))
+	}
+
+	report := migrations.StatusReport{
+		CliVersion:        CliVersion,
+		ProjectCliVersion: cfg.projectSettings.Sveltin.Version,
+		Migrations:        []migrations.MigrationStatus{},
+	}
+	cwd, _ := os.Getwd()
+	targets := migrationTargets(cwd)
+	targets[migrations.ProjectSettings] = path.Join(cwd, ProjectSettingsFile)
+	migrationServices := migrations.NewMigrationServices(cfg.fs, cfg.fsManager, cfg.pathMaker, cfg.log)
+	journal, err := migrations.LoadJournal(cfg.fs, migrationsJournalPath())
+	utils.ExitIfError(err)
+
+	for _, k := range sortedMigrationMap(targets) {
+		id := migrations.Migration(k)
+		migrationData := &migrations.MigrationData{
+			TargetPath:        targets[id],
+			CliVersion:        CliVersion,
+			ProjectCliVersion: report.ProjectCliVersion,
+		}
+		state, err := migrations.CheckMigration(id, migrationServices, migrationData)
+		utils.ExitIfError(err)
+
+		target, err := filepath.Rel(cwd, targets[id])
+		utils.ExitIfError(err)
+		status := migrations.MigrationStatus{
+			Name:   id.String(),
+			Target: target,
+			State:  state,
+			Since:  id.Since(),
+		}
+		if applied := journal.LastApplied(id.String(), ""); applied != nil {
+			status.AppliedAt = &applied.Timestamp
+		}
+		report.Migrations = append(report.Migrations, status)
+	}
+
+	if withReport == JSONReport {
+		encoder := json.NewEncoder(os.Stdout)
+		encoder.SetIndent("", "  ")
+		utils.ExitIfError(encoder.Encode(report))
+		return
+	}
+	feedbacks.ShowMigrationsStatus(report.ProjectCliVersion, report.CliVersion, report.Migrations)
+}
+
+func migrateStatusCmdFlags(cmd *cobra.Command) {
+	cmd.Flags().StringVar(&withReport, "report", "", "print the status as machine-readable output. Valid values: json")
+}
+
+func init() {
+	migrateStatusCmdFlags(migrateStatusCmd)
+	migrateCmd.AddCommand(migrateStatusCmd)
+}
@@ -0,0 +1,294 @@
+package cmd
+
+import (
+	"bytes"
+	"io"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"testing"
+
+	"github.com/matryer/is"
+	"github.com/spf13/afero"
+	"github.com/sveltinio/sveltin/internal/migrations"
+)
+
+// failingWriteFs fails writing the file at path once, as a migration failing halfway.
+type failingWriteFs struct {
+	afero.Fs
+	path   string
+	failed bool
+}
+
+func (fs *failingWriteFs) OpenFile(name string, flag int, perm os.FileMode) (afero.File, error) {
+	if name == fs.path && flag&(os.O_WRONLY|os.O_RDWR) != 0 && !fs.failed {
+		fs.failed = true
+		return nil, os.ErrPermission
+	}
+	return fs.Fs.OpenFile(name, flag, perm)
+}
+
+const testMigrateSvelteConfig = "const config = {\n\tkit: {\n\t\ttrailingSlash: 'always'\n\t}\n};\n"
+
+// setupMigrateProject creates a project on sveltin v0.10.0 in a temporary folder and makes it the current one.
+func setupMigrateProject(t *testing.T) {
+	t.Helper()
+	setupTestProject(t, map[string]string{
+		PackageJSONFile:     `{"name": "my-site"}`,
+		ProjectSettingsFile: strings.Replace(testSveltinJSON, "%ASSETS%", "build", 1),
+		filepath.Join("config", DefaultsConfigFile):                 "export const sveltinVersion = '0.10.0';\n",
+		filepath.Join("config", WebSiteTSFile):                      "",
+		filepath.Join("config", MenuTSFile):                         "",
+		filepath.Join("src", SveltinDTSFile):                        "",
+		filepath.Join("src", "routes", LayoutTSFile):                "",
+		filepath.Join("themes", "sveltin_theme", "theme.config.js"): "",
+		MDsveXFile:       "",
+		SvelteConfigFile: testMigrateSvelteConfig,
+		ViteConfigFile:   "",
+		TSConfigFile:     "{}",
+		DotEnvProdFile:   "VITE_PUBLIC_BASE_PATH=/\n",
+	})
+	isDryRun = false
+}
+
+// captureStdout returns what fn writes to stdout.
+func captureStdout(t *testing.T, fn func()) string {
+	t.Helper()
+	r, w, err := os.Pipe()
+	if err != nil {
+		t.Fatal(err)
+	}
+	stdout := os.Stdout
+	os.Stdout = w
+	defer func() { os.Stdout = stdout }()
+
+	out := make(chan string)
+	go func() {
+		var buf bytes.Buffer
+		io.Copy(&buf, r)
+		out <- buf.String()
+	}()
+	fn()
+	w.Close()
+	return <-out
+}
+
+func TestMigrateCmdDryRun(t *testing.T) {
+	is := is.New(t)
+	setupMigrateProject(t)
+	settings, err := os.ReadFile(ProjectSettingsFile)
+	is.NoErr(err)
+	isDryRun = true
+
+	out := captureStdout(t, func() {
+		RunMigrateCmd(migrateCmd, []string{})
+	})
+
+	out = regexp.MustCompile("\x1b\\[[0-9;]*m").ReplaceAllString(out, "")
+
+	// the unified diff of each changed file
+	is.True(strings.Contains(out, "--- a/svelte.config.js\n+++ b/svelte.config.js\n@@ -1,5 +1,5 @@\n const config = {\n \tkit: {\n-\t\ttrailingSlash: 'always'\n+\n"))
+	is.True(strings.Contains(out, "--- a/sveltin.json\n+++ b/sveltin.json\n"))
+	is.True(strings.Contains(out, "\n-\t\"sveltin\": {\"version\": \"0.10.0\"},\n+\t\"sveltin\": {\"version\": \"0.11.0\"},\n"))
+	is.True(strings.Contains(out, "--- a/config/defaults.js.ts\n+++ b/config/defaults.js.ts\n@@ -1 +1,3 @@\n-export const sveltinVersion = '0.10.0';\n"))
+	// the migrations that would run, with the files they change
+	is.True(strings.Contains(out, "+ project-settings (1 file)\n    sveltin.json\n"))
+	is.True(strings.Contains(out, "+ defaults-ts (1 file)\n    config/defaults.js.ts\n"))
+	is.True(strings.Contains(out, "+ sveltin-dts (1 file)\n    src/sveltin.d.ts\n"))
+	is.True(strings.Contains(out, "+ svelte-config-js (1 file)\n    svelte.config.js\n"))
+	is.True(strings.Contains(out, "  package-json (nothing to migrate)\n"))
+	is.True(strings.Contains(out, "\n4 of 18 migrations would run\n"))
+
+	// nothing is written to disk
+	content, err := os.ReadFile(SvelteConfigFile)
+	is.NoErr(err)
+	is.Equal(string(content), testMigrateSvelteConfig)
+	content, err = os.ReadFile(ProjectSettingsFile)
+	is.NoErr(err)
+	is.Equal(string(content), string(settings))
+}
+
+func TestMigrateCmdCurrentProject(t *testing.T) {
+	is := is.New(t)
+	setupMigrateProject(t)
+	cfg.projectSettings.Sveltin.Version = CliVersion
+
+	// no prompt, nothing to migrate
+	RunMigrateCmd(migrateCmd, []string{})
+
+	_, err := os.Stat(StateFolder)
+	is.True(os.IsNotExist(err))
+}
+
+func TestMigrateRollbackCmd(t *testing.T) {
+	is := is.New(t)
+	setupMigrateProject(t)
+	cwd, err := os.Getwd()
+	is.NoErr(err)
+	is.NoErr(os.WriteFile(SvelteConfigFile, []byte("old"), 0644))
+	// the vite config is created by the run
+	is.NoErr(os.Remove(ViteConfigFile))
+
+	run := &migrations.JournalRun{ID: "1", ProjectCliVersion: "0.10.0", Snapshot: filepath.Join(StateFolder, MigrationSnapshotsFolder, "1")}
+	snapshot := migrations.NewSnapshot(cfg.fs, cwd, filepath.Join(cwd, run.Snapshot))
+	is.NoErr(snapshot.Add(filepath.Join(cwd, SvelteConfigFile)))
+	is.NoErr(snapshot.Add(filepath.Join(cwd, ViteConfigFile)))
+	is.NoErr(os.WriteFile(SvelteConfigFile, []byte("new"), 0644))
+	is.NoErr(os.WriteFile(ViteConfigFile, []byte("created"), 0644))
+	is.NoErr(recordMigrateRun(snapshot, run))
+
+	RunMigrateRollbackCmd(migrateRollbackCmd, []string{})
+
+	content, err := os.ReadFile(SvelteConfigFile)
+	is.NoErr(err)
+	is.Equal(string(content), "old")
+	_, err = os.Stat(ViteConfigFile)
+	is.True(os.IsNotExist(err))
+	journal, err := migrations.LoadJournal(cfg.fs, migrationsJournalPath())
+	is.NoErr(err)
+	is.True(journal.Runs[0].RolledBackAt != nil)
+	is.Equal(journal.LastRun(), nil)
+}
+
+func TestMigrationRunnerJournal(t *testing.T) {
+	is := is.New(t)
+	setupMigrateProject(t)
+	cwd, err := os.Getwd()
+	is.NoErr(err)
+
+	journal, err := migrations.LoadJournal(cfg.fs, migrationsJournalPath())
+	is.NoErr(err)
+	journal.Add(&migrations.JournalRun{ID: "1", Migrations: []migrations.JournalEntry{
+		{Migration: migrations.SvelteConfig.String(), Target: SvelteConfigFile, Version: "0.11.0", AfterChecksum: "old", Outcome: migrations.OutcomeMigrated},
+	}})
+	run := &migrations.JournalRun{ID: "2"}
+	runner := &migrationRunner{
+		fs:       cfg.fs,
+		cwd:      cwd,
+		manager:  migrations.NewMigrationManager(),
+		services: migrations.NewMigrationServices(cfg.fs, cfg.fsManager, cfg.pathMaker, cfg.log),
+		journal:  journal,
+		run:      run,
+	}
+	data := &migrations.MigrationData{TargetPath: filepath.Join(cwd, SvelteConfigFile), CliVersion: "0.11.0"}
+
+	// the target changed since the migration was logged and it would be migrated again
+	is.True(runner.migrate(migrations.SvelteConfig, data) != nil)
+	is.Equal(run.Migrations[0].Outcome, migrations.OutcomeRefused)
+	content, err := os.ReadFile(SvelteConfigFile)
+	is.NoErr(err)
+	is.Equal(string(content), testMigrateSvelteConfig)
+
+	// migrating to another version
+	data.CliVersion = "0.12.0"
+	is.NoErr(runner.migrate(migrations.SvelteConfig, data))
+	entry := run.Migrations[1]
+	is.Equal(entry.Outcome, migrations.OutcomeMigrated)
+	is.Equal(entry.Target, SvelteConfigFile)
+	is.True(entry.BeforeChecksum != entry.AfterChecksum)
+	checksum, err := migrations.TargetChecksum(cfg.fs, data.TargetPath)
+	is.NoErr(err)
+	is.Equal(entry.AfterChecksum, checksum)
+}
+
+func TestMigrationRunnerFailingStep(t *testing.T) {
+	is := is.New(t)
+	setupMigrateProject(t)
+	cwd, err := os.Getwd()
+	is.NoErr(err)
+	// the dotenv migration fails without the env file
+	is.NoErr(os.Remove(DotEnvProdFile))
+
+	run := &migrations.JournalRun{ID: "1", ProjectCliVersion: "0.10.0", Snapshot: filepath.Join(StateFolder, MigrationSnapshotsFolder, "1")}
+	snapshot := migrations.NewSnapshot(cfg.fs, cwd, filepath.Join(cwd, run.Snapshot))
+	journal, err := migrations.LoadJournal(cfg.fs, migrationsJournalPath())
+	is.NoErr(err)
+	services := migrations.NewMigrationServices(cfg.fs, cfg.fsManager, cfg.pathMaker, cfg.log)
+	services.SetSnapshot(snapshot)
+	runner := &migrationRunner{
+		fs:       cfg.fs,
+		cwd:      cwd,
+		manager:  migrations.NewMigrationManager(),
+		services: services,
+		journal:  journal,
+		run:      run,
+	}
+
+	pathToSettings := filepath.Join(cwd, ProjectSettingsFile)
+	targets := migrationTargets(cwd)
+	for _, target := range []string{pathToSettings, targets[migrations.SvelteConfig], targets[migrations.DotEnv]} {
+		is.NoErr(snapshot.Add(target))
+	}
+	is.NoErr(runner.migrate(migrations.ProjectSettings, &migrations.MigrationData{TargetPath: pathToSettings, CliVersion: CliVersion, ProjectCliVersion: "0.10.0"}))
+	is.NoErr(runner.migrate(migrations.SvelteConfig, &migrations.MigrationData{TargetPath: targets[migrations.SvelteConfig], CliVersion: CliVersion, ProjectCliVersion: "0.10.0"}))
+	is.True(runner.migrate(migrations.DotEnv, &migrations.MigrationData{TargetPath: targets[migrations.DotEnv], CliVersion: CliVersion, ProjectCliVersion: "0.10.0"}) != nil)
+
+	is.NoErr(abortMigrateRun(snapshot, run, pathToSettings))
+
+	// the project stays on its version, the next run plans the same migrations
+	settings, err := loadProjectSettings(ProjectSettingsFile)
+	is.NoErr(err)
+	is.Equal(settings.Sveltin.Version, "0.10.0")
+	plan, err := migrations.NewMigrationPlan(settings.Sveltin.Version, CliVersion)
+	is.NoErr(err)
+	is.True(len(plan) > 0)
+	// the run is recorded with the files migrated before the failure
+	journal, err = migrations.LoadJournal(cfg.fs, migrationsJournalPath())
+	is.NoErr(err)
+	is.Equal(journal.LastRun().Files, []migrations.SnapshotFile{{Path: SvelteConfigFile, Existed: true}})
+}
+
+func TestMigrationRunnerFailingFolderTarget(t *testing.T) {
+	is := is.New(t)
+	setupMigrateProject(t)
+	cwd, err := os.Getwd()
+	is.NoErr(err)
+	pathToLayoutTS := filepath.Join("src", "routes", LayoutTSFile)
+	pathToLayout := filepath.Join("src", "routes", "+layout.svelte")
+	pathToPage := filepath.Join("src", "routes", "blog", "+page.svelte")
+	is.NoErr(os.WriteFile(pathToLayoutTS, []byte("export const prerender = true;\n"), 0644))
+	is.NoErr(os.WriteFile(pathToLayout, []byte("<a data-sveltekit-prefetch href=\"/\">Home</a>\n"), 0644))
+	is.NoErr(os.MkdirAll(filepath.Dir(pathToPage), 0755))
+	is.NoErr(os.WriteFile(pathToPage, []byte("<a data-sveltekit-prefetch href=\"/blog\">Blog</a>\n"), 0644))
+
+	// the svelte files migration fails writing the second file within src/routes
+	fs := &failingWriteFs{Fs: cfg.fs, path: filepath.Join(cwd, pathToPage)}
+	run := &migrations.JournalRun{ID: "1", ProjectCliVersion: "0.10.0", Snapshot: filepath.Join(StateFolder, MigrationSnapshotsFolder, "1")}
+	snapshot := migrations.NewSnapshot(fs, cwd, filepath.Join(cwd, run.Snapshot))
+	journal, err := migrations.LoadJournal(fs, migrationsJournalPath())
+	is.NoErr(err)
+	services := migrations.NewMigrationServices(fs, cfg.fsManager, cfg.pathMaker, cfg.log)
+	services.SetSnapshot(snapshot)
+	runner := &migrationRunner{
+		fs:       fs,
+		cwd:      cwd,
+		manager:  migrations.NewMigrationManager(),
+		services: services,
+		journal:  journal,
+		run:      run,
+	}
+
+	targets := migrationTargets(cwd)
+	for _, id := range []migrations.Migration{migrations.Layout, migrations.SvelteFiles} {
+		is.NoErr(snapshot.Add(targets[id]))
+	}
+	is.NoErr(runner.migrate(migrations.Layout, &migrations.MigrationData{TargetPath: targets[migrations.Layout], CliVersion: CliVersion, ProjectCliVersion: "0.10.0"}))
+	is.True(runner.migrate(migrations.SvelteFiles, &migrations.MigrationData{TargetPath: targets[migrations.SvelteFiles], CliVersion: CliVersion, ProjectCliVersion: "0.10.0"}) != nil)
+
+	// the failing migration undoes its own changes only
+	is.Equal(run.Migrations[0].Outcome, migrations.OutcomeMigrated)
+	is.Equal(run.Migrations[1].Outcome, migrations.OutcomeFailed)
+	checksum, err := migrations.TargetChecksum(fs, targets[migrations.Layout])
+	is.NoErr(err)
+	is.Equal(run.Migrations[0].AfterChecksum, checksum)
+	content, err := os.ReadFile(pathToLayoutTS)
+	is.NoErr(err)
+	is.True(strings.Contains(string(content), "export const trailingSlash"))
+	content, err = os.ReadFile(pathToLayout)
+	is.NoErr(err)
+	is.Equal(string(content), "<a data-sveltekit-prefetch href=\"/\">Home</a>\n")
+	content, err = os.ReadFile(pathToPage)
+	is.NoErr(err)
+	is.Equal(string(content), "<a data-sveltekit-prefetch href=\"/blog\">Blog</a>\n")
+}
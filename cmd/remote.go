@@ -0,0 +1,54 @@
+/**
+ * Copyright © 2021-present Sveltin contributors <github@sveltin.io>
+ *
+ * Use of this source code is governed by Apache 2.0 license
+ * that can be found in the LICENSE file.
+ */
+
+package cmd
+
+import (
+	"github.com/spf13/cobra"
+	"github.com/sveltinio/sveltin/internal/ftpfs"
+	"github.com/sveltinio/sveltin/resources"
+	"github.com/sveltinio/sveltin/utils"
+)
+
+var remoteCmd = &cobra.Command{
+	Use:   "remote",
+	Short: "Browse and fetch the files on the remote server",
+	Long: resources.GetASCIIArt() + `
+Command used to list, download, measure and delete the files within the remote folder
+through its own subcommands, using the connection settings from the .env.<name> file
+(see --env) as the deploy command does.
+
+The paths are relative to the remote folder (FTP_SERVER_FOLDER).
+
+Run 'sveltin remote -h' for further details.
+`,
+	ValidArgs:             []string{"ls", "get", "du", "rm"},
+	Args:                  cobra.MatchAll(cobra.ExactArgs(1), cobra.OnlyValidArgs),
+	DisableFlagsInUseLine: true,
+}
+
+func init() {
+	rootCmd.AddCommand(remoteCmd)
+}
+
+//=============================================================================
+
+// remoteCmdFlags adds the flags to choose the remote server, shared by the remote subcommands.
+func remoteCmdFlags(cmd *cobra.Command) {
+	cmd.Flags().StringVar(&withEnv, "env", DefaultEnvironment, "name of the environment to connect to, loaded from the .env.<name> file")
+	cmd.Flags().StringVarP(&withTarget, "target", "t", "", "remote target overriding DEPLOY_PROTOCOL, e.g. dir:/srv/www/site")
+}
+
+// listRemoteFiles loads the environment, connects to the remote server and lists the files
+// within the remote folder. The caller logs out.
+func listRemoteFiles() (ftpfs.RemoteServer, []ftpfs.RemoteFile) {
+	loadEnvironment(withEnv, true)
+	remoteServer, _ := connectRemoteServer()
+	files, err := remoteServer.ListFiles()
+	utils.ExitIfError(err)
+	return remoteServer, files
+}
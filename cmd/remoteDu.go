@@ -0,0 +1,66 @@
+/**
+ * Copyright © 2021-present Sveltin contributors <github@sveltin.io>
+ *
+ * Use of this source code is governed by Apache 2.0 license
+ * that can be found in the LICENSE file.
+ */
+
+package cmd
+
+import (
+	"fmt"
+	"sort"
+
+	"github.com/spf13/cobra"
+	sveltinerr "github.com/sveltinio/sveltin/internal/errors"
+	"github.com/sveltinio/sveltin/internal/ftpfs"
+	"github.com/sveltinio/sveltin/internal/markup"
+	"github.com/sveltinio/sveltin/tui/feedbacks"
+	"github.com/sveltinio/sveltin/utils"
+)
+
+var remoteDuCmd = &cobra.Command{
+	Use:   "du [path]",
+	Short: "Show the disk usage on the remote server",
+	Long: `Command used to show the total size and number of files within a remote folder
+(default: the remote folder itself), with the size of each of its entries, largest first.
+`,
+	Args: cobra.MaximumNArgs(1),
+	Run:  RunRemoteDuCmd,
+}
+
+// RunRemoteDuCmd is the actual work function.
+func RunRemoteDuCmd(cmd *cobra.Command, args []string) {
+	// Exit if running sveltin commands either from a not valid directory or not latest sveltin version.
+	isValidProject(true)
+
+	dir := ""
+	if len(args) == 1 {
+		dir = ftpfs.CleanRemotePath(args[0])
+	}
+
+	cfg.log.Plain(markup.H1("Disk usage on the remote server"))
+
+	remoteServer, files := listRemoteFiles()
+	utils.ExitIfError(ftpfs.LogoutAction(remoteServer).Run())
+
+	files = ftpfs.FilesWithin(files, dir)
+	if len(files) == 0 && dir != "" {
+		utils.ExitIfError(sveltinerr.NewFileNotFoundError(dir))
+	}
+
+	var totalSize int64
+	for _, file := range files {
+		totalSize += file.Size
+	}
+	entries := ftpfs.ReadRemoteDir(files, dir)
+	sort.SliceStable(entries, func(i, j int) bool {
+		return entries[i].Size > entries[j].Size
+	})
+	feedbacks.ShowRemoteDir(fmt.Sprintf("/%s: %d files, %s", dir, len(files), utils.HumanizeBytes(totalSize)), entries)
+}
+
+func init() {
+	remoteCmdFlags(remoteDuCmd)
+	remoteCmd.AddCommand(remoteDuCmd)
+}
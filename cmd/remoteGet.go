@@ -0,0 +1,103 @@
+/**
+ * Copyright © 2021-present Sveltin contributors <github@sveltin.io>
+ *
+ * Use of this source code is governed by Apache 2.0 license
+ * that can be found in the LICENSE file.
+ */
+
+package cmd
+
+import (
+	"errors"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+
+	"github.com/spf13/afero"
+	"github.com/spf13/cobra"
+	sveltinerr "github.com/sveltinio/sveltin/internal/errors"
+	"github.com/sveltinio/sveltin/internal/ftpfs"
+	"github.com/sveltinio/sveltin/internal/markup"
+	"github.com/sveltinio/sveltin/utils"
+)
+
+// StdoutOutput is the --output value to write a downloaded file to stdout.
+const StdoutOutput string = "-"
+
+var withOutput string
+
+var remoteGetCmd = &cobra.Command{
+	Use:   "get <path>",
+	Short: "Download a file or a folder from the remote server",
+	Long: `Command used to download a file, or a folder with all the files within it, from the remote folder.
+
+It is saved in the current folder with the same name unless --output is set.
+Use --output - to write a file to stdout, e.g. sveltin remote get .htaccess -o - | less
+`,
+	Args: cobra.ExactArgs(1),
+	Run:  RunRemoteGetCmd,
+}
+
+// RunRemoteGetCmd is the actual work function.
+func RunRemoteGetCmd(cmd *cobra.Command, args []string) {
+	// Exit if running sveltin commands either from a not valid directory or not latest sveltin version.
+	isValidProject(true)
+
+	remotePath := ftpfs.CleanRemotePath(args[0])
+	if remotePath == "" {
+		utils.ExitIfError(sveltinerr.NewDefaultError(errors.New("the path must be a file or a folder within the remote folder")))
+	}
+	if withOutput == StdoutOutput {
+		// stdout only gets the file content
+		useStderrLogger()
+	}
+
+	cfg.log.Plain(markup.H1("Downloading from the remote server"))
+
+	remoteServer, files := listRemoteFiles()
+	files = ftpfs.FilesWithin(files, remotePath)
+	if len(files) == 0 {
+		utils.ExitIfError(sveltinerr.NewFileNotFoundError(remotePath))
+	}
+
+	saveAs := withOutput
+	if saveAs == "" {
+		saveAs = path.Base(remotePath)
+	}
+	isFolder := files[0].Path != remotePath
+	if isFolder && saveAs == StdoutOutput {
+		utils.ExitIfError(sveltinerr.NewDefaultError(errors.New("a folder cannot be written to stdout")))
+	}
+
+	for _, file := range files {
+		content, err := remoteServer.ReadFile(file.Path)
+		utils.ExitIfError(err)
+		if saveAs == StdoutOutput {
+			_, err = os.Stdout.Write(content)
+			utils.ExitIfError(err)
+			continue
+		}
+
+		localPath := saveAs
+		if isFolder {
+			localPath = filepath.Join(saveAs, filepath.FromSlash(strings.TrimPrefix(file.Path, remotePath+"/")))
+		}
+		cfg.log.Infof("Saving %s as %s", file.Path, localPath)
+		utils.ExitIfError(cfg.fs.MkdirAll(filepath.Dir(localPath), os.ModePerm))
+		utils.ExitIfError(afero.WriteFile(cfg.fs, localPath, content, 0644))
+	}
+
+	utils.ExitIfError(ftpfs.LogoutAction(remoteServer).Run())
+	cfg.log.Success("Done\n")
+}
+
+func remoteGetCmdFlags(cmd *cobra.Command) {
+	remoteCmdFlags(cmd)
+	cmd.Flags().StringVarP(&withOutput, "output", "o", "", "local path to save the file or folder as, - to write a file to stdout")
+}
+
+func init() {
+	remoteGetCmdFlags(remoteGetCmd)
+	remoteCmd.AddCommand(remoteGetCmd)
+}
@@ -0,0 +1,74 @@
+/**
+ * Copyright © 2021-present Sveltin contributors <github@sveltin.io>
+ *
+ * Use of this source code is governed by Apache 2.0 license
+ * that can be found in the LICENSE file.
+ */
+
+package cmd
+
+import (
+	"fmt"
+
+	"github.com/spf13/cobra"
+	sveltinerr "github.com/sveltinio/sveltin/internal/errors"
+	"github.com/sveltinio/sveltin/internal/ftpfs"
+	"github.com/sveltinio/sveltin/internal/markup"
+	"github.com/sveltinio/sveltin/tui/feedbacks"
+	"github.com/sveltinio/sveltin/utils"
+)
+
+var isRecursive bool
+
+var remoteLsCmd = &cobra.Command{
+	Use:   "ls [path]",
+	Short: "List the files on the remote server",
+	Long: `Command used to list the files and folders within a remote folder (default: the remote folder itself)
+with their size and last modification time. The size of a folder is the total size of the files within it.
+
+Use --recursive to list all the files within the folder.
+`,
+	Args: cobra.MaximumNArgs(1),
+	Run:  RunRemoteLsCmd,
+}
+
+// RunRemoteLsCmd is the actual work function.
+func RunRemoteLsCmd(cmd *cobra.Command, args []string) {
+	// Exit if running sveltin commands either from a not valid directory or not latest sveltin version.
+	isValidProject(true)
+
+	dir := ""
+	if len(args) == 1 {
+		dir = ftpfs.CleanRemotePath(args[0])
+	}
+
+	cfg.log.Plain(markup.H1("Listing the remote files"))
+
+	remoteServer, files := listRemoteFiles()
+	utils.ExitIfError(ftpfs.LogoutAction(remoteServer).Run())
+
+	files = ftpfs.FilesWithin(files, dir)
+	if len(files) == 0 && dir != "" {
+		utils.ExitIfError(sveltinerr.NewFileNotFoundError(dir))
+	}
+
+	var entries []ftpfs.RemoteDirEntry
+	if isRecursive {
+		for _, file := range files {
+			entries = append(entries, ftpfs.RemoteDirEntry{Name: file.Path, Files: 1, Size: file.Size, ModTime: file.ModTime})
+		}
+	} else {
+		entries = ftpfs.ReadRemoteDir(files, dir)
+	}
+	feedbacks.ShowRemoteDir(fmt.Sprintf("%d entries in /%s", len(entries), dir), entries)
+}
+
+func remoteLsCmdFlags(cmd *cobra.Command) {
+	remoteCmdFlags(cmd)
+	cmd.Flags().BoolVarP(&isRecursive, "recursive", "r", false, "list all the files within the folder")
+}
+
+func init() {
+	remoteLsCmdFlags(remoteLsCmd)
+	remoteCmd.AddCommand(remoteLsCmd)
+}
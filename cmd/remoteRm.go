@@ -0,0 +1,138 @@
+/**
+ * Copyright © 2021-present Sveltin contributors <github@sveltin.io>
+ *
+ * Use of this source code is governed by Apache 2.0 license
+ * that can be found in the LICENSE file.
+ */
+
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"path"
+
+	"github.com/spf13/cobra"
+	"github.com/sveltinio/prompti/confirm"
+	sveltinerr "github.com/sveltinio/sveltin/internal/errors"
+	"github.com/sveltinio/sveltin/internal/ftpfs"
+	"github.com/sveltinio/sveltin/internal/markup"
+	"github.com/sveltinio/sveltin/tui/feedbacks"
+	"github.com/sveltinio/sveltin/utils"
+)
+
+var remoteRmCmd = &cobra.Command{
+	Use:   "rm <path...>",
+	Short: "Delete files or folders from the remote server",
+	Long: `Command used to delete one or more files or folders, with all the files within them, from the remote folder.
+
+The files to be deleted are listed before confirming. The deleted files are removed
+from the manifest saved by the deploy command too, so that the next incremental deploy
+uploads them again when they are still part of the build.
+`,
+	Args: cobra.MinimumNArgs(1),
+	Run:  RunRemoteRmCmd,
+}
+
+// RunRemoteRmCmd is the actual work function.
+func RunRemoteRmCmd(cmd *cobra.Command, args []string) {
+	// Exit if running sveltin commands either from a not valid directory or not latest sveltin version.
+	isValidProject(true)
+
+	setupNonInteractiveMode()
+
+	remotePaths := []string{}
+	for _, arg := range args {
+		remotePath := ftpfs.CleanRemotePath(arg)
+		if remotePath == "" {
+			utils.ExitIfError(sveltinerr.NewDefaultError(errors.New("the remote folder itself cannot be deleted, use a path within it")))
+		}
+		remotePaths = append(remotePaths, remotePath)
+	}
+
+	cfg.log.Plain(markup.H1("Deleting from the remote server"))
+
+	remoteServer, files := listRemoteFiles()
+	toDelete := []string{}
+	folders := []string{}
+	entries := []ftpfs.RemoteDirEntry{}
+	for _, remotePath := range remotePaths {
+		within := ftpfs.FilesWithin(files, remotePath)
+		if len(within) == 0 {
+			utils.ExitIfError(sveltinerr.NewFileNotFoundError(remotePath))
+		}
+		if within[0].Path != remotePath {
+			folders = append(folders, remotePath)
+		}
+		for _, file := range within {
+			toDelete = append(toDelete, file.Path)
+			entries = append(entries, ftpfs.RemoteDirEntry{Name: file.Path, Files: 1, Size: file.Size, ModTime: file.ModTime})
+		}
+	}
+	feedbacks.ShowRemoteDir(fmt.Sprintf("%d files to be deleted", len(toDelete)), entries)
+
+	if isDryRun {
+		feedbacks.ShowDryRunMessage()
+	}
+
+	isConfirm := isYes
+	if !isConfirm {
+		var err error
+		isConfirm, err = confirm.Run(&confirm.Config{Question: "Continue?"})
+		utils.ExitIfError(err)
+	}
+
+	if isConfirm {
+		utils.ExitIfError(ftpfs.DeleteFilesAction(remoteServer, toDelete, isDryRun).Run())
+		// git does not track folders
+		if !isGitDeploy() {
+			for _, folder := range folders {
+				utils.ExitIfError(ftpfs.RemoveDirAction(remoteServer, path.Join(deployFolder(), folder), isDryRun).Run())
+			}
+		}
+		utils.ExitIfError(forgetDeployedFiles(remoteServer, toDelete))
+	}
+
+	utils.ExitIfError(ftpfs.LogoutAction(remoteServer).Run())
+	if isConfirm {
+		cfg.log.Success("Done\n")
+	}
+}
+
+// forgetDeployedFiles removes the files from the manifest on the remote folder, if any.
+func forgetDeployedFiles(server ftpfs.RemoteServer, files []string) error {
+	manifest, err := ftpfs.FetchManifest(server)
+	if err != nil {
+		// no manifest, nothing to update
+		return nil
+	}
+	isChanged := false
+	for _, file := range files {
+		if file == ftpfs.ManifestFilename {
+			return nil
+		}
+		if _, exists := manifest.Files[file]; exists {
+			delete(manifest.Files, file)
+			isChanged = true
+		}
+	}
+	if !isChanged {
+		return nil
+	}
+	content, err := manifest.Bytes()
+	if err != nil {
+		return err
+	}
+	return ftpfs.WriteFileAction(server, ftpfs.ManifestFilename, content, isDryRun).Run()
+}
+
+func remoteRmCmdFlags(cmd *cobra.Command) {
+	remoteCmdFlags(cmd)
+	cmd.Flags().BoolVarP(&isDryRun, "dryRun", "d", false, "dry run")
+	cmd.Flags().BoolVarP(&isYes, "yes", "y", false, "do not prompt for confirmation and print plain progress lines")
+}
+
+func init() {
+	remoteRmCmdFlags(remoteRmCmd)
+	remoteCmd.AddCommand(remoteRmCmd)
+}
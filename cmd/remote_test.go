@@ -0,0 +1,48 @@
+package cmd
+
+import (
+	"testing"
+
+	"github.com/matryer/is"
+	"github.com/spf13/afero"
+	"github.com/sveltinio/sveltin/internal/ftpfs"
+)
+
+func TestRemoteGetAndRm(t *testing.T) {
+	is := is.New(t)
+	server := setupDeployProject(t, "build", map[string]string{
+		".htaccess":           "Options -Indexes",
+		"index.html":          "<h1>home</h1>",
+		"posts/first.html":    "<h1>first</h1>",
+		"posts/drafts/2.html": "<h1>second</h1>",
+	})
+	manifest := ftpfs.NewManifest()
+	manifest.Files["index.html"] = ftpfs.ManifestEntry{Size: 13}
+	manifest.Files["posts/first.html"] = ftpfs.ManifestEntry{Size: 14}
+	content, err := manifest.Bytes()
+	is.NoErr(err)
+	is.NoErr(afero.WriteFile(server.Fs, "/www/"+ftpfs.ManifestFilename, content, 0644))
+
+	withOutput = ""
+	RunRemoteGetCmd(remoteGetCmd, []string{"/posts/"})
+	downloaded, err := afero.ReadFile(cfg.fs, "posts/drafts/2.html")
+	is.NoErr(err)
+	is.Equal(string(downloaded), "<h1>second</h1>")
+
+	withOutput = "htaccess.txt"
+	RunRemoteGetCmd(remoteGetCmd, []string{".htaccess"})
+	downloaded, err = afero.ReadFile(cfg.fs, "htaccess.txt")
+	is.NoErr(err)
+	is.Equal(string(downloaded), "Options -Indexes")
+
+	isRecursive = false
+	RunRemoteLsCmd(remoteLsCmd, []string{"posts"})
+	RunRemoteDuCmd(remoteDuCmd, []string{})
+
+	RunRemoteRmCmd(remoteRmCmd, []string{"posts", "../index.html"})
+	is.Equal(remoteFiles(t, server), []string{".htaccess", ftpfs.ManifestFilename})
+	// the next incremental deploy uploads the deleted files again
+	remoteManifest, err := ftpfs.FetchManifest(server)
+	is.NoErr(err)
+	is.Equal(len(remoteManifest.Files), 0)
+}
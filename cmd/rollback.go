@@ -0,0 +1,135 @@
+/**
+ * Copyright © 2021-present Sveltin contributors <github@sveltin.io>
+ *
+ * Use of this source code is governed by Apache 2.0 license
+ * that can be found in the LICENSE file.
+ */
+
+package cmd
+
+import (
+	"fmt"
+	"path/filepath"
+	"strings"
+
+	"github.com/spf13/afero"
+	"github.com/spf13/cobra"
+	"github.com/sveltinio/prompti/confirm"
+	sveltinerr "github.com/sveltinio/sveltin/internal/errors"
+	"github.com/sveltinio/sveltin/internal/ftpfs"
+	"github.com/sveltinio/sveltin/internal/markup"
+	"github.com/sveltinio/sveltin/tui/activehelps"
+	"github.com/sveltinio/sveltin/tui/feedbacks"
+	"github.com/sveltinio/sveltin/tui/prompts"
+	"github.com/sveltinio/sveltin/utils"
+)
+
+var (
+	isLatestBackup bool
+)
+
+var rollbackCmd = &cobra.Command{
+	Use:   "rollback",
+	Short: "Restore the remote folder from a deploy backup",
+	Long: `Command used to restore the content of the remote folder from one of the backup archives
+created by the deploy command in the backups folder.
+
+The existing content is deleted (except what set with --exclude) and replaced by the archive content.
+Use --latest --yes to restore the most recent backup without prompting, e.g. when running in CI.
+`,
+	DisableFlagsInUseLine: true,
+	Args:                  cobra.ExactArgs(0),
+	Run:                   RollbackCmdRun,
+	ValidArgsFunction: func(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+		var comps []string
+		comps = cobra.AppendActiveHelp(comps, activehelps.Hint("[WARN] This command does not take any argument but accepts flags."))
+		return comps, cobra.ShellCompDirectiveDefault
+	},
+}
+
+// RollbackCmdRun is the actual work function.
+func RollbackCmdRun(cmd *cobra.Command, args []string) {
+	// Exit if running sveltin commands either from a not valid directory or not latest sveltin version.
+	isValidProject(true)
+
+	setupNonInteractiveMode()
+
+	cfg.log.Plain(markup.H1("Rollback your website from a backup"))
+
+	loadEnvironment(withEnv, true)
+	loadExcludeList()
+
+	backupsFolderPath := environmentBackupsFolder(withEnv)
+	pathToPkgFile := filepath.Join(cfg.pathMaker.GetRootFolder(), "package.json")
+	projectName, err := utils.RetrieveProjectName(cfg.fs, pathToPkgFile)
+	utils.ExitIfError(err)
+
+	archives, err := ftpfs.ListBackups(cfg.fs, backupsFolderPath, projectName)
+	utils.ExitIfError(err)
+	if len(archives) == 0 {
+		utils.ExitIfError(sveltinerr.NewBackupNotFoundError(backupsFolderPath))
+	}
+
+	archive, err := prompts.SelectBackupHandler(archives, isLatestBackup)
+	utils.ExitIfError(err)
+
+	// unpack the archive in memory, the files are uploaded from there
+	memFs := afero.NewMemMapFs()
+	cfg.log.Infof("Reading the backup archive: %s", archive.Path)
+	backupFiles, err := ftpfs.ExtractBackup(cfg.fs, archive.Path, memFs)
+	utils.ExitIfError(err)
+
+	remoteServer, noOpAction := connectRemoteServer()
+
+	feedbacks.ShowRollbackCommandWarningMessages(archive.Name)
+
+	if isDryRun {
+		feedbacks.ShowDryRunMessage()
+	}
+
+	isConfirm := isYes
+	if !isConfirm {
+		isConfirm, err = confirm.Run(&confirm.Config{Question: "Continue?"})
+		exitIfDeployError(err)
+	}
+
+	if isConfirm {
+		cfg.log.Important(fmt.Sprintf("The remote files and folders matching the following exclude rules will not be deleted: %s", strings.Join(withExclude, ", ")))
+		err = ftpfs.DeleteAllAction(remoteServer, withExclude, isDryRun).Run()
+		exitIfDeployError(err)
+
+		cfg.log.Info("Creating remote folders structure")
+		err = ftpfs.MakeDirsAction(remoteServer, ftpfs.MissingDirs(backupFiles, ftpfs.NewManifest()), isDryRun).Run()
+		exitIfDeployError(err)
+
+		// prevent the remote FTP server to close the idle connection
+		err = noOpAction.Run()
+		exitIfDeployError(err)
+
+		cfg.log.Infof("Uploading %d files to the remote folder", len(backupFiles))
+		err = ftpfs.UploadAction(remoteServer, memFs, "", backupFiles, false, isDryRun).Run()
+		exitIfDeployError(err)
+
+		// close the connection
+		err = ftpfs.LogoutAction(remoteServer).Run()
+		exitIfDeployError(err)
+
+		cfg.log.Success("Done\n")
+	}
+}
+
+func rollbackCmdFlags(cmd *cobra.Command) {
+	cmd.Flags().BoolVarP(&isLatestBackup, "latest", "l", false, "restore the most recent backup archive without prompting")
+	cmd.Flags().BoolVarP(&isDryRun, "dryRun", "d", false, "dry run")
+	cmd.Flags().BoolVarP(&isYes, "yes", "y", false, "do not prompt for confirmation and print plain progress lines")
+	cmd.Flags().StringArrayVarP(&withExclude, "exclude", "e", []string{".htaccess"}, "gitignore-style pattern of files and folders to not be deleted from the remote server (repeatable). Default: .htaccess")
+	cmd.Flags().StringVar(&withExcludeFile, "withExcludeFile", "", "path to the file with the gitignore-style patterns of files and folders to not be deleted from the remote server. Default: .sveltinignore")
+	cmd.Flags().IntVarP(&withParallel, "parallel", "p", 1, "number of concurrent connections used to upload the files")
+	cmd.Flags().StringVarP(&withTarget, "target", "t", "", "target overriding DEPLOY_PROTOCOL, e.g. dir:/srv/www/site for a local or mounted folder")
+	cmd.Flags().StringVar(&withEnv, "env", DefaultEnvironment, "name of the environment to restore, loaded from the .env.<name> file")
+}
+
+func init() {
+	rollbackCmdFlags(rollbackCmd)
+	rootCmd.AddCommand(rollbackCmd)
+}
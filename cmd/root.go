@@ -66,6 +66,7 @@ const (
 	LibFolder     string = "lib"
 	StaticFolder  string = "static"
 	ThemesFolder  string = "themes"
+	StateFolder   string = ".sveltin"
 )
 
 // File IDs for a Sveltin project structure.
@@ -242,6 +243,6 @@ func isValidProject(checkIfLatestVersion bool) {
 // GetSveltinCommands returns an array of pointers to the implemented cobra.Command
 func GetSveltinCommands() []*cobra.Command {
 	return []*cobra.Command{
-		initCmd, newCmd, addCmd, generateCmd, installCmd, updateCmd, serverCmd, buildCmd, previewCmd, deployCmd, migrateCmd,
+		initCmd, newCmd, addCmd, generateCmd, installCmd, updateCmd, serverCmd, buildCmd, previewCmd, deployCmd, rollbackCmd, backupsCmd, remoteCmd, secretsCmd, migrateCmd,
 	}
 }
@@ -0,0 +1,127 @@
+/**
+ * Copyright © 2021-present Sveltin contributors <github@sveltin.io>
+ *
+ * Use of this source code is governed by Apache 2.0 license
+ * that can be found in the LICENSE file.
+ */
+
+package cmd
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"regexp"
+
+	"github.com/spf13/afero"
+	"github.com/spf13/cobra"
+	sveltinerr "github.com/sveltinio/sveltin/internal/errors"
+	"github.com/sveltinio/sveltin/internal/secrets"
+	"github.com/sveltinio/sveltin/internal/tpltypes"
+	"github.com/sveltinio/sveltin/resources"
+	"github.com/sveltinio/sveltin/tui/prompts"
+)
+
+// SecretsFile is the encrypted secrets store within the state folder.
+const SecretsFile string = "secrets.enc"
+
+// secretKeyRegExp matches the names allowed for a secret, the same as for an env variable.
+var secretKeyRegExp = regexp.MustCompile(`^[A-Z][A-Z0-9_]*$`)
+
+//=============================================================================
+
+var secretsCmd = &cobra.Command{
+	Use:   "secrets",
+	Short: "Manage the credentials in the encrypted secrets store",
+	Long: resources.GetASCIIArt() + `
+Command used to set and get the secrets (e.g. FTP_PASSWORD) in the local encrypted
+secrets store through its own subcommands.
+
+The store is saved as .sveltin/secrets.enc and unlocked by a passphrase, read from the
+SVELTIN_SECRETS_PASSPHRASE environment variable or asked for. The secrets are set per
+environment (see --env).
+
+When connecting to the remote server, FTP_USER and FTP_PASSWORD are looked up in order in
+the process environment, the ~/.netrc file, the secrets store and the .env.<name> file.
+
+Run 'sveltin secrets -h' for further details.
+`,
+	ValidArgs:             []string{"set", "get"},
+	Args:                  cobra.MatchAll(cobra.ExactArgs(1), cobra.OnlyValidArgs),
+	DisableFlagsInUseLine: true,
+}
+
+func init() {
+	rootCmd.AddCommand(secretsCmd)
+}
+
+//=============================================================================
+
+// secretsStorePath returns the path to the encrypted secrets store.
+func secretsStorePath() string {
+	return filepath.Join(cfg.pathMaker.GetRootFolder(), StateFolder, SecretsFile)
+}
+
+// secretsPassphrase returns the passphrase from the SVELTIN_SECRETS_PASSPHRASE environment variable
+// or asks for it. It cannot be asked for when running with --yes or --report.
+func secretsPassphrase(isNew bool) (string, error) {
+	if passphrase := os.Getenv(secrets.PassphraseEnv); passphrase != "" {
+		return passphrase, nil
+	}
+	if isYes || withReport != "" {
+		return "", sveltinerr.NewDefaultError(errors.New("set " + secrets.PassphraseEnv + " to unlock the secrets store in non-interactive mode"))
+	}
+	return prompts.AskPassphraseHandler(isNew)
+}
+
+// openSecretsStore asks for the passphrase and decrypts the secrets store.
+// It returns nil when the store does not exist.
+func openSecretsStore() (*secrets.Store, string, error) {
+	exists, err := afero.Exists(cfg.fs, secretsStorePath())
+	if err != nil || !exists {
+		return nil, "", err
+	}
+	passphrase, err := secretsPassphrase(false)
+	if err != nil {
+		return nil, "", err
+	}
+	store, err := secrets.OpenStore(cfg.fs, secretsStorePath(), passphrase)
+	return store, passphrase, err
+}
+
+// resolveCredentials looks up the user and password to log in the remote server in order in the
+// process environment, the netrc file, the secrets store of the environment and the env file.
+func resolveCredentials(data tpltypes.EnvProductionData) (*secrets.Credentials, error) {
+	envFileCredentials := secrets.Credentials{User: data.FTPUser, Password: data.FTPPassword}
+	if envFileCredentials.User == placeholderValue {
+		envFileCredentials.User = ""
+	}
+	if envFileCredentials.Password == placeholderValue {
+		envFileCredentials.Password = ""
+	}
+
+	sources := []secrets.Source{
+		&secrets.EnvSource{UserKey: "FTP_USER", PasswordKey: "FTP_PASSWORD", Getenv: os.Getenv},
+		&secrets.NetrcSource{Fs: afero.NewOsFs(), Path: secrets.DefaultNetrcPath()},
+		&secrets.StoreSource{
+			Environment: withEnv,
+			UserKey:     "FTP_USER",
+			PasswordKey: "FTP_PASSWORD",
+			Open: func() (*secrets.Store, error) {
+				store, _, err := openSecretsStore()
+				return store, err
+			},
+		},
+		&secrets.StaticSource{Description: envFileName(withEnv), Credentials: envFileCredentials},
+	}
+
+	credentials, source, err := secrets.Resolve(sources, data.FTPHost, envFileCredentials.User)
+	if err != nil {
+		return nil, err
+	}
+	if credentials == nil {
+		return &secrets.Credentials{User: envFileCredentials.User}, nil
+	}
+	cfg.log.Infof("Using the credentials from %s", source)
+	return credentials, nil
+}
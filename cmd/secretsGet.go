@@ -0,0 +1,55 @@
+/**
+ * Copyright © 2021-present Sveltin contributors <github@sveltin.io>
+ *
+ * Use of this source code is governed by Apache 2.0 license
+ * that can be found in the LICENSE file.
+ */
+
+package cmd
+
+import (
+	"fmt"
+
+	"github.com/spf13/cobra"
+	sveltinerr "github.com/sveltinio/sveltin/internal/errors"
+	"github.com/sveltinio/sveltin/utils"
+)
+
+var secretsGetCmd = &cobra.Command{
+	Use:   "get <key>",
+	Short: "Print a secret from the encrypted store",
+	Long: `Command used to print the value of a secret from the encrypted secrets store.
+
+Only the value is printed to stdout, e.g. to be used by scripts.
+`,
+	DisableFlagsInUseLine: true,
+	Args:                  cobra.ExactArgs(1),
+	Run:                   RunSecretsGetCmd,
+}
+
+// RunSecretsGetCmd is the actual work function.
+func RunSecretsGetCmd(cmd *cobra.Command, args []string) {
+	// Exit if running sveltin commands either from a not valid directory or not latest sveltin version.
+	isValidProject(true)
+
+	store, _, err := openSecretsStore()
+	utils.ExitIfError(err)
+	if store == nil {
+		utils.ExitIfError(sveltinerr.NewFileNotFoundError(secretsStorePath()))
+	}
+
+	value, exists := store.Get(withEnv, args[0])
+	if !exists {
+		utils.ExitIfError(sveltinerr.NewOptionNotValidError(args[0], store.Keys(withEnv)))
+	}
+	fmt.Println(value)
+}
+
+func secretsGetCmdFlags(cmd *cobra.Command) {
+	cmd.Flags().StringVar(&withEnv, "env", DefaultEnvironment, "name of the environment the secret belongs to")
+}
+
+func init() {
+	secretsGetCmdFlags(secretsGetCmd)
+	secretsCmd.AddCommand(secretsGetCmd)
+}
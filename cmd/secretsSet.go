@@ -0,0 +1,90 @@
+/**
+ * Copyright © 2021-present Sveltin contributors <github@sveltin.io>
+ *
+ * Use of this source code is governed by Apache 2.0 license
+ * that can be found in the LICENSE file.
+ */
+
+package cmd
+
+import (
+	"bufio"
+	"os"
+	"strings"
+
+	"github.com/spf13/cobra"
+	sveltinerr "github.com/sveltinio/sveltin/internal/errors"
+	"github.com/sveltinio/sveltin/internal/markup"
+	"github.com/sveltinio/sveltin/internal/secrets"
+	"github.com/sveltinio/sveltin/tui/prompts"
+	"github.com/sveltinio/sveltin/utils"
+)
+
+var (
+	isFromStdin bool
+)
+
+var secretsSetCmd = &cobra.Command{
+	Use:   "set <key>",
+	Short: "Add or replace a secret in the encrypted store",
+	Long: `Command used to add or replace a secret, e.g. FTP_PASSWORD, in the encrypted secrets store.
+
+The value is asked for, or read from the standard input with --stdin.
+The store is created on the first run, asking for the passphrase twice.
+`,
+	DisableFlagsInUseLine: true,
+	Args:                  cobra.ExactArgs(1),
+	Run:                   RunSecretsSetCmd,
+}
+
+// RunSecretsSetCmd is the actual work function.
+func RunSecretsSetCmd(cmd *cobra.Command, args []string) {
+	// Exit if running sveltin commands either from a not valid directory or not latest sveltin version.
+	isValidProject(true)
+
+	cfg.log.Plain(markup.H1("Setting a secret"))
+
+	key := args[0]
+	if !secretKeyRegExp.MatchString(key) {
+		utils.ExitIfError(sveltinerr.NewOptionNotValidError(key, []string{"uppercase letters, digits and '_'"}))
+	}
+
+	store, passphrase, err := openSecretsStore()
+	utils.ExitIfError(err)
+	if store == nil {
+		cfg.log.Infof("Creating the secrets store %s", secretsStorePath())
+		store = secrets.NewStore()
+		passphrase, err = secretsPassphrase(true)
+		utils.ExitIfError(err)
+	}
+
+	value, err := readSecretValue(key)
+	utils.ExitIfError(err)
+
+	store.Set(withEnv, key, value)
+	utils.ExitIfError(store.Save(cfg.fs, secretsStorePath(), passphrase))
+
+	cfg.log.Successf("Done! %s set for the '%s' environment\n", key, withEnv)
+}
+
+// readSecretValue asks for the secret value or reads the first line from the standard input with --stdin.
+func readSecretValue(key string) (string, error) {
+	if !isFromStdin {
+		return prompts.AskSecretValueHandler(key)
+	}
+	value, err := bufio.NewReader(os.Stdin).ReadString('\n')
+	if err != nil && value == "" {
+		return "", err
+	}
+	return strings.TrimRight(value, "\r\n"), nil
+}
+
+func secretsSetCmdFlags(cmd *cobra.Command) {
+	cmd.Flags().StringVar(&withEnv, "env", DefaultEnvironment, "name of the environment the secret belongs to")
+	cmd.Flags().BoolVar(&isFromStdin, "stdin", false, "read the value from the standard input")
+}
+
+func init() {
+	secretsSetCmdFlags(secretsSetCmd)
+	secretsCmd.AddCommand(secretsSetCmd)
+}
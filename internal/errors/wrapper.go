@@ -51,6 +51,11 @@ const (
 	execSystemCommandError
 	execSystemCommandErrorWithMsg
 	shellCompletionError
+	backupNotFoundError
+	notValidEnvironmentError
+	deployDriftError
+	hookFailedError
+	credentialsNotFoundError
 )
 
 var (
@@ -344,6 +349,36 @@ func NewShellCompletionError() error {
 	return newSveltinError(shellCompletionError, "CompletionShellError", "Invalid shell name", err.Error(), err)
 }
 
+// NewBackupNotFoundError ...
+func NewBackupNotFoundError(backupsFolder string) error {
+	err := fmt.Errorf("no backup archives found for the project. Please, check the backups folder: %s", backupsFolder)
+	return newSveltinError(backupNotFoundError, "BackupNotFoundError", "Backup Not Found", err.Error(), err)
+}
+
+// NewNotValidEnvironmentError ...
+func NewNotValidEnvironmentError(name, pathToFile string, fields []string) error {
+	err := fmt.Errorf("the '%s' environment is not valid. Please, set the following values in %s:\n\n%s", name, pathToFile, strings.Join(fields, "\n"))
+	return newSveltinError(notValidEnvironmentError, "NotValidEnvironmentError", "Environment Not Valid", err.Error(), err)
+}
+
+// NewDeployDriftError ...
+func NewDeployDriftError(missing, extra, mismatched int) error {
+	err := fmt.Errorf("the remote folder does not match the local build: %d missing, %d extra and %d mismatched files", missing, extra, mismatched)
+	return newSveltinError(deployDriftError, "DeployDriftError", "Deploy Verification Failed", err.Error(), err)
+}
+
+// NewHookFailedError ...
+func NewHookFailedError(hook, command string, errN error) error {
+	err := fmt.Errorf("the %s hook failed running '%s': %w", hook, command, errN)
+	return newSveltinError(hookFailedError, "HookFailedError", "Hook Failure", err.Error(), err)
+}
+
+// NewCredentialsNotFoundError ...
+func NewCredentialsNotFoundError(host string) error {
+	err := fmt.Errorf("no credentials found for %s. Please, set FTP_USER and FTP_PASSWORD in the process environment, in ~/.netrc, with 'sveltin secrets set' or in the env file", host)
+	return newSveltinError(credentialsNotFoundError, "CredentialsNotFoundError", "Credentials Not Found", err.Error(), err)
+}
+
 //=============================================================================
 
 func messageTag(tag string) string {
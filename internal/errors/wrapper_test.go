@@ -75,4 +75,24 @@ func TestErrors(t *testing.T) {
 	errVar = NewExecSystemCommandError("git", "init")
 	re = errVar.(*SveltinError)
 	is.Equal("ExecSystemCommandError", re.Name)
+
+	errVar = NewBackupNotFoundError("backups")
+	re = errVar.(*SveltinError)
+	is.Equal("BackupNotFoundError", re.Name)
+
+	errVar = NewNotValidEnvironmentError("staging", ".env.staging", []string{"FTP_HOST"})
+	re = errVar.(*SveltinError)
+	is.Equal("NotValidEnvironmentError", re.Name)
+
+	errVar = NewDeployDriftError(1, 0, 2)
+	re = errVar.(*SveltinError)
+	is.Equal("DeployDriftError", re.Name)
+
+	errVar = NewHookFailedError("pre-build", "exit 1", errors.New("exit status 1"))
+	re = errVar.(*SveltinError)
+	is.Equal("HookFailedError", re.Name)
+
+	errVar = NewCredentialsNotFoundError("ftp.example.com")
+	re = errVar.(*SveltinError)
+	is.Equal("CredentialsNotFoundError", re.Name)
 }
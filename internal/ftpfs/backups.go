@@ -0,0 +1,214 @@
+/**
+ * Copyright © 2021-present Sveltin contributors <github@sveltin.io>
+ *
+ * Use of this source code is governed by Apache 2.0 license
+ * that can be found in the LICENSE file.
+ */
+
+package ftpfs
+
+import (
+	"archive/tar"
+	"compress/gzip"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/spf13/afero"
+)
+
+// BackupArchiveExt is the extension for the backup archives created by DoBackup.
+const BackupArchiveExt string = ".tar.gz"
+
+// BackupArchive is the struct representing a backup archive saved in the local backups folder.
+type BackupArchive struct {
+	Name    string
+	Path    string
+	Size    int64
+	ModTime time.Time
+}
+
+// ListBackups returns the backup archives for the project, newest first.
+func ListBackups(appFs afero.Fs, backupsFolder, projectName string) ([]BackupArchive, error) {
+	archives := []BackupArchive{}
+	exists, err := afero.DirExists(appFs, backupsFolder)
+	if err != nil || !exists {
+		return archives, err
+	}
+
+	entries, err := afero.ReadDir(appFs, backupsFolder)
+	if err != nil {
+		return nil, err
+	}
+	for _, entry := range entries {
+		if entry.IsDir() || !isProjectBackup(entry.Name(), projectName) {
+			continue
+		}
+		archives = append(archives, BackupArchive{
+			Name:    entry.Name(),
+			Path:    filepath.Join(backupsFolder, entry.Name()),
+			Size:    entry.Size(),
+			ModTime: entry.ModTime(),
+		})
+	}
+
+	sort.SliceStable(archives, func(i, j int) bool {
+		return archives[i].ModTime.After(archives[j].ModTime)
+	})
+	return archives, nil
+}
+
+// BackupEntry is the struct representing a file within a backup archive.
+type BackupEntry struct {
+	Name    string
+	Size    int64
+	ModTime time.Time
+}
+
+// ExtractBackup unpacks the backup archive into destFs and returns the sorted list of the extracted files.
+func ExtractBackup(appFs afero.Fs, archivePath string, destFs afero.Fs) ([]string, error) {
+	files := []string{}
+	err := readBackupArchive(appFs, archivePath, func(name string, header *tar.Header, content io.Reader) error {
+		if err := destFs.MkdirAll(filepath.Dir(name), os.ModePerm); err != nil {
+			return err
+		}
+		out, err := destFs.Create(name)
+		if err != nil {
+			return err
+		}
+		defer out.Close()
+		if _, err := io.Copy(out, content); err != nil {
+			return fmt.Errorf("could not extract the file '%s', got error '%s'", name, err.Error())
+		}
+		files = append(files, name)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	sort.Strings(files)
+	return files, nil
+}
+
+// InspectBackup returns the files within the backup archive, sorted by name.
+func InspectBackup(appFs afero.Fs, archivePath string) ([]BackupEntry, error) {
+	entries := []BackupEntry{}
+	err := readBackupArchive(appFs, archivePath, func(name string, header *tar.Header, content io.Reader) error {
+		entries = append(entries, BackupEntry{Name: name, Size: header.Size, ModTime: header.ModTime})
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	sort.SliceStable(entries, func(i, j int) bool {
+		return entries[i].Name < entries[j].Name
+	})
+	return entries, nil
+}
+
+// BackupsToPrune returns the archives exceeding the retention policy: the ones after the
+// first keep archives and the ones older than maxAge. A zero value disables the rule.
+// The archives are expected newest first, as returned by ListBackups.
+func BackupsToPrune(archives []BackupArchive, keep int, maxAge time.Duration, now time.Time) []BackupArchive {
+	toPrune := []BackupArchive{}
+	for i, archive := range archives {
+		if (keep > 0 && i >= keep) || (maxAge > 0 && now.Sub(archive.ModTime) > maxAge) {
+			toPrune = append(toPrune, archive)
+		}
+	}
+	return toPrune
+}
+
+// DeleteBackups removes the archives from the local backups folder.
+func DeleteBackups(appFs afero.Fs, archives []BackupArchive) error {
+	for _, archive := range archives {
+		if err := appFs.Remove(archive.Path); err != nil {
+			return fmt.Errorf("could not delete the backup archive '%s', got error '%s'", archive.Path, err.Error())
+		}
+	}
+	return nil
+}
+
+// ParseMaxAge parses the max age for the backups, either as a number of days (e.g. 30d)
+// or as a duration (e.g. 12h). An empty string means no limit.
+func ParseMaxAge(value string) (time.Duration, error) {
+	value = strings.TrimSpace(value)
+	if value == "" {
+		return 0, nil
+	}
+	if strings.HasSuffix(value, "d") {
+		n, err := strconv.Atoi(strings.TrimSuffix(value, "d"))
+		if err != nil || n < 0 {
+			return 0, fmt.Errorf("not valid max age '%s', use a number of days (e.g. 30d) or a duration (e.g. 12h)", value)
+		}
+		return time.Duration(n) * 24 * time.Hour, nil
+	}
+	maxAge, err := time.ParseDuration(value)
+	if err != nil || maxAge < 0 {
+		return 0, fmt.Errorf("not valid max age '%s', use a number of days (e.g. 30d) or a duration (e.g. 12h)", value)
+	}
+	return maxAge, nil
+}
+
+//=============================================================================
+
+// isProjectBackup checks the archive name matches the one used by DoBackup: <projectName>_<timestamp>.tar.gz
+func isProjectBackup(name, projectName string) bool {
+	return strings.HasPrefix(name, projectName+"_") && strings.HasSuffix(name, BackupArchiveExt)
+}
+
+// readBackupArchive calls fn for every regular file within the backup archive
+// with the sanitized entry name, the tar header and the file content.
+func readBackupArchive(appFs afero.Fs, archivePath string, fn func(name string, header *tar.Header, content io.Reader) error) error {
+	file, err := appFs.Open(archivePath)
+	if err != nil {
+		return fmt.Errorf("could not open the backup archive '%s', got error '%s'", archivePath, err.Error())
+	}
+	defer file.Close()
+
+	gzipReader, err := gzip.NewReader(file)
+	if err != nil {
+		return fmt.Errorf("could not read the backup archive '%s', got error '%s'", archivePath, err.Error())
+	}
+	defer gzipReader.Close()
+
+	tarReader := tar.NewReader(gzipReader)
+	for {
+		header, err := tarReader.Next()
+		if errors.Is(err, io.EOF) {
+			return nil
+		}
+		if err != nil {
+			return fmt.Errorf("could not read the backup archive '%s', got error '%s'", archivePath, err.Error())
+		}
+		if header.Typeflag != tar.TypeReg && header.Typeflag != tar.TypeRegA {
+			continue
+		}
+
+		name, err := sanitizeArchiveEntry(header.Name)
+		if err != nil {
+			return err
+		}
+		if err := fn(name, header, tarReader); err != nil {
+			return err
+		}
+	}
+}
+
+// sanitizeArchiveEntry cleans the entry name and rejects the ones pointing outside the remote folder.
+func sanitizeArchiveEntry(name string) (string, error) {
+	cleaned := path.Clean(strings.TrimPrefix(filepath.ToSlash(name), "/"))
+	if cleaned == "." || cleaned == ".." || strings.HasPrefix(cleaned, "../") {
+		return "", fmt.Errorf("not valid entry '%s' in the backup archive", name)
+	}
+	return cleaned, nil
+}
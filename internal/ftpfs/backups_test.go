@@ -0,0 +1,132 @@
+package ftpfs
+
+import (
+	"archive/tar"
+	"compress/gzip"
+	"testing"
+	"time"
+
+	"github.com/matryer/is"
+	"github.com/spf13/afero"
+)
+
+func newTestBackupArchive(t *testing.T, appFs afero.Fs, archivePath string, files map[string]string) {
+	t.Helper()
+	is := is.New(t)
+
+	memFs := afero.NewMemMapFs()
+	file, err := appFs.Create(archivePath)
+	is.NoErr(err)
+	gzipWriter := gzip.NewWriter(file)
+	tarWriter := tar.NewWriter(gzipWriter)
+	for name, content := range files {
+		is.NoErr(afero.WriteFile(memFs, name, []byte(content), 0644))
+		is.NoErr(addToTarWriter(memFs, name, tarWriter))
+	}
+	is.NoErr(tarWriter.Close())
+	is.NoErr(gzipWriter.Close())
+	is.NoErr(file.Close())
+}
+
+func TestListBackups(t *testing.T) {
+	is := is.New(t)
+	appFs := afero.NewMemMapFs()
+
+	archives, err := ListBackups(appFs, "backups", "mysite")
+	is.NoErr(err)
+	is.Equal(len(archives), 0)
+
+	newTestBackupArchive(t, appFs, "backups/mysite_20230101_1:0:0PM.tar.gz", map[string]string{"index.html": "v1"})
+	newTestBackupArchive(t, appFs, "backups/mysite_20230102_1:0:0PM.tar.gz", map[string]string{"index.html": "v2"})
+	newTestBackupArchive(t, appFs, "backups/othersite_20230103_1:0:0PM.tar.gz", map[string]string{"index.html": "v3"})
+	is.NoErr(afero.WriteFile(appFs, "backups/mysite_notes.txt", []byte(""), 0644))
+
+	now := time.Now()
+	is.NoErr(appFs.Chtimes("backups/mysite_20230101_1:0:0PM.tar.gz", now, now.Add(-time.Hour)))
+	is.NoErr(appFs.Chtimes("backups/mysite_20230102_1:0:0PM.tar.gz", now, now))
+
+	archives, err = ListBackups(appFs, "backups", "mysite")
+	is.NoErr(err)
+	is.Equal(len(archives), 2)
+	is.Equal(archives[0].Name, "mysite_20230102_1:0:0PM.tar.gz")
+	is.Equal(archives[1].Path, "backups/mysite_20230101_1:0:0PM.tar.gz")
+}
+
+func TestExtractBackup(t *testing.T) {
+	is := is.New(t)
+	appFs := afero.NewMemMapFs()
+
+	newTestBackupArchive(t, appFs, "backups/mysite_20230101_1:0:0PM.tar.gz", map[string]string{
+		"index.html":             "<h1>home</h1>",
+		"posts/first/index.html": "<h1>first</h1>",
+		".htaccess":              "Options -Indexes",
+	})
+
+	destFs := afero.NewMemMapFs()
+	files, err := ExtractBackup(appFs, "backups/mysite_20230101_1:0:0PM.tar.gz", destFs)
+	is.NoErr(err)
+	is.Equal(files, []string{".htaccess", "index.html", "posts/first/index.html"})
+
+	content, err := afero.ReadFile(destFs, "posts/first/index.html")
+	is.NoErr(err)
+	is.Equal(string(content), "<h1>first</h1>")
+
+	_, err = ExtractBackup(appFs, "backups/missing.tar.gz", destFs)
+	is.True(err != nil)
+}
+
+func TestInspectBackup(t *testing.T) {
+	is := is.New(t)
+	appFs := afero.NewMemMapFs()
+	newTestBackupArchive(t, appFs, "backups/mysite_20230101_1:0:0PM.tar.gz", map[string]string{
+		"index.html":       "<h1>home</h1>",
+		"about/index.html": "<h1>about</h1>",
+	})
+
+	entries, err := InspectBackup(appFs, "backups/mysite_20230101_1:0:0PM.tar.gz")
+	is.NoErr(err)
+	is.Equal(len(entries), 2)
+	is.Equal(entries[0].Name, "about/index.html")
+	is.Equal(entries[0].Size, int64(len("<h1>about</h1>")))
+	is.Equal(entries[1].Name, "index.html")
+}
+
+func TestBackupsToPrune(t *testing.T) {
+	is := is.New(t)
+	now := time.Now()
+	archives := []BackupArchive{
+		{Name: "a", ModTime: now.Add(-time.Hour)},
+		{Name: "b", ModTime: now.Add(-48 * time.Hour)},
+		{Name: "c", ModTime: now.Add(-72 * time.Hour)},
+	}
+
+	names := func(archives []BackupArchive) []string {
+		result := []string{}
+		for _, archive := range archives {
+			result = append(result, archive.Name)
+		}
+		return result
+	}
+	is.Equal(names(BackupsToPrune(archives, 0, 0, now)), []string{})
+	is.Equal(names(BackupsToPrune(archives, 1, 0, now)), []string{"b", "c"})
+	is.Equal(names(BackupsToPrune(archives, 0, 60*time.Hour, now)), []string{"c"})
+	is.Equal(names(BackupsToPrune(archives, 2, 24*time.Hour, now)), []string{"b", "c"})
+}
+
+func TestParseMaxAge(t *testing.T) {
+	is := is.New(t)
+
+	maxAge, err := ParseMaxAge("")
+	is.NoErr(err)
+	is.Equal(maxAge, time.Duration(0))
+	maxAge, err = ParseMaxAge("30d")
+	is.NoErr(err)
+	is.Equal(maxAge, 30*24*time.Hour)
+	maxAge, err = ParseMaxAge("12h")
+	is.NoErr(err)
+	is.Equal(maxAge, 12*time.Hour)
+	_, err = ParseMaxAge("a week")
+	is.True(err != nil)
+	_, err = ParseMaxAge("-1d")
+	is.True(err != nil)
+}
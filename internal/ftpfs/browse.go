@@ -0,0 +1,78 @@
+/**
+ * Copyright © 2021-present Sveltin contributors <github@sveltin.io>
+ *
+ * Use of this source code is governed by Apache 2.0 license
+ * that can be found in the LICENSE file.
+ */
+
+package ftpfs
+
+import (
+	"path"
+	"sort"
+	"strings"
+	"time"
+)
+
+// RemoteDirEntry is an entry of a remote folder: a file or a subfolder, with the number
+// of files within it, their total size and the latest modification time.
+type RemoteDirEntry struct {
+	Name    string    `json:"name"`
+	IsDir   bool      `json:"isDir"`
+	Files   int       `json:"files"`
+	Size    int64     `json:"size"`
+	ModTime time.Time `json:"modTime"`
+}
+
+// CleanRemotePath returns the path relative to the remote folder, the empty string being the
+// remote folder itself. Paths pointing outside the remote folder (e.g. ../) are not allowed.
+func CleanRemotePath(name string) string {
+	return strings.TrimPrefix(path.Clean("/"+name), "/")
+}
+
+// FilesWithin returns the files within the folder (the empty string for the remote folder),
+// or the file itself when name is the path to a file.
+func FilesWithin(files []RemoteFile, name string) []RemoteFile {
+	name = CleanRemotePath(name)
+	within := []RemoteFile{}
+	for _, file := range files {
+		if name == "" || file.Path == name || strings.HasPrefix(file.Path, name+"/") {
+			within = append(within, file)
+		}
+	}
+	return within
+}
+
+// ReadRemoteDir groups the files within the folder by its entries, sorted by name.
+func ReadRemoteDir(files []RemoteFile, dir string) []RemoteDirEntry {
+	dir = CleanRemotePath(dir)
+	entries := map[string]*RemoteDirEntry{}
+	for _, file := range FilesWithin(files, dir) {
+		relPath := strings.TrimPrefix(strings.TrimPrefix(file.Path, dir), "/")
+		parts := strings.SplitN(relPath, "/", 2)
+		name, isDir := parts[0], len(parts) == 2
+		if relPath == "" {
+			// dir is the path to a file
+			name = path.Base(file.Path)
+		}
+		entry, exists := entries[name]
+		if !exists {
+			entry = &RemoteDirEntry{Name: name, IsDir: isDir}
+			entries[name] = entry
+		}
+		entry.Files++
+		entry.Size += file.Size
+		if file.ModTime.After(entry.ModTime) {
+			entry.ModTime = file.ModTime
+		}
+	}
+
+	sorted := make([]RemoteDirEntry, 0, len(entries))
+	for _, entry := range entries {
+		sorted = append(sorted, *entry)
+	}
+	sort.Slice(sorted, func(i, j int) bool {
+		return sorted[i].Name < sorted[j].Name
+	})
+	return sorted
+}
@@ -0,0 +1,42 @@
+package ftpfs
+
+import (
+	"testing"
+	"time"
+
+	"github.com/matryer/is"
+)
+
+func TestReadRemoteDir(t *testing.T) {
+	is := is.New(t)
+	older := time.Date(2023, 1, 1, 0, 0, 0, 0, time.UTC)
+	newer := older.Add(time.Hour)
+	files := []RemoteFile{
+		{Path: ".htaccess", Size: 5, ModTime: older},
+		{Path: "index.html", Size: 10, ModTime: newer},
+		{Path: "posts/first/index.html", Size: 20, ModTime: older},
+		{Path: "posts/second/index.html", Size: 30, ModTime: newer},
+		{Path: "postscript.txt", Size: 1, ModTime: older},
+	}
+
+	is.Equal(CleanRemotePath("/posts/../../etc/passwd"), "etc/passwd")
+	is.Equal(CleanRemotePath("../"), "")
+	is.Equal(len(FilesWithin(files, "")), 5)
+	is.Equal(len(FilesWithin(files, "/posts/")), 2)
+	is.Equal(FilesWithin(files, "index.html"), []RemoteFile{files[1]})
+
+	is.Equal(ReadRemoteDir(files, ""), []RemoteDirEntry{
+		{Name: ".htaccess", Files: 1, Size: 5, ModTime: older},
+		{Name: "index.html", Files: 1, Size: 10, ModTime: newer},
+		{Name: "posts", IsDir: true, Files: 2, Size: 50, ModTime: newer},
+		{Name: "postscript.txt", Files: 1, Size: 1, ModTime: older},
+	})
+	is.Equal(ReadRemoteDir(files, "posts"), []RemoteDirEntry{
+		{Name: "first", IsDir: true, Files: 1, Size: 20, ModTime: older},
+		{Name: "second", IsDir: true, Files: 1, Size: 30, ModTime: newer},
+	})
+	is.Equal(ReadRemoteDir(files, "posts/first/index.html"), []RemoteDirEntry{
+		{Name: "index.html", Files: 1, Size: 20, ModTime: older},
+	})
+	is.Equal(len(ReadRemoteDir(files, "missing")), 0)
+}
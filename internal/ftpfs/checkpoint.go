@@ -0,0 +1,144 @@
+/**
+ * Copyright © 2021-present Sveltin contributors <github@sveltin.io>
+ *
+ * Use of this source code is governed by Apache 2.0 license
+ * that can be found in the LICENSE file.
+ */
+
+package ftpfs
+
+import (
+	"bufio"
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"github.com/spf13/afero"
+)
+
+// CheckpointHeader identifies the deploy a checkpoint belongs to.
+type CheckpointHeader struct {
+	Environment   string    `json:"environment"`
+	RemoteFolder  string    `json:"remoteFolder"`
+	BuildChecksum string    `json:"buildChecksum"`
+	StartedAt     time.Time `json:"startedAt"`
+}
+
+type checkpointEntry struct {
+	Operation string `json:"op"`
+	Item      string `json:"item"`
+}
+
+// Checkpoint is the local log of the folders created and the files uploaded by a deploy,
+// used to resume it after a failure. The file has the header on the first line and then
+// one JSON line per completed item, appended as soon as the item is done.
+type Checkpoint struct {
+	Header CheckpointHeader
+	fs     afero.Fs
+	path   string
+	done   map[checkpointEntry]bool
+	mu     sync.Mutex
+}
+
+// NewCheckpoint creates the checkpoint file, replacing an existing one.
+func NewCheckpoint(fs afero.Fs, path string, header CheckpointHeader) (*Checkpoint, error) {
+	if err := fs.MkdirAll(filepath.Dir(path), os.ModePerm); err != nil {
+		return nil, err
+	}
+	line, err := json.Marshal(header)
+	if err != nil {
+		return nil, err
+	}
+	if err := afero.WriteFile(fs, path, append(line, '\n'), 0644); err != nil {
+		return nil, err
+	}
+	return &Checkpoint{
+		Header: header,
+		fs:     fs,
+		path:   path,
+		done:   map[checkpointEntry]bool{},
+	}, nil
+}
+
+// LoadCheckpoint reads the checkpoint file. A truncated last line, e.g. when
+// the process has been killed while writing it, is ignored.
+func LoadCheckpoint(fs afero.Fs, path string) (*Checkpoint, error) {
+	file, err := fs.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	scanner := bufio.NewScanner(file)
+	if !scanner.Scan() {
+		return nil, errors.New("the checkpoint file is empty")
+	}
+	c := &Checkpoint{fs: fs, path: path, done: map[checkpointEntry]bool{}}
+	if err := json.Unmarshal(scanner.Bytes(), &c.Header); err != nil {
+		return nil, err
+	}
+	for scanner.Scan() {
+		entry := checkpointEntry{}
+		if err := json.Unmarshal(scanner.Bytes(), &entry); err == nil {
+			c.done[entry] = true
+		}
+	}
+	return c, scanner.Err()
+}
+
+// Matches returns true when the checkpoint has been created for the same environment,
+// remote folder and build.
+func (c *Checkpoint) Matches(header CheckpointHeader) bool {
+	return c.Header.Environment == header.Environment &&
+		c.Header.RemoteFolder == header.RemoteFolder &&
+		c.Header.BuildChecksum == header.BuildChecksum
+}
+
+// PendingFolders returns the folders not created yet.
+func (c *Checkpoint) PendingFolders(folders []string) []string {
+	return c.pending(opMakeDirs, folders)
+}
+
+// PendingFiles returns the files not uploaded yet.
+func (c *Checkpoint) PendingFiles(files []string) []string {
+	return c.pending(opUpload, files)
+}
+
+func (c *Checkpoint) pending(op operation, items []string) []string {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	pending := []string{}
+	for _, item := range items {
+		if !c.done[checkpointEntry{Operation: string(op), Item: item}] {
+			pending = append(pending, item)
+		}
+	}
+	return pending
+}
+
+// add appends the completed item to the checkpoint file.
+func (c *Checkpoint) add(op operation, item string) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	entry := checkpointEntry{Operation: string(op), Item: item}
+	if c.done[entry] {
+		return nil
+	}
+	line, err := json.Marshal(entry)
+	if err != nil {
+		return err
+	}
+	file, err := c.fs.OpenFile(c.path, os.O_APPEND|os.O_WRONLY, 0644)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+	if _, err := file.Write(append(line, '\n')); err != nil {
+		return err
+	}
+	c.done[entry] = true
+	return nil
+}
@@ -0,0 +1,43 @@
+package ftpfs
+
+import (
+	"errors"
+	"os"
+	"testing"
+
+	"github.com/matryer/is"
+	"github.com/spf13/afero"
+)
+
+func TestCheckpoint(t *testing.T) {
+	is := is.New(t)
+	fs := afero.NewMemMapFs()
+	header := CheckpointHeader{Environment: "production", RemoteFolder: "/www", BuildChecksum: "abc"}
+
+	checkpoint, err := NewCheckpoint(fs, ".sveltin/deploy-production.checkpoint", header)
+	is.NoErr(err)
+	SetCheckpoint(checkpoint)
+	t.Cleanup(func() { SetCheckpoint(nil) })
+
+	trackItem(opMakeDirs, "posts", nil)
+	trackItem(opUpload, "build/index.html", nil)
+	trackItem(opUpload, "build/posts/index.html", errors.New("426 transfer aborted"))
+	trackItem(opDelete, "old.html", nil)
+
+	// a line truncated by a crash is ignored
+	file, err := fs.OpenFile(".sveltin/deploy-production.checkpoint", os.O_APPEND|os.O_WRONLY, 0644)
+	is.NoErr(err)
+	_, err = file.WriteString(`{"op":"upload","it`)
+	is.NoErr(err)
+	is.NoErr(file.Close())
+
+	loaded, err := LoadCheckpoint(fs, ".sveltin/deploy-production.checkpoint")
+	is.NoErr(err)
+	is.True(loaded.Matches(header))
+	is.True(!loaded.Matches(CheckpointHeader{Environment: "production", RemoteFolder: "/www", BuildChecksum: "def"}))
+	is.Equal(loaded.PendingFolders([]string{"posts", "tags"}), []string{"tags"})
+	is.Equal(loaded.PendingFiles([]string{"build/index.html", "build/posts/index.html"}), []string{"build/posts/index.html"})
+
+	_, err = LoadCheckpoint(fs, ".sveltin/missing.checkpoint")
+	is.True(err != nil)
+}
@@ -22,7 +22,7 @@ func (c *Client) Run() error {
 }
 
 // DialAction creates and configures the concrete dial command.
-func DialAction(conn *FTPServerConnection) *Client {
+func DialAction(conn RemoteServer) *Client {
 	return &Client{
 		Command: &DialCommand{
 			Server: conn,
@@ -31,7 +31,7 @@ func DialAction(conn *FTPServerConnection) *Client {
 }
 
 // LoginAction creates and configures the concrete login command.
-func LoginAction(conn *FTPServerConnection) *Client {
+func LoginAction(conn RemoteServer) *Client {
 	return &Client{
 		Command: &LoginCommand{
 			Server: conn,
@@ -40,7 +40,7 @@ func LoginAction(conn *FTPServerConnection) *Client {
 }
 
 // LogoutAction creates and configures the concrete logout command.
-func LogoutAction(conn *FTPServerConnection) *Client {
+func LogoutAction(conn RemoteServer) *Client {
 	return &Client{
 		Command: &LogoutCommand{
 			Server: conn,
@@ -49,7 +49,7 @@ func LogoutAction(conn *FTPServerConnection) *Client {
 }
 
 // IdleAction creates and configures the concrete no-operation(idle) command.
-func IdleAction(conn *FTPServerConnection) *Client {
+func IdleAction(conn RemoteServer) *Client {
 	return &Client{
 		Command: &IdleCommand{
 			Server: conn,
@@ -58,7 +58,7 @@ func IdleAction(conn *FTPServerConnection) *Client {
 }
 
 // MakeDirsAction creates and configures the concrete dial command.
-func MakeDirsAction(conn *FTPServerConnection, dirs []string, dryRun bool) *Client {
+func MakeDirsAction(conn RemoteServer, dirs []string, dryRun bool) *Client {
 	return &Client{
 		Command: &MakeDirsCommand{
 			Server: conn,
@@ -69,7 +69,7 @@ func MakeDirsAction(conn *FTPServerConnection, dirs []string, dryRun bool) *Clie
 }
 
 // UploadAction creates and configures the concrete upload command.
-func UploadAction(conn *FTPServerConnection, appFs afero.Fs, localDirname string, files []string, replaceBasePath, dryRun bool) *Client {
+func UploadAction(conn RemoteServer, appFs afero.Fs, localDirname string, files []string, replaceBasePath, dryRun bool) *Client {
 	return &Client{
 		Command: &UploadCommand{
 			Server:          conn,
@@ -83,7 +83,7 @@ func UploadAction(conn *FTPServerConnection, appFs afero.Fs, localDirname string
 }
 
 // DeleteAllAction creates and configures the concrete delete all command.
-func DeleteAllAction(conn *FTPServerConnection, exludeList []string, dryRun bool) *Client {
+func DeleteAllAction(conn RemoteServer, exludeList []string, dryRun bool) *Client {
 	return &Client{
 		Command: &DeleteAllCommand{
 			Server:      conn,
@@ -94,7 +94,7 @@ func DeleteAllAction(conn *FTPServerConnection, exludeList []string, dryRun bool
 }
 
 // BackupAction creates and configures the concrete backup command.
-func BackupAction(conn *FTPServerConnection, appFs afero.Fs, name string, dryRun bool) *Client {
+func BackupAction(conn RemoteServer, appFs afero.Fs, name string, dryRun bool) *Client {
 	return &Client{
 		Command: &BackupCommand{
 			Server: conn,
@@ -104,3 +104,61 @@ func BackupAction(conn *FTPServerConnection, appFs afero.Fs, name string, dryRun
 		},
 	}
 }
+
+// WriteFileAction creates and configures the concrete write file command.
+func WriteFileAction(conn RemoteServer, filename string, data []byte, dryRun bool) *Client {
+	return &Client{
+		Command: &WriteFileCommand{
+			Server:   conn,
+			Filename: filename,
+			Data:     data,
+			DryRun:   dryRun,
+		},
+	}
+}
+
+// DeleteFilesAction creates and configures the concrete delete files command.
+func DeleteFilesAction(conn RemoteServer, files []string, dryRun bool) *Client {
+	return &Client{
+		Command: &DeleteFilesCommand{
+			Server: conn,
+			Files:  files,
+			DryRun: dryRun,
+		},
+	}
+}
+
+// RenameAction creates and configures the concrete rename command.
+func RenameAction(conn RemoteServer, oldPath, newPath string, dryRun bool) *Client {
+	return &Client{
+		Command: &RenameCommand{
+			Server:  conn,
+			OldPath: oldPath,
+			NewPath: newPath,
+			DryRun:  dryRun,
+		},
+	}
+}
+
+// RemoveDirAction creates and configures the concrete remove dir command.
+func RemoveDirAction(conn RemoteServer, dir string, dryRun bool) *Client {
+	return &Client{
+		Command: &RemoveDirCommand{
+			Server: conn,
+			Dir:    dir,
+			DryRun: dryRun,
+		},
+	}
+}
+
+// SwapAction creates and configures the concrete swap command.
+func SwapAction(conn RemoteServer, stagingFolder, previousFolder string, dryRun bool) *Client {
+	return &Client{
+		Command: &SwapCommand{
+			Server:         conn,
+			StagingFolder:  stagingFolder,
+			PreviousFolder: previousFolder,
+			DryRun:         dryRun,
+		},
+	}
+}
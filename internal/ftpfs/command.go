@@ -101,3 +101,61 @@ type BackupCommand struct {
 func (c *BackupCommand) execute() error {
 	return c.Server.DoBackup(c.AppFs, c.Name, c.DryRun)
 }
+
+// WriteFileCommand implements the write file request.
+type WriteFileCommand struct {
+	Server   RemoteServer
+	Filename string
+	Data     []byte
+	DryRun   bool
+}
+
+func (c *WriteFileCommand) execute() error {
+	return c.Server.WriteFile(c.Filename, c.Data, c.DryRun)
+}
+
+// DeleteFilesCommand implements the delete files request.
+type DeleteFilesCommand struct {
+	Server RemoteServer
+	Files  []string
+	DryRun bool
+}
+
+func (c *DeleteFilesCommand) execute() error {
+	return c.Server.DeleteFiles(c.Files, c.DryRun)
+}
+
+// RenameCommand implements the rename request.
+type RenameCommand struct {
+	Server  RemoteServer
+	OldPath string
+	NewPath string
+	DryRun  bool
+}
+
+func (c *RenameCommand) execute() error {
+	return c.Server.Rename(c.OldPath, c.NewPath, c.DryRun)
+}
+
+// RemoveDirCommand implements the remove dir request.
+type RemoveDirCommand struct {
+	Server RemoteServer
+	Dir    string
+	DryRun bool
+}
+
+func (c *RemoveDirCommand) execute() error {
+	return c.Server.RemoveDir(c.Dir, c.DryRun)
+}
+
+// SwapCommand implements the swap request.
+type SwapCommand struct {
+	Server         RemoteServer
+	StagingFolder  string
+	PreviousFolder string
+	DryRun         bool
+}
+
+func (c *SwapCommand) execute() error {
+	return c.Server.Swap(c.StagingFolder, c.PreviousFolder, c.DryRun)
+}
@@ -8,21 +8,115 @@
 package ftpfs
 
 import (
+	"crypto/tls"
+	"crypto/x509"
+	"fmt"
+	"os"
 	"strconv"
 	"strings"
+	"time"
+)
+
+// TLS modes for the FTP connection.
+const (
+	FTPTLSExplicit string = "explicit"
+	FTPTLSImplicit string = "implicit"
 )
 
 // FTPConnectionConfig is the struct with all is needed to
 // establish an FTP connection to a remote server.
+//
+// TLSMode enables FTPS, either explicit (AUTH TLS) or implicit. The server
+// certificate is verified against the system roots or the TLSCAFile bundle
+// unless TLSSkipVerify is true.
+// Parallel is the number of connections used to upload files (default: 1).
+// Retries is the number of times an operation failed with a transient error is
+// retried after reconnecting, waiting RetryDelay doubled at every attempt.
 type FTPConnectionConfig struct {
-	Host     string
-	Port     int
-	User     string
-	Password string
-	Timeout  int
-	IsEPSV   bool
+	Host          string
+	Port          int
+	User          string
+	Password      string
+	Timeout       int
+	IsEPSV        bool
+	TLSMode       string
+	TLSCAFile     string
+	TLSSkipVerify bool
+	Parallel      int
+	Retries       int
+	RetryDelay    time.Duration
 }
 
 func (d *FTPConnectionConfig) makeConnectionString() string {
 	return strings.Join([]string{d.Host, strconv.Itoa(d.Port)}, ":")
 }
+
+// makeTLSConfig returns the TLS configuration for the FTPS modes, nil for cleartext FTP.
+func (d *FTPConnectionConfig) makeTLSConfig() (*tls.Config, error) {
+	switch strings.ToLower(d.TLSMode) {
+	case "":
+		return nil, nil
+	case FTPTLSExplicit, FTPTLSImplicit:
+	default:
+		return nil, fmt.Errorf("not valid FTP TLS mode '%s'. Valid ones are: %s, %s", d.TLSMode, FTPTLSExplicit, FTPTLSImplicit)
+	}
+
+	tlsConfig := &tls.Config{
+		ServerName:         d.Host,
+		InsecureSkipVerify: d.TLSSkipVerify,
+		MinVersion:         tls.VersionTLS12,
+	}
+	if d.TLSCAFile != "" {
+		caCert, err := os.ReadFile(d.TLSCAFile)
+		if err != nil {
+			return nil, fmt.Errorf("could not read the CA bundle '%s', got error '%s'", d.TLSCAFile, err.Error())
+		}
+		caPool := x509.NewCertPool()
+		if !caPool.AppendCertsFromPEM(caCert) {
+			return nil, fmt.Errorf("no valid certificates found in the CA bundle '%s'", d.TLSCAFile)
+		}
+		tlsConfig.RootCAs = caPool
+	}
+	return tlsConfig, nil
+}
+
+// SFTPConnectionConfig is the struct with all is needed to
+// establish an SFTP connection to a remote server.
+//
+// Authentication happens with the private key (PrivateKeyFile), the password
+// or both. The remote host key is verified against the KnownHostsFile
+// (default: ~/.ssh/known_hosts) unless IgnoreHostKey is true.
+// Parallel is the number of concurrent uploads over the connection (default: 1).
+type SFTPConnectionConfig struct {
+	Host                 string
+	Port                 int
+	User                 string
+	Password             string
+	PrivateKeyFile       string
+	PrivateKeyPassphrase string
+	KnownHostsFile       string
+	IgnoreHostKey        bool
+	Timeout              int
+	Parallel             int
+}
+
+func (d *SFTPConnectionConfig) makeConnectionString() string {
+	return strings.Join([]string{d.Host, strconv.Itoa(d.Port)}, ":")
+}
+
+// S3ConnectionConfig is the struct with all is needed to
+// connect to an S3-compatible object storage (AWS S3, MinIO, ...).
+//
+// CacheControl is set on the uploaded objects except the SvelteKit
+// immutable ones (default: DefaultCacheControl).
+// Parallel is the number of concurrent uploads (default: 1).
+type S3ConnectionConfig struct {
+	Endpoint     string
+	Region       string
+	Bucket       string
+	AccessKey    string
+	SecretKey    string
+	UseSSL       bool
+	CacheControl string
+	Parallel     int
+}
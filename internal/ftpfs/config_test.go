@@ -0,0 +1,86 @@
+package ftpfs
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"math/big"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/matryer/is"
+)
+
+func newTestCAFile(t *testing.T) string {
+	t.Helper()
+	is := is.New(t)
+
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	is.NoErr(err)
+	template := &x509.Certificate{
+		SerialNumber:          big.NewInt(1),
+		Subject:               pkix.Name{CommonName: "sveltin test CA"},
+		NotBefore:             time.Now(),
+		NotAfter:              time.Now().Add(time.Hour),
+		IsCA:                  true,
+		BasicConstraintsValid: true,
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	is.NoErr(err)
+
+	caFile := filepath.Join(t.TempDir(), "ca.pem")
+	is.NoErr(os.WriteFile(caFile, pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der}), 0600))
+	return caFile
+}
+
+func TestFTPTLSConfig(t *testing.T) {
+	caFile := newTestCAFile(t)
+	notPEMFile := filepath.Join(t.TempDir(), "ca.txt")
+	if err := os.WriteFile(notPEMFile, []byte("not a certificate"), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name        string
+		config      FTPConnectionConfig
+		wantTLS     bool
+		wantRootCAs bool
+		wantErr     bool
+	}{
+		{name: "cleartext", config: FTPConnectionConfig{Host: "ftp.example.com"}},
+		{name: "explicit", config: FTPConnectionConfig{Host: "ftp.example.com", TLSMode: "explicit"}, wantTLS: true},
+		{name: "implicit uppercase", config: FTPConnectionConfig{Host: "ftp.example.com", TLSMode: "IMPLICIT"}, wantTLS: true},
+		{name: "custom CA", config: FTPConnectionConfig{Host: "ftp.example.com", TLSMode: "explicit", TLSCAFile: caFile}, wantTLS: true, wantRootCAs: true},
+		{name: "not valid mode", config: FTPConnectionConfig{TLSMode: "starttls"}, wantErr: true},
+		{name: "missing CA", config: FTPConnectionConfig{TLSMode: "explicit", TLSCAFile: "missing.pem"}, wantErr: true},
+		{name: "not PEM CA", config: FTPConnectionConfig{TLSMode: "explicit", TLSCAFile: notPEMFile}, wantErr: true},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			is := is.New(t)
+			tlsConfig, err := tc.config.makeTLSConfig()
+			if tc.wantErr {
+				is.True(err != nil)
+				return
+			}
+			is.NoErr(err)
+			is.Equal(tlsConfig != nil, tc.wantTLS)
+			if tc.wantTLS {
+				is.Equal(tlsConfig.ServerName, "ftp.example.com")
+				is.Equal(tlsConfig.RootCAs != nil, tc.wantRootCAs)
+			}
+		})
+	}
+
+	is := is.New(t)
+	conn := NewFTPServerConnection(&FTPConnectionConfig{Host: "ftp.example.com", TLSMode: "implicit", TLSSkipVerify: true})
+	options, err := conn.dialOptions()
+	is.NoErr(err)
+	is.Equal(len(options), 3)
+}
@@ -16,13 +16,13 @@ import (
 	"io"
 	"path/filepath"
 	"sort"
+	"strings"
 	"time"
 
 	tea "github.com/charmbracelet/bubbletea"
 	"github.com/jlaffaye/ftp"
 	"github.com/spf13/afero"
 	"github.com/sveltinio/prompti/progressbar"
-	"github.com/sveltinio/sveltin/common"
 	"github.com/sveltinio/sveltin/utils"
 	"github.com/sveltinio/yinlog"
 )
@@ -39,12 +39,18 @@ type FTPServerConnection struct {
 func NewFTPServerConnection(config *FTPConnectionConfig) *FTPServerConnection {
 	return &FTPServerConnection{
 		Config: FTPConnectionConfig{
-			Host:     config.Host,
-			Port:     config.Port,
-			User:     config.User,
-			Password: config.Password,
-			Timeout:  config.Timeout,
-			IsEPSV:   config.IsEPSV,
+			Host:          config.Host,
+			Port:          config.Port,
+			User:          config.User,
+			Password:      config.Password,
+			Timeout:       config.Timeout,
+			IsEPSV:        config.IsEPSV,
+			TLSMode:       config.TLSMode,
+			TLSCAFile:     config.TLSCAFile,
+			TLSSkipVerify: config.TLSSkipVerify,
+			Parallel:      config.Parallel,
+			Retries:       config.Retries,
+			RetryDelay:    config.RetryDelay,
 		},
 	}
 }
@@ -62,8 +68,16 @@ func (s *FTPServerConnection) SetLogger(logger *yinlog.Logger) {
 // Dial contains the logic for the FTP receiver to handle the dial command.
 func (s *FTPServerConnection) Dial() error {
 	connStr := s.Config.makeConnectionString()
-	s.logger.Infof("Connecting to the FTP Server (%s) ", connStr)
-	c, err := ftp.Dial(connStr, ftp.DialWithTimeout(time.Duration(s.Config.Timeout)*time.Second), ftp.DialWithDisabledEPSV(s.Config.IsEPSV))
+	dialOptions, err := s.dialOptions()
+	if err != nil {
+		return err
+	}
+	if s.Config.TLSMode != "" {
+		s.logger.Infof("Connecting to the FTP Server (%s) over %s TLS", connStr, strings.ToLower(s.Config.TLSMode))
+	} else {
+		s.logger.Infof("Connecting to the FTP Server (%s) ", connStr)
+	}
+	c, err := ftp.Dial(connStr, dialOptions...)
 	if err != nil {
 		return err
 	}
@@ -98,7 +112,20 @@ func (s *FTPServerConnection) Idle() error {
 func (s *FTPServerConnection) MakeDirs(folders []string, dryRun bool) error {
 	sort.Strings(folders)
 	if err := s.client.ChangeDir(s.serverFolder); err != nil {
-		return err
+		// only the staging folder of an atomic deploy is created, a missing remote folder is a wrong setting
+		if !strings.HasSuffix(strings.TrimSuffix(s.serverFolder, "/"), StagingFolderSuffix) {
+			return err
+		}
+		if dryRun {
+			s.logger.Importantf("The remote folder %s does not exist and will be created", s.serverFolder)
+		} else {
+			if err := s.client.MakeDir(s.serverFolder); err != nil {
+				return err
+			}
+			if err := s.client.ChangeDir(s.serverFolder); err != nil {
+				return err
+			}
+		}
 	}
 
 	pbConfig := &progressbar.Config{
@@ -109,7 +136,7 @@ func (s *FTPServerConnection) MakeDirs(folders []string, dryRun bool) error {
 		},
 	}
 
-	if _, err := progressbar.Run(pbConfig); err != nil {
+	if err := runProgressBar(opMakeDirs, pbConfig); err != nil {
 		return err
 	}
 	return nil
@@ -119,6 +146,10 @@ func (s *FTPServerConnection) MakeDirs(folders []string, dryRun bool) error {
 func (s *FTPServerConnection) UploadFiles(appFs afero.Fs, localDir string, files []string, replaceBasePath, dryRun bool) error {
 	sort.Strings(files)
 
+	if s.Config.Parallel > 1 && len(files) > 1 && !dryRun {
+		return s.uploadFilesInParallel(appFs, localDir, files, replaceBasePath)
+	}
+
 	pbConfig := &progressbar.Config{
 		Items:          files,
 		OnCompletesMsg: fmt.Sprintf("Done! %d files uploaded", len(files)),
@@ -127,44 +158,50 @@ func (s *FTPServerConnection) UploadFiles(appFs afero.Fs, localDir string, files
 		},
 	}
 
-	if _, err := progressbar.Run(pbConfig); err != nil {
+	if err := runProgressBar(opUpload, pbConfig); err != nil {
 		return err
 	}
 	return nil
 }
 
 // DeleteAll contains the logic for the FTP receiver to handle the delete all command.
+// The entries matching the gitignore-style exclude rules are kept.
 func (s *FTPServerConnection) DeleteAll(exclude []string, dryrun bool) error {
 	entries, err := s.client.List(s.serverFolder)
 	if err != nil {
 		return err
 	}
+	if len(entries) == 0 {
+		return nil
+	}
 
-	if len(entries) > 0 {
-		s.logger.Important("Deleting previous content from the FTP remote folder")
-		for _, entry := range entries {
-			switch entry.Type {
-			case ftp.EntryTypeFolder:
-				if !dryrun {
-					folder := filepath.Join(s.serverFolder, entry.Name)
-					if err := s.client.RemoveDirRecur(folder); err != nil {
-						return err
-					}
-				}
-			case ftp.EntryTypeFile:
-				if !dryrun {
-					file := filepath.Join(s.serverFolder, entry.Name)
-					if !common.Contains(exclude, filepath.Base(file)) {
-						if err := s.client.Delete(file); err != nil {
-							return nil
-						}
-					}
+	s.logger.Important("Deleting previous content from the FTP remote folder")
+	cleaner := &remoteCleaner{
+		list: func(dir string) ([]remoteEntry, error) {
+			entries, err := s.client.List(filepath.Join(s.serverFolder, dir))
+			if err != nil {
+				return nil, err
+			}
+			list := []remoteEntry{}
+			for _, entry := range entries {
+				if entry.Type == ftp.EntryTypeFolder || entry.Type == ftp.EntryTypeFile {
+					list = append(list, remoteEntry{name: entry.Name, isDir: entry.Type == ftp.EntryTypeFolder})
 				}
 			}
-		}
+			return list, nil
+		},
+		removeFile: func(name string) error {
+			return s.client.Delete(filepath.Join(s.serverFolder, name))
+		},
+		removeDir: func(name string) error {
+			return s.client.RemoveDir(filepath.Join(s.serverFolder, name))
+		},
+		removeDirRecur: func(name string) error {
+			return s.client.RemoveDirRecur(filepath.Join(s.serverFolder, name))
+		},
 	}
-
-	return nil
+	_, err = cleaner.cleanDir(".", NewExcludeRules(exclude), dryrun)
+	return err
 }
 
 // DoBackup contains the logic for the FTP receiver to handle the backup command.
@@ -185,8 +222,113 @@ func (s *FTPServerConnection) DoBackup(appFs afero.Fs, tarballFilePath string, d
 	return nil
 }
 
+// ReadFile contains the logic for the FTP receiver to retrieve a file from the remote folder.
+func (s *FTPServerConnection) ReadFile(filename string) ([]byte, error) {
+	r, err := s.client.Retr(filepath.Join(s.serverFolder, filename))
+	if err != nil {
+		return nil, err
+	}
+	defer r.Close()
+	return io.ReadAll(r)
+}
+
+// WriteFile contains the logic for the FTP receiver to store a file on the remote folder.
+func (s *FTPServerConnection) WriteFile(filename string, data []byte, dryRun bool) error {
+	return s.uploadSingle(filename, bytes.NewBuffer(data), dryRun)
+}
+
+// DeleteFiles contains the logic for the FTP receiver to handle the delete files command.
+func (s *FTPServerConnection) DeleteFiles(files []string, dryRun bool) error {
+	if len(files) == 0 {
+		return nil
+	}
+	sort.Strings(files)
+
+	pbConfig := &progressbar.Config{
+		Items:          files,
+		OnCompletesMsg: fmt.Sprintf("Done! %d files deleted", len(files)),
+		OnProgressCmd: func(path string) tea.Cmd {
+			return deleteFileTeaCmd(s, path, dryRun)
+		},
+	}
+
+	if err := runProgressBar(deleteOperation(dryRun), pbConfig); err != nil {
+		return err
+	}
+	return nil
+}
+
+// Rename contains the logic for the FTP receiver to handle the rename command.
+func (s *FTPServerConnection) Rename(oldPath, newPath string, dryRun bool) error {
+	s.logger.Infof("Renaming %s to %s", oldPath, newPath)
+	if dryRun {
+		return nil
+	}
+	return s.client.Rename(oldPath, newPath)
+}
+
+// RemoveDir contains the logic for the FTP receiver to handle the remove dir command.
+// It does nothing when the folder does not exist.
+func (s *FTPServerConnection) RemoveDir(dirname string, dryRun bool) error {
+	if dryRun || !s.dirExists(dirname) {
+		return nil
+	}
+	s.logger.Infof("Deleting the remote folder %s", dirname)
+	return s.client.RemoveDirRecur(dirname)
+}
+
+// Swap contains the logic for the FTP receiver to handle the swap command.
+// The root folder is renamed as previousFolder and the stagingFolder takes its place.
+func (s *FTPServerConnection) Swap(stagingFolder, previousFolder string, dryRun bool) error {
+	s.logger.Infof("Swapping %s with %s", stagingFolder, s.serverFolder)
+	if dryRun {
+		return nil
+	}
+
+	if err := s.RemoveDir(previousFolder, dryRun); err != nil {
+		return err
+	}
+	if s.dirExists(s.serverFolder) {
+		if err := s.client.Rename(s.serverFolder, previousFolder); err != nil {
+			return err
+		}
+	}
+	return s.client.Rename(stagingFolder, s.serverFolder)
+}
+
+// ListFiles returns the files within the FTP remote folder.
+func (s *FTPServerConnection) ListFiles() ([]RemoteFile, error) {
+	w := s.client.Walk(s.serverFolder)
+	files := []RemoteFile{}
+	for w.Next() {
+		if entry := w.Stat(); entry.Type == ftp.EntryTypeFile {
+			files = append(files, RemoteFile{
+				Path:    utils.ToBasePath(w.Path(), s.serverFolder),
+				Size:    int64(entry.Size),
+				ModTime: entry.Time,
+			})
+		}
+	}
+	if err := w.Err(); err != nil {
+		return nil, err
+	}
+	sortRemoteFiles(files)
+	return files, nil
+}
+
 //=============================================================================
 
+func (s *FTPServerConnection) dirExists(dirname string) bool {
+	cwd, err := s.client.CurrentDir()
+	if err != nil {
+		return false
+	}
+	if err := s.client.ChangeDir(dirname); err != nil {
+		return false
+	}
+	return s.client.ChangeDir(cwd) == nil
+}
+
 func (s *FTPServerConnection) walkRemote() []string {
 	w := s.client.Walk(s.serverFolder)
 	var remoteFiles []string
@@ -198,6 +340,120 @@ func (s *FTPServerConnection) walkRemote() []string {
 	return remoteFiles
 }
 
+// uploadLocalFile reads the file from appFs and uploads it to the remote folder.
+func (s *FTPServerConnection) uploadLocalFile(appFs afero.Fs, file, localDir string, replaceBasePath bool) error {
+	fileBytes, err := afero.ReadFile(appFs, file)
+	if err != nil {
+		return err
+	}
+	remoteFile := file
+	if replaceBasePath {
+		remoteFile = utils.ToBasePath(file, localDir)
+	}
+	if err := s.uploadSingle(remoteFile, bytes.NewBuffer(fileBytes), false); err != nil {
+		return err
+	}
+	trackBytes(len(fileBytes))
+	return nil
+}
+
+// uploadFilesInParallel opens a pool of connections sharing the files queue.
+// Each connection has its own current directory so they can upload at the same time.
+func (s *FTPServerConnection) uploadFilesInParallel(appFs afero.Fs, localDir string, files []string, replaceBasePath bool) error {
+	numOfConns := numOfWorkers(s.Config.Parallel, len(files))
+	s.logger.Infof("Opening %d connections to the FTP server", numOfConns)
+
+	workers := []worker{}
+	for i := 0; i < numOfConns; i++ {
+		conn, err := s.newPoolConnection()
+		if err != nil {
+			return err
+		}
+		// the client may be replaced when reconnecting
+		defer func() { conn.client.Quit() }()
+		workers = append(workers, func(file string) error {
+			return conn.retry(func() error {
+				return conn.uploadLocalFile(appFs, file, localDir, replaceBasePath)
+			})
+		})
+	}
+
+	return uploadInParallel(files, workers)
+}
+
+// newPoolConnection returns a new connection logged in with the same configuration and remote folder.
+func (s *FTPServerConnection) newPoolConnection() (*FTPServerConnection, error) {
+	c, err := s.dialAndLogin()
+	if err != nil {
+		return nil, err
+	}
+	return &FTPServerConnection{
+		Config:       s.Config,
+		serverFolder: s.serverFolder,
+		client:       c,
+		logger:       s.logger,
+	}, nil
+}
+
+// dialAndLogin opens a new connection to the FTP server and logs in.
+func (s *FTPServerConnection) dialAndLogin() (*ftp.ServerConn, error) {
+	dialOptions, err := s.dialOptions()
+	if err != nil {
+		return nil, err
+	}
+	c, err := ftp.Dial(s.Config.makeConnectionString(), dialOptions...)
+	if err != nil {
+		return nil, err
+	}
+	if err := c.Login(s.Config.User, s.Config.Password); err != nil {
+		c.Quit()
+		return nil, err
+	}
+	return c, nil
+}
+
+// reconnect replaces the client with a new logged in connection and moves back to the remote folder.
+func (s *FTPServerConnection) reconnect() error {
+	if s.client != nil {
+		s.client.Quit()
+	}
+	c, err := s.dialAndLogin()
+	if err != nil {
+		return err
+	}
+	s.client = c
+	// the remote folder may not exist yet, e.g. the staging one before MakeDirs
+	s.client.ChangeDir(s.serverFolder)
+	return nil
+}
+
+// retry runs op retrying with exponential backoff on transient errors.
+// The connection is reopened before each retry.
+func (s *FTPServerConnection) retry(op func() error) error {
+	return withRetry(s.Config.Retries, s.Config.RetryDelay, s.reconnect, op, func(attempt int, err error) {
+		s.logger.Warningf("%s. Reconnecting to the FTP server (attempt %d of %d)", err.Error(), attempt, s.Config.Retries)
+	})
+}
+
+func (s *FTPServerConnection) dialOptions() ([]ftp.DialOption, error) {
+	options := []ftp.DialOption{
+		ftp.DialWithTimeout(time.Duration(s.Config.Timeout) * time.Second),
+		ftp.DialWithDisabledEPSV(s.Config.IsEPSV),
+	}
+
+	tlsConfig, err := s.Config.makeTLSConfig()
+	if err != nil {
+		return nil, err
+	}
+	switch strings.ToLower(s.Config.TLSMode) {
+	case FTPTLSExplicit:
+		options = append(options, ftp.DialWithExplicitTLS(tlsConfig))
+	case FTPTLSImplicit:
+		options = append(options, ftp.DialWithTLS(tlsConfig))
+	}
+	return options, nil
+}
+
 func (s *FTPServerConnection) uploadSingle(filename string, data *bytes.Buffer, dryRun bool) error {
 	saveTo := filepath.Join(s.serverFolder, filepath.Dir(filename))
 	saveAs := filepath.Base(filename)
@@ -244,7 +500,7 @@ func (s *FTPServerConnection) createTarball(appFs afero.Fs, tarballFilePath stri
 		},
 	}
 
-	if _, err := progressbar.Run(pbConfig); err != nil {
+	if err := runProgressBar(opBackup, pbConfig); err != nil {
 		return err
 	}
 
@@ -0,0 +1,250 @@
+/**
+ * Copyright © 2021-present Sveltin contributors <github@sveltin.io>
+ *
+ * Use of this source code is governed by Apache 2.0 license
+ * that can be found in the LICENSE file.
+ */
+
+package ftpfs
+
+import (
+	"path"
+	"regexp"
+	"strings"
+)
+
+// ExcludeFilename is the file with the exclude rules for the remote folder, in the project root.
+const ExcludeFilename string = ".sveltinignore"
+
+// excludePattern is a single gitignore-style rule.
+type excludePattern struct {
+	re       *regexp.Regexp
+	segments []string
+	negate   bool
+	dirOnly  bool
+	anchored bool
+}
+
+// ExcludeRules is the list of gitignore-style rules protecting remote files and folders from being deleted.
+//
+// As for .gitignore files: blank lines and lines starting with # are ignored, a leading !
+// negates the rule, a trailing / matches folders only, a rule with a / in the beginning or
+// in the middle is relative to the remote folder while the other ones match at any depth,
+// * and ? do not match /, ** matches any number of folders. The last matching rule wins and
+// a file within an excluded folder cannot be re-included.
+type ExcludeRules struct {
+	patterns []excludePattern
+}
+
+// NewExcludeRules parses the rules.
+func NewExcludeRules(lines []string) *ExcludeRules {
+	rules := &ExcludeRules{}
+	for _, line := range lines {
+		line = strings.TrimSpace(line)
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+
+		p := excludePattern{}
+		if strings.HasPrefix(line, "!") {
+			p.negate = true
+			line = line[1:]
+		} else if strings.HasPrefix(line, `\`) {
+			line = line[1:]
+		}
+		if strings.HasSuffix(line, "/") {
+			p.dirOnly = true
+			line = strings.TrimRight(line, "/")
+		}
+		if strings.Contains(line, "/") {
+			p.anchored = true
+			line = strings.TrimPrefix(line, "/")
+		}
+		if line == "" {
+			continue
+		}
+		if !p.anchored {
+			line = "**/" + line
+		}
+		p.segments = strings.Split(line, "/")
+		p.re = regexp.MustCompile("^" + globToRegexp(line) + "$")
+		rules.patterns = append(rules.patterns, p)
+	}
+	return rules
+}
+
+// IsEmpty returns true when there are no rules.
+func (r *ExcludeRules) IsEmpty() bool {
+	return len(r.patterns) == 0
+}
+
+// Match returns true when the path, relative to the remote folder, is excluded by the last matching rule.
+// It does not take the parent folders into account, see Excluded.
+func (r *ExcludeRules) Match(name string, isDir bool) bool {
+	name = path.Clean(strings.TrimPrefix(name, "/"))
+	matched := false
+	for _, p := range r.patterns {
+		if p.dirOnly && !isDir {
+			continue
+		}
+		if p.re.MatchString(name) {
+			matched = !p.negate
+		}
+	}
+	return matched
+}
+
+// Excluded returns true when the path or one of its parent folders is excluded.
+func (r *ExcludeRules) Excluded(name string, isDir bool) bool {
+	name = path.Clean(strings.TrimPrefix(name, "/"))
+	segments := strings.Split(name, "/")
+	for i := 1; i < len(segments); i++ {
+		if r.Match(strings.Join(segments[:i], "/"), true) {
+			return true
+		}
+	}
+	return r.Match(name, isDir)
+}
+
+// mayMatchWithin returns true when a rule could exclude something within the folder,
+// so that the folder cannot be deleted recursively at once.
+func (r *ExcludeRules) mayMatchWithin(dir string) bool {
+	dirSegments := strings.Split(path.Clean(strings.TrimPrefix(dir, "/")), "/")
+	for _, p := range r.patterns {
+		if p.negate {
+			continue
+		}
+		if segmentsMayMatchWithin(p.segments, dirSegments) {
+			return true
+		}
+	}
+	return false
+}
+
+func segmentsMayMatchWithin(patternSegments, dirSegments []string) bool {
+	for i, dirSegment := range dirSegments {
+		if i >= len(patternSegments) {
+			return false
+		}
+		if patternSegments[i] == "**" {
+			return true
+		}
+		if matched, _ := path.Match(patternSegments[i], dirSegment); !matched {
+			return false
+		}
+	}
+	return len(patternSegments) > len(dirSegments)
+}
+
+// globToRegexp converts the glob to a regular expression, ** matching any number of folders.
+func globToRegexp(glob string) string {
+	var b strings.Builder
+	for i := 0; i < len(glob); i++ {
+		c := glob[i]
+		switch {
+		case strings.HasPrefix(glob[i:], "**/"):
+			b.WriteString("(?:.*/)?")
+			i += 2
+		case strings.HasPrefix(glob[i:], "/**") && i+3 == len(glob):
+			b.WriteString("/.*")
+			i += 2
+		case strings.HasPrefix(glob[i:], "**"):
+			b.WriteString(".*")
+			i++
+		case c == '*':
+			b.WriteString("[^/]*")
+		case c == '?':
+			b.WriteString("[^/]")
+		case c == '[':
+			end := strings.IndexByte(glob[i:], ']')
+			if end < 0 {
+				b.WriteString(`\[`)
+				continue
+			}
+			class := glob[i+1 : i+end]
+			if strings.HasPrefix(class, "!") {
+				class = "^" + class[1:]
+			}
+			b.WriteString("[" + class + "]")
+			i += end
+		case c == '\\' && i+1 < len(glob):
+			i++
+			b.WriteString(regexp.QuoteMeta(string(glob[i])))
+		default:
+			b.WriteString(regexp.QuoteMeta(string(c)))
+		}
+	}
+	return b.String()
+}
+
+//=============================================================================
+
+// remoteEntry is a file or folder listed from the remote folder.
+type remoteEntry struct {
+	name  string
+	isDir bool
+}
+
+// remoteCleaner is what is needed to delete the remote folder content preserving the excluded entries.
+// All the paths are relative to the remote folder.
+type remoteCleaner struct {
+	list           func(dir string) ([]remoteEntry, error)
+	removeFile     func(name string) error
+	removeDir      func(name string) error
+	removeDirRecur func(name string) error
+}
+
+// cleanDir deletes the folder content except the entries excluded by the rules. Folders without
+// excluded entries are deleted at once, the other ones are walked. It returns true when something
+// has been kept within the folder.
+func (c *remoteCleaner) cleanDir(dir string, rules *ExcludeRules, dryRun bool) (bool, error) {
+	entries, err := c.list(dir)
+	if err != nil {
+		return false, err
+	}
+
+	kept := false
+	for _, entry := range entries {
+		if entry.name == "." || entry.name == ".." {
+			continue
+		}
+		name := path.Join(dir, entry.name)
+		if rules.Match(name, entry.isDir) {
+			kept = true
+			continue
+		}
+
+		if !entry.isDir {
+			if !dryRun {
+				if err := c.removeFile(name); err != nil {
+					return kept, err
+				}
+			}
+			trackItem(deleteOperation(dryRun), name, nil)
+			continue
+		}
+
+		if !rules.mayMatchWithin(name) {
+			if !dryRun {
+				if err := c.removeDirRecur(name); err != nil {
+					return kept, err
+				}
+			}
+			trackItem(deleteOperation(dryRun), name+"/", nil)
+			continue
+		}
+
+		keptWithin, err := c.cleanDir(name, rules, dryRun)
+		if err != nil {
+			return kept, err
+		}
+		if keptWithin {
+			kept = true
+		} else if !dryRun {
+			if err := c.removeDir(name); err != nil {
+				return kept, err
+			}
+		}
+	}
+	return kept, nil
+}
@@ -0,0 +1,99 @@
+package ftpfs
+
+import (
+	"testing"
+
+	"github.com/matryer/is"
+	"github.com/spf13/afero"
+)
+
+func TestExcludeRules(t *testing.T) {
+	is := is.New(t)
+	rules := NewExcludeRules([]string{
+		"# keep the server config",
+		".htaccess",
+		"",
+		"*.php",
+		"!public/*.php",
+		"uploads/",
+		"/robots.txt",
+		"data/**/*.json",
+	})
+
+	tests := []struct {
+		path  string
+		isDir bool
+		want  bool
+	}{
+		{".htaccess", false, true},
+		{"blog/.htaccess", false, true},
+		{"index.php", false, true},
+		{"admin/login.php", false, true},
+		{"public/index.php", false, false},
+		{"uploads", true, true},
+		{"media/uploads", true, true},
+		{"uploads", false, false},
+		{"robots.txt", false, true},
+		{"blog/robots.txt", false, false},
+		{"data/feed.json", false, true},
+		{"data/2023/01/feed.json", false, true},
+		{"other/feed.json", false, false},
+		{"index.html", false, false},
+	}
+	for _, tt := range tests {
+		is.Equal(rules.Match(tt.path, tt.isDir), tt.want) // tt.path
+	}
+
+	// files within an excluded folder are excluded too
+	is.True(rules.Excluded("uploads/2023/photo.jpg", false))
+	is.True(!rules.Match("uploads/2023/photo.jpg", false))
+	is.True(NewExcludeRules([]string{"# nothing", " "}).IsEmpty())
+}
+
+func TestExcludeRulesMayMatchWithin(t *testing.T) {
+	is := is.New(t)
+	rules := NewExcludeRules([]string{"/assets/keep.txt", "data/**/*.json", "!*.html"})
+
+	is.True(rules.mayMatchWithin("assets"))
+	is.True(!rules.mayMatchWithin("assets/css"))
+	is.True(!rules.mayMatchWithin("posts"))
+	is.True(rules.mayMatchWithin("data/2023"))
+	is.True(NewExcludeRules([]string{"*.php"}).mayMatchWithin("posts"))
+}
+
+func TestDeleteAllWithExcludeRules(t *testing.T) {
+	is := is.New(t)
+	_, report := usePlainProgressAndReport(t)
+	fs := afero.NewMemMapFs()
+	conn := newTestLocalDirConnection(fs, "/srv/www/site")
+	for _, file := range []string{
+		"index.html",
+		".htaccess",
+		"posts/first/index.html",
+		"uploads/2023/photo.jpg",
+		"media/logo.png",
+		"media/uploads/video.mp4",
+		"api/v1/users.php",
+		"api/v1/users.json",
+	} {
+		is.NoErr(WriteFileAction(conn, file, []byte("x"), false).Run())
+	}
+	rules := []string{".htaccess", "uploads/", "*.php"}
+
+	// dry-run must not delete anything
+	is.NoErr(DeleteAllAction(conn, rules, true).Run())
+	files, err := conn.walkFiles()
+	is.NoErr(err)
+	is.Equal(len(files), 8)
+
+	is.NoErr(DeleteAllAction(conn, rules, false).Run())
+	files, err = conn.walkFiles()
+	is.NoErr(err)
+	is.Equal(files, []string{".htaccess", "api/v1/users.php", "media/uploads/video.mp4", "uploads/2023/photo.jpg"})
+
+	// *.php may match at any depth so every folder is walked
+	deleted := []string{"api/v1/users.json", "index.html", "media/logo.png", "posts/first/index.html"}
+	is.Equal(report.FilesDeleted, deleted)
+	// the dry-run only reports the deletions as planned
+	is.Equal(report.PlannedDeletions, deleted)
+}
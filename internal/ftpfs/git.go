@@ -0,0 +1,186 @@
+/**
+ * Copyright © 2021-present Sveltin contributors <github@sveltin.io>
+ *
+ * Use of this source code is governed by Apache 2.0 license
+ * that can be found in the LICENSE file.
+ */
+
+package ftpfs
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/spf13/afero"
+	"github.com/sveltinio/sveltin/internal/shell"
+	"github.com/sveltinio/yinlog"
+)
+
+// Defaults for the git deploy target.
+const (
+	DefaultGitRemote string = "origin"
+	DefaultGitBranch string = "gh-pages"
+)
+
+// gitMetadataFile is the .git file linking a worktree to its repository. It is never deleted.
+const gitMetadataFile string = ".git"
+
+// GitConnectionConfig is the struct with the settings for the git deploy target.
+type GitConnectionConfig struct {
+	RepoPath      string
+	Remote        string
+	Branch        string
+	CommitMessage string
+	// DryRun keeps the changes out of the branch: nothing is committed nor pushed.
+	DryRun bool
+}
+
+// GitConnection is the struct to deploy to a branch of a git repository, e.g. gh-pages.
+// The branch is checked out into a temporary worktree, the files are handled there as
+// for a local folder and the changes are committed and pushed to the remote on logout.
+type GitConnection struct {
+	*LocalDirConnection
+	Config    GitConnectionConfig
+	subFolder string
+	worktree  string
+	git       *shell.GitShell
+	logger    *yinlog.Logger
+}
+
+// NewGitConnection returns a new GitConnection struct.
+func NewGitConnection(config *GitConnectionConfig) *GitConnection {
+	if config.Remote == "" {
+		config.Remote = DefaultGitRemote
+	}
+	if config.Branch == "" {
+		config.Branch = DefaultGitBranch
+	}
+	worktreeDir := NewLocalDirConnection(afero.NewOsFs())
+	// the worktree .git file is never listed, backed up nor deleted
+	worktreeDir.hidden = []string{gitMetadataFile}
+	return &GitConnection{
+		LocalDirConnection: worktreeDir,
+		Config:             *config,
+		git:                shell.NewGitClient(),
+	}
+}
+
+// SetRootFolder sets the destination folder within the branch.
+func (s *GitConnection) SetRootFolder(name string) {
+	s.subFolder = name
+	if s.worktree != "" {
+		s.LocalDirConnection.SetRootFolder(filepath.Join(s.worktree, name))
+	}
+}
+
+// SetLogger sets the logger used by the git connection.
+func (s *GitConnection) SetLogger(logger *yinlog.Logger) {
+	s.logger = logger
+	s.LocalDirConnection.SetLogger(logger)
+}
+
+// Dial contains the logic for the git receiver to handle the dial command.
+// It checks out the branch from the remote into a temporary worktree, or creates
+// it with no history when it does not exist on the remote yet. The local branch
+// is moved to the remote one, so it fails when the local branch has unpushed commits.
+func (s *GitConnection) Dial() error {
+	s.logger.Infof("Checking out the '%s' branch from the '%s' remote", s.Config.Branch, s.Config.Remote)
+	exists, err := s.git.RemoteBranchExists(s.Config.RepoPath, s.Config.Remote, s.Config.Branch)
+	if err != nil {
+		return err
+	}
+	localExists, err := s.git.LocalBranchExists(s.Config.RepoPath, s.Config.Branch)
+	if err != nil {
+		return err
+	}
+	if exists {
+		if err := s.git.RunFetch(s.Config.RepoPath, s.Config.Remote, s.Config.Branch, true); err != nil {
+			return err
+		}
+		if localExists {
+			ahead, err := s.git.CountCommitsAhead(s.Config.RepoPath, s.Config.Branch, s.Config.Remote+"/"+s.Config.Branch)
+			if err != nil {
+				return err
+			}
+			if ahead > 0 {
+				return fmt.Errorf("the local '%s' branch has %d commits not on the '%s' remote, push or drop them before deploying", s.Config.Branch, ahead, s.Config.Remote)
+			}
+		}
+	} else if localExists {
+		return fmt.Errorf("the '%s' branch exists locally but not on the '%s' remote, push or delete it before deploying", s.Config.Branch, s.Config.Remote)
+	}
+
+	worktree, err := os.MkdirTemp("", "sveltin-deploy-")
+	if err != nil {
+		return err
+	}
+	// git worktree add wants a missing or empty folder
+	if err := os.Remove(worktree); err != nil {
+		return err
+	}
+	if exists {
+		err = s.git.RunWorktreeAdd(s.Config.RepoPath, worktree, s.Config.Branch, s.Config.Remote+"/"+s.Config.Branch, true)
+	} else {
+		s.logger.Importantf("The '%s' branch does not exist on the remote, it will be created", s.Config.Branch)
+		err = s.git.RunWorktreeAddOrphan(s.Config.RepoPath, worktree, s.Config.Branch, true)
+	}
+	if err != nil {
+		return err
+	}
+
+	s.worktree = worktree
+	s.SetRootFolder(s.subFolder)
+	return nil
+}
+
+// Login contains the logic for the git receiver to handle the login command. Nothing to do.
+func (s *GitConnection) Login() error {
+	return nil
+}
+
+// Logout contains the logic for the git receiver to handle the logout command.
+// It commits the changes, if any, pushes the branch and removes the worktree.
+func (s *GitConnection) Logout() error {
+	if s.worktree == "" {
+		return nil
+	}
+	defer s.Cleanup()
+
+	if s.Config.DryRun {
+		return nil
+	}
+	if err := s.git.RunAddAll(s.worktree, true); err != nil {
+		return err
+	}
+	hasChanges, err := s.git.HasStagedChanges(s.worktree)
+	if err != nil {
+		return err
+	}
+	if !hasChanges {
+		s.logger.Info("Nothing to commit, the branch is up to date")
+		return nil
+	}
+
+	message := s.Config.CommitMessage
+	if message == "" {
+		message = "Deploy " + time.Now().Format("2006-01-02 15:04:05")
+	}
+	s.logger.Infof("Committing and pushing to '%s/%s'", s.Config.Remote, s.Config.Branch)
+	if err := s.git.RunCommit(s.worktree, message, true); err != nil {
+		return err
+	}
+	return s.git.RunPush(s.worktree, s.Config.Remote, s.Config.Branch, true)
+}
+
+// Cleanup removes the temporary worktree, if any, without committing the changes.
+// It releases the worktree when exiting on error before logout.
+func (s *GitConnection) Cleanup() error {
+	if s.worktree == "" {
+		return nil
+	}
+	err := s.git.RunWorktreeRemove(s.Config.RepoPath, s.worktree, true)
+	s.worktree = ""
+	return err
+}
@@ -0,0 +1,135 @@
+package ftpfs
+
+import (
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/matryer/is"
+	"github.com/spf13/afero"
+	"github.com/sveltinio/yinlog"
+)
+
+func runTestGit(t *testing.T, dir string, args ...string) string {
+	t.Helper()
+	cmd := exec.Command("git", args...)
+	cmd.Dir = dir
+	out, err := cmd.CombinedOutput()
+	if err != nil {
+		t.Fatalf("git %s: %v\n%s", strings.Join(args, " "), err, out)
+	}
+	return strings.TrimSpace(string(out))
+}
+
+func deployToTestBranch(t *testing.T, repo string, build afero.Fs, exclude []string) {
+	t.Helper()
+	is := is.New(t)
+	conn := NewGitConnection(&GitConnectionConfig{RepoPath: repo, CommitMessage: "Deploy"})
+	conn.SetRootFolder("")
+	conn.SetLogger(yinlog.New())
+
+	is.NoErr(DialAction(conn).Run())
+	is.NoErr(DeleteAllAction(conn, exclude, false).Run())
+	files, err := afero.ReadDir(build, "build")
+	is.NoErr(err)
+	filenames := []string{}
+	for _, file := range files {
+		filenames = append(filenames, filepath.Join("build", file.Name()))
+	}
+	is.NoErr(UploadAction(conn, build, "build", filenames, true, false).Run())
+	is.NoErr(LogoutAction(conn).Run())
+}
+
+func TestGitDeploy(t *testing.T) {
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git is not installed")
+	}
+	is := is.New(t)
+	usePlainProgressAndReport(t)
+
+	remote := t.TempDir()
+	runTestGit(t, remote, "init", "--quiet", "--bare")
+	repo := t.TempDir()
+	runTestGit(t, repo, "init", "--quiet")
+	runTestGit(t, repo, "config", "user.name", "Sveltin")
+	runTestGit(t, repo, "config", "user.email", "test@sveltin.io")
+	runTestGit(t, repo, "remote", "add", "origin", remote)
+	runTestGit(t, repo, "commit", "--quiet", "--allow-empty", "-m", "Initial commit")
+
+	build := afero.NewMemMapFs()
+	is.NoErr(afero.WriteFile(build, "build/index.html", []byte("<h1>home</h1>"), 0644))
+	is.NoErr(afero.WriteFile(build, "build/about.html", []byte("<h1>about</h1>"), 0644))
+
+	// the branch is created on the remote with no history
+	deployToTestBranch(t, repo, build, nil)
+	is.Equal(runTestGit(t, remote, "ls-tree", "-r", "--name-only", DefaultGitBranch), "about.html\nindex.html")
+	is.Equal(runTestGit(t, remote, "rev-list", "--count", DefaultGitBranch), "1")
+
+	// a file added to the branch, e.g. CNAME for GitHub Pages, is kept when excluded
+	clone := t.TempDir()
+	runTestGit(t, clone, "clone", "--quiet", "--branch", DefaultGitBranch, remote, ".")
+	is.NoErr(afero.WriteFile(afero.NewOsFs(), filepath.Join(clone, "CNAME"), []byte("example.com"), 0644))
+	runTestGit(t, clone, "add", "CNAME")
+	runTestGit(t, clone, "-c", "user.name=Sveltin", "-c", "user.email=test@sveltin.io", "commit", "--quiet", "-m", "Add CNAME")
+	runTestGit(t, clone, "push", "--quiet", "origin", DefaultGitBranch)
+
+	is.NoErr(build.Remove("build/about.html"))
+	deployToTestBranch(t, repo, build, []string{"CNAME"})
+	is.Equal(runTestGit(t, remote, "ls-tree", "-r", "--name-only", DefaultGitBranch), "CNAME\nindex.html")
+	is.Equal(runTestGit(t, remote, "rev-list", "--count", DefaultGitBranch), "3")
+
+	// nothing is committed when the branch is up to date
+	deployToTestBranch(t, repo, build, []string{"CNAME"})
+	is.Equal(runTestGit(t, remote, "rev-list", "--count", DefaultGitBranch), "3")
+	// the temporary worktrees are removed
+	is.Equal(len(strings.Split(runTestGit(t, repo, "worktree", "list"), "\n")), 1)
+}
+
+func TestGitDialLocalBranch(t *testing.T) {
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git is not installed")
+	}
+	is := is.New(t)
+	usePlainProgressAndReport(t)
+
+	remote := t.TempDir()
+	runTestGit(t, remote, "init", "--quiet", "--bare")
+	repo := t.TempDir()
+	runTestGit(t, repo, "init", "--quiet")
+	runTestGit(t, repo, "config", "user.name", "Sveltin")
+	runTestGit(t, repo, "config", "user.email", "test@sveltin.io")
+	runTestGit(t, repo, "remote", "add", "origin", remote)
+	runTestGit(t, repo, "commit", "--quiet", "--allow-empty", "-m", "Initial commit")
+
+	build := afero.NewMemMapFs()
+	is.NoErr(afero.WriteFile(build, "build/index.html", []byte("<h1>home</h1>"), 0644))
+	deployToTestBranch(t, repo, build, nil)
+
+	newConn := func() *GitConnection {
+		conn := NewGitConnection(&GitConnectionConfig{RepoPath: repo, CommitMessage: "Deploy"})
+		conn.SetRootFolder("")
+		conn.SetLogger(yinlog.New())
+		return conn
+	}
+
+	// the worktree is removed without committing when the deploy fails after dial
+	conn := newConn()
+	is.NoErr(DialAction(conn).Run())
+	is.Equal(len(strings.Split(runTestGit(t, repo, "worktree", "list"), "\n")), 2)
+	is.NoErr(conn.Cleanup())
+	is.Equal(len(strings.Split(runTestGit(t, repo, "worktree", "list"), "\n")), 1)
+
+	// the unpushed commits on the local branch are never dropped
+	runTestGit(t, repo, "branch", "--quiet", "pushed", DefaultGitBranch)
+	runTestGit(t, repo, "update-ref", "refs/heads/"+DefaultGitBranch, runTestGit(t, repo, "commit-tree", "-p", DefaultGitBranch, "-m", "Local", DefaultGitBranch+"^{tree}"))
+	local := runTestGit(t, repo, "rev-parse", DefaultGitBranch)
+	is.True(DialAction(newConn()).Run() != nil)
+	is.Equal(runTestGit(t, repo, "rev-parse", DefaultGitBranch), local)
+	is.Equal(len(strings.Split(runTestGit(t, repo, "worktree", "list"), "\n")), 1)
+
+	// a branch checked out elsewhere is not checked out again
+	runTestGit(t, repo, "update-ref", "refs/heads/"+DefaultGitBranch, runTestGit(t, repo, "rev-parse", "pushed"))
+	runTestGit(t, repo, "checkout", "--quiet", DefaultGitBranch)
+	is.True(DialAction(newConn()).Run() != nil)
+}
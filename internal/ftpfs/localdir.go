@@ -0,0 +1,393 @@
+/**
+ * Copyright © 2021-present Sveltin contributors <github@sveltin.io>
+ *
+ * Use of this source code is governed by Apache 2.0 license
+ * that can be found in the LICENSE file.
+ */
+
+package ftpfs
+
+import (
+	"archive/tar"
+	"compress/gzip"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/spf13/afero"
+	"github.com/sveltinio/prompti/progressbar"
+	"github.com/sveltinio/sveltin/utils"
+	"github.com/sveltinio/yinlog"
+)
+
+// LocalDirConnection is the struct to deploy to a local or mounted folder as it was a remote server.
+type LocalDirConnection struct {
+	fs           afero.Fs
+	serverFolder string
+	logger       *yinlog.Logger
+	// hidden are the entries of the root folder never listed, backed up nor deleted.
+	hidden []string
+}
+
+// NewLocalDirConnection returns a new LocalDirConnection struct acting on the given file system.
+func NewLocalDirConnection(fs afero.Fs) *LocalDirConnection {
+	return &LocalDirConnection{
+		fs: fs,
+	}
+}
+
+// SetRootFolder sets the destination folder.
+func (s *LocalDirConnection) SetRootFolder(name string) {
+	s.serverFolder = name
+}
+
+// SetLogger sets the logger used by the local folder connection.
+func (s *LocalDirConnection) SetLogger(logger *yinlog.Logger) {
+	s.logger = logger
+}
+
+// Dial contains the logic for the local folder receiver to handle the dial command.
+// It checks the parent of the destination folder exists, e.g. the mount point.
+func (s *LocalDirConnection) Dial() error {
+	s.logger.Infof("Using the local folder (%s) ", s.serverFolder)
+	parentDir := filepath.Dir(filepath.Clean(s.serverFolder))
+	exists, err := afero.DirExists(s.fs, parentDir)
+	if err != nil {
+		return err
+	}
+	if !exists {
+		return fmt.Errorf("the parent folder '%s' does not exist", parentDir)
+	}
+	return nil
+}
+
+// Login contains the logic for the local folder receiver to handle the login command.
+func (s *LocalDirConnection) Login() error {
+	return nil
+}
+
+// Logout contains the logic for the local folder receiver to handle the logout command.
+func (s *LocalDirConnection) Logout() error {
+	return nil
+}
+
+// Idle contains the logic for the local folder receiver to handle the no-operation (idle) command.
+func (s *LocalDirConnection) Idle() error {
+	return nil
+}
+
+// MakeDirs contains the logic for the local folder receiver to handle the make dirs command.
+func (s *LocalDirConnection) MakeDirs(folders []string, dryRun bool) error {
+	sort.Strings(folders)
+	if !dryRun {
+		if err := s.makeDir(""); err != nil {
+			return err
+		}
+	}
+
+	pbConfig := &progressbar.Config{
+		Items:          folders,
+		OnCompletesMsg: fmt.Sprintf("Done! %d folders created", len(folders)),
+		OnProgressCmd: func(path string) tea.Cmd {
+			return localMkDirTeaCmd(s, path, dryRun)
+		},
+	}
+
+	if err := runProgressBar(opMakeDirs, pbConfig); err != nil {
+		return err
+	}
+	return nil
+}
+
+// UploadFiles contains the logic for the local folder receiver to handle the upload files command.
+func (s *LocalDirConnection) UploadFiles(appFs afero.Fs, localDir string, files []string, replaceBasePath, dryRun bool) error {
+	sort.Strings(files)
+
+	pbConfig := &progressbar.Config{
+		Items:          files,
+		OnCompletesMsg: fmt.Sprintf("Done! %d files copied", len(files)),
+		OnProgressCmd: func(path string) tea.Cmd {
+			return localCopyFileTeaCmd(s, appFs, path, localDir, replaceBasePath, dryRun)
+		},
+	}
+
+	if err := runProgressBar(opUpload, pbConfig); err != nil {
+		return err
+	}
+	return nil
+}
+
+// DeleteAll contains the logic for the local folder receiver to handle the delete all command.
+// The entries matching the gitignore-style exclude rules are kept.
+func (s *LocalDirConnection) DeleteAll(exclude []string, dryrun bool) error {
+	exists, err := afero.DirExists(s.fs, s.serverFolder)
+	if err != nil || !exists {
+		return err
+	}
+	entries, err := afero.ReadDir(s.fs, s.serverFolder)
+	if err != nil {
+		return err
+	}
+	if len(entries) == 0 {
+		return nil
+	}
+
+	s.logger.Important("Deleting previous content from the local folder")
+	cleaner := &remoteCleaner{
+		list: func(dir string) ([]remoteEntry, error) {
+			entries, err := afero.ReadDir(s.fs, filepath.Join(s.serverFolder, dir))
+			if err != nil {
+				return nil, err
+			}
+			list := []remoteEntry{}
+			for _, entry := range entries {
+				if s.isHidden(filepath.Join(dir, entry.Name())) {
+					continue
+				}
+				list = append(list, remoteEntry{name: entry.Name(), isDir: entry.IsDir()})
+			}
+			return list, nil
+		},
+		removeFile: func(name string) error {
+			return s.fs.Remove(filepath.Join(s.serverFolder, name))
+		},
+		removeDir: func(name string) error {
+			return s.fs.Remove(filepath.Join(s.serverFolder, name))
+		},
+		removeDirRecur: func(name string) error {
+			return s.fs.RemoveAll(filepath.Join(s.serverFolder, name))
+		},
+	}
+	_, err = cleaner.cleanDir(".", NewExcludeRules(exclude), dryrun)
+	return err
+}
+
+// DoBackup contains the logic for the local folder receiver to handle the backup command.
+func (s *LocalDirConnection) DoBackup(appFs afero.Fs, tarballFilePath string, dryRun bool) error {
+	archiveFilename := tarballFilePath + "_" + time.Now().Format("20060102_3:4:5PM") + ".tar.gz"
+	s.logger.Infof("Reading the local folder: %s", s.serverFolder)
+	files, err := s.walkFiles()
+	if err != nil {
+		return err
+	}
+
+	if !dryRun {
+		if len(files) > 0 {
+			if err := s.createTarball(appFs, archiveFilename, files, dryRun); err != nil {
+				return err
+			}
+		} else {
+			s.logger.Important("Nothing to backup in the folder!")
+		}
+	}
+	return nil
+}
+
+// ReadFile contains the logic for the local folder receiver to retrieve a file.
+func (s *LocalDirConnection) ReadFile(filename string) ([]byte, error) {
+	return afero.ReadFile(s.fs, filepath.Join(s.serverFolder, filename))
+}
+
+// WriteFile contains the logic for the local folder receiver to store a file.
+func (s *LocalDirConnection) WriteFile(filename string, data []byte, dryRun bool) error {
+	if dryRun {
+		return nil
+	}
+	return s.writeSingle(filename, data)
+}
+
+// DeleteFiles contains the logic for the local folder receiver to handle the delete files command.
+func (s *LocalDirConnection) DeleteFiles(files []string, dryRun bool) error {
+	if len(files) == 0 {
+		return nil
+	}
+	sort.Strings(files)
+
+	pbConfig := &progressbar.Config{
+		Items:          files,
+		OnCompletesMsg: fmt.Sprintf("Done! %d files deleted", len(files)),
+		OnProgressCmd: func(path string) tea.Cmd {
+			return localDeleteFileTeaCmd(s, path, dryRun)
+		},
+	}
+
+	if err := runProgressBar(deleteOperation(dryRun), pbConfig); err != nil {
+		return err
+	}
+	return nil
+}
+
+// Rename contains the logic for the local folder receiver to handle the rename command.
+func (s *LocalDirConnection) Rename(oldPath, newPath string, dryRun bool) error {
+	s.logger.Infof("Renaming %s to %s", oldPath, newPath)
+	if dryRun {
+		return nil
+	}
+	return s.fs.Rename(oldPath, newPath)
+}
+
+// RemoveDir contains the logic for the local folder receiver to handle the remove dir command.
+// It does nothing when the folder does not exist.
+func (s *LocalDirConnection) RemoveDir(dirname string, dryRun bool) error {
+	if dryRun || !s.dirExists(dirname) {
+		return nil
+	}
+	s.logger.Infof("Deleting the folder %s", dirname)
+	return s.fs.RemoveAll(dirname)
+}
+
+// Swap contains the logic for the local folder receiver to handle the swap command.
+// The root folder is renamed as previousFolder and the stagingFolder takes its place.
+func (s *LocalDirConnection) Swap(stagingFolder, previousFolder string, dryRun bool) error {
+	s.logger.Infof("Swapping %s with %s", stagingFolder, s.serverFolder)
+	if dryRun {
+		return nil
+	}
+
+	if err := s.RemoveDir(previousFolder, dryRun); err != nil {
+		return err
+	}
+	if s.dirExists(s.serverFolder) {
+		if err := s.fs.Rename(s.serverFolder, previousFolder); err != nil {
+			return err
+		}
+	}
+	return s.fs.Rename(stagingFolder, s.serverFolder)
+}
+
+// ListFiles returns the files within the local folder.
+func (s *LocalDirConnection) ListFiles() ([]RemoteFile, error) {
+	files := []RemoteFile{}
+	if !s.dirExists(s.serverFolder) {
+		return files, nil
+	}
+	err := afero.Walk(s.fs, s.serverFolder, func(path string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+		if s.isHidden(utils.ToBasePath(path, s.serverFolder)) {
+			return skipHidden(info)
+		}
+		if info.Mode().IsRegular() {
+			files = append(files, RemoteFile{
+				Path:    utils.ToBasePath(path, s.serverFolder),
+				Size:    info.Size(),
+				ModTime: info.ModTime(),
+			})
+		}
+		return nil
+	})
+	sortRemoteFiles(files)
+	return files, err
+}
+
+//=============================================================================
+
+func (s *LocalDirConnection) dirExists(dirname string) bool {
+	exists, err := afero.DirExists(s.fs, dirname)
+	return err == nil && exists
+}
+
+// walkFiles returns the files within the root folder, relative to it.
+func (s *LocalDirConnection) walkFiles() ([]string, error) {
+	files := []string{}
+	if !s.dirExists(s.serverFolder) {
+		return files, nil
+	}
+	err := afero.Walk(s.fs, s.serverFolder, func(path string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+		if s.isHidden(utils.ToBasePath(path, s.serverFolder)) {
+			return skipHidden(info)
+		}
+		if info.Mode().IsRegular() {
+			files = append(files, utils.ToBasePath(path, s.serverFolder))
+		}
+		return nil
+	})
+	sort.Strings(files)
+	return files, err
+}
+
+// isHidden reports whether the path, relative to the root folder, is one of the hidden entries.
+func (s *LocalDirConnection) isHidden(relPath string) bool {
+	for _, name := range s.hidden {
+		if filepath.Clean(relPath) == name {
+			return true
+		}
+	}
+	return false
+}
+
+// skipHidden makes afero.Walk skip a hidden entry and, for a folder, its content.
+func skipHidden(info os.FileInfo) error {
+	if info.IsDir() {
+		return filepath.SkipDir
+	}
+	return nil
+}
+
+func (s *LocalDirConnection) makeDir(dirname string) error {
+	return s.fs.MkdirAll(filepath.Join(s.serverFolder, dirname), os.ModePerm)
+}
+
+// copyLocalFile reads the file from appFs and copies it to the destination folder.
+func (s *LocalDirConnection) copyLocalFile(appFs afero.Fs, file, localDir string, replaceBasePath bool) error {
+	fileBytes, err := afero.ReadFile(appFs, file)
+	if err != nil {
+		return err
+	}
+	destFile := file
+	if replaceBasePath {
+		destFile = utils.ToBasePath(file, localDir)
+	}
+	if err := s.writeSingle(destFile, fileBytes); err != nil {
+		return err
+	}
+	trackBytes(len(fileBytes))
+	return nil
+}
+
+func (s *LocalDirConnection) writeSingle(filename string, data []byte) error {
+	saveAs := filepath.Join(s.serverFolder, filename)
+	if err := s.fs.MkdirAll(filepath.Dir(saveAs), os.ModePerm); err != nil {
+		return err
+	}
+	return afero.WriteFile(s.fs, saveAs, data, 0644)
+}
+
+func (s *LocalDirConnection) createTarball(appFs afero.Fs, tarballFilePath string, filePaths []string, dryRun bool) error {
+	s.logger.Info("Creating the backup archive...")
+	// files are added to the archive with paths relative to the root folder
+	rootFs := afero.NewBasePathFs(s.fs, s.serverFolder)
+	// Create a new archive file
+	file, err := appFs.Create(tarballFilePath)
+	if err != nil {
+		return fmt.Errorf("could not create tarball file '%s', got error '%s'", tarballFilePath, err.Error())
+	}
+	defer file.Close()
+
+	gzipWriter := gzip.NewWriter(file)
+	defer gzipWriter.Close()
+
+	tarWriter := tar.NewWriter(gzipWriter)
+	defer tarWriter.Close()
+
+	pbConfig := &progressbar.Config{
+		Items:          filePaths,
+		OnCompletesMsg: fmt.Sprintf("Backup done! Saved as: %s", tarballFilePath),
+		OnProgressCmd: func(path string) tea.Cmd {
+			return localCreateTarballTeaCmd(rootFs, tarWriter, path, dryRun)
+		},
+	}
+
+	if err := runProgressBar(opBackup, pbConfig); err != nil {
+		return err
+	}
+
+	return nil
+}
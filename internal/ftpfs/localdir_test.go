@@ -0,0 +1,89 @@
+package ftpfs
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/matryer/is"
+	"github.com/spf13/afero"
+	"github.com/sveltinio/yinlog"
+)
+
+func newTestLocalDirConnection(fs afero.Fs, folder string) *LocalDirConnection {
+	conn := NewLocalDirConnection(fs)
+	conn.SetRootFolder(folder)
+	conn.SetLogger(yinlog.New())
+	return conn
+}
+
+func TestLocalDirDial(t *testing.T) {
+	is := is.New(t)
+	fs := afero.NewMemMapFs()
+	is.NoErr(fs.MkdirAll("/srv/www", 0755))
+
+	is.NoErr(DialAction(newTestLocalDirConnection(fs, "/srv/www/site")).Run())
+	// the mount point must exist
+	is.True(DialAction(newTestLocalDirConnection(fs, "/mnt/nfs/site")).Run() != nil)
+}
+
+func TestLocalDirOperations(t *testing.T) {
+	is := is.New(t)
+	fs := afero.NewMemMapFs()
+	is.NoErr(afero.WriteFile(fs, "build/index.html", []byte("<h1>home</h1>"), 0644))
+	is.NoErr(afero.WriteFile(fs, "build/posts/first/index.html", []byte("<h1>first</h1>"), 0644))
+
+	conn := newTestLocalDirConnection(fs, "/srv/www/site")
+	is.NoErr(conn.makeDir(""))
+	is.NoErr(conn.copyLocalFile(fs, "build/index.html", "build", true))
+	is.NoErr(conn.copyLocalFile(fs, "build/posts/first/index.html", "build", true))
+	is.NoErr(WriteFileAction(conn, ".htaccess", []byte("Options -Indexes"), false).Run())
+	// dry-run must not write anything
+	is.NoErr(WriteFileAction(conn, "dryrun.html", []byte(""), true).Run())
+
+	files, err := conn.walkFiles()
+	is.NoErr(err)
+	is.Equal(files, []string{".htaccess", "index.html", "posts/first/index.html"})
+
+	content, err := conn.ReadFile("posts/first/index.html")
+	is.NoErr(err)
+	is.Equal(string(content), "<h1>first</h1>")
+
+	// dry-run must not delete anything
+	is.NoErr(DeleteAllAction(conn, []string{".htaccess"}, true).Run())
+	files, err = conn.walkFiles()
+	is.NoErr(err)
+	is.Equal(len(files), 3)
+
+	is.NoErr(DeleteAllAction(conn, []string{".htaccess"}, false).Run())
+	files, err = conn.walkFiles()
+	is.NoErr(err)
+	is.Equal(files, []string{".htaccess"})
+	is.True(!conn.dirExists("/srv/www/site/posts"))
+}
+
+func TestLocalDirSwap(t *testing.T) {
+	is := is.New(t)
+	// MemMapFs does not move the folder content on rename
+	fs := afero.NewOsFs()
+	liveFolder := filepath.Join(t.TempDir(), "site")
+	stagingFolder := liveFolder + ".staging"
+	previousFolder := liveFolder + ".previous"
+
+	conn := newTestLocalDirConnection(fs, stagingFolder)
+	is.NoErr(conn.WriteFile("index.html", []byte("v1"), false))
+	conn.SetRootFolder(liveFolder)
+	is.NoErr(SwapAction(conn, stagingFolder, previousFolder, false).Run())
+
+	conn.SetRootFolder(stagingFolder)
+	is.NoErr(conn.WriteFile("index.html", []byte("v2"), false))
+	conn.SetRootFolder(liveFolder)
+	is.NoErr(SwapAction(conn, stagingFolder, previousFolder, false).Run())
+
+	content, err := conn.ReadFile("index.html")
+	is.NoErr(err)
+	is.Equal(string(content), "v2")
+	content, err = afero.ReadFile(fs, previousFolder+"/index.html")
+	is.NoErr(err)
+	is.Equal(string(content), "v1")
+	is.True(!conn.dirExists(stagingFolder))
+}
@@ -0,0 +1,201 @@
+/**
+ * Copyright © 2021-present Sveltin contributors <github@sveltin.io>
+ *
+ * Use of this source code is governed by Apache 2.0 license
+ * that can be found in the LICENSE file.
+ */
+
+package ftpfs
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"io"
+	"path/filepath"
+	"sort"
+
+	"github.com/spf13/afero"
+	"github.com/sveltinio/sveltin/utils"
+)
+
+// ManifestFilename is the name of the manifest file saved on the remote folder.
+const ManifestFilename string = ".sveltin-manifest.json"
+
+// ManifestEntry is the struct representing a single file in the manifest.
+type ManifestEntry struct {
+	Size int64  `json:"size"`
+	Hash string `json:"sha256"`
+}
+
+// Manifest is the struct mapping the remote file paths to their size and SHA-256 checksum.
+type Manifest struct {
+	Files      map[string]ManifestEntry `json:"files"`
+	localPaths map[string]string
+}
+
+// ManifestDiff is the struct with the remote file paths grouped by the action to be performed.
+type ManifestDiff struct {
+	Added     []string
+	Changed   []string
+	Removed   []string
+	Unchanged []string
+}
+
+// NewManifest returns a new empty Manifest struct.
+func NewManifest() *Manifest {
+	return &Manifest{
+		Files:      map[string]ManifestEntry{},
+		localPaths: map[string]string{},
+	}
+}
+
+// ParseManifest returns a Manifest struct from its JSON representation.
+func ParseManifest(data []byte) (*Manifest, error) {
+	m := NewManifest()
+	if err := json.Unmarshal(data, m); err != nil {
+		return nil, err
+	}
+	if m.Files == nil {
+		m.Files = map[string]ManifestEntry{}
+	}
+	return m, nil
+}
+
+// FetchManifest reads the manifest file from the remote folder.
+func FetchManifest(server RemoteServer) (*Manifest, error) {
+	data, err := server.ReadFile(ManifestFilename)
+	if err != nil {
+		return nil, err
+	}
+	return ParseManifest(data)
+}
+
+// Bytes returns the JSON representation of the manifest.
+func (m *Manifest) Bytes() ([]byte, error) {
+	return json.MarshalIndent(m, "", "  ")
+}
+
+// Checksum returns the SHA-256 checksum of the manifest, identifying the build it describes.
+func (m *Manifest) Checksum() (string, error) {
+	data, err := m.Bytes()
+	if err != nil {
+		return "", err
+	}
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:]), nil
+}
+
+// AddFiles computes size and checksum for the local files and adds them to the manifest.
+// The remote path is computed the same way UploadFiles does.
+func (m *Manifest) AddFiles(appFs afero.Fs, localDir string, files []string, replaceBasePath bool) error {
+	for _, file := range files {
+		entry, err := newManifestEntry(appFs, file)
+		if err != nil {
+			return err
+		}
+		remoteFile := file
+		if replaceBasePath {
+			remoteFile = utils.ToBasePath(file, localDir)
+		}
+		m.Files[remoteFile] = *entry
+		m.localPaths[remoteFile] = file
+	}
+	return nil
+}
+
+// LocalFiles returns the local file paths for the given remote ones.
+func (m *Manifest) LocalFiles(remoteFiles []string) []string {
+	files := []string{}
+	for _, remoteFile := range remoteFiles {
+		if localFile, exists := m.localPaths[remoteFile]; exists {
+			files = append(files, localFile)
+		}
+	}
+	sort.Strings(files)
+	return files
+}
+
+// Diff compares the manifest against the previous one and returns the list
+// of files to be uploaded (added and changed) and deleted (removed).
+func (m *Manifest) Diff(previous *Manifest) *ManifestDiff {
+	diff := &ManifestDiff{}
+	for file, entry := range m.Files {
+		prevEntry, exists := previous.Files[file]
+		switch {
+		case !exists:
+			diff.Added = append(diff.Added, file)
+		case prevEntry != entry:
+			diff.Changed = append(diff.Changed, file)
+		default:
+			diff.Unchanged = append(diff.Unchanged, file)
+		}
+	}
+	for file := range previous.Files {
+		if _, exists := m.Files[file]; !exists {
+			diff.Removed = append(diff.Removed, file)
+		}
+	}
+
+	sort.Strings(diff.Added)
+	sort.Strings(diff.Changed)
+	sort.Strings(diff.Removed)
+	sort.Strings(diff.Unchanged)
+	return diff
+}
+
+// MissingDirs returns the sorted list of folders needed by the files but
+// not existing on the remote folder as described by the previous manifest.
+func MissingDirs(files []string, previous *Manifest) []string {
+	existing := map[string]bool{}
+	for file := range previous.Files {
+		for _, dir := range parentDirs(file) {
+			existing[dir] = true
+		}
+	}
+
+	missing := map[string]bool{}
+	for _, file := range files {
+		for _, dir := range parentDirs(file) {
+			if !existing[dir] {
+				missing[dir] = true
+			}
+		}
+	}
+
+	dirs := make([]string, 0, len(missing))
+	for dir := range missing {
+		dirs = append(dirs, dir)
+	}
+	sort.Strings(dirs)
+	return dirs
+}
+
+//=============================================================================
+
+func newManifestEntry(appFs afero.Fs, file string) (*ManifestEntry, error) {
+	f, err := appFs.Open(file)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	h := sha256.New()
+	size, err := io.Copy(h, f)
+	if err != nil {
+		return nil, err
+	}
+	return &ManifestEntry{
+		Size: size,
+		Hash: hex.EncodeToString(h.Sum(nil)),
+	}, nil
+}
+
+// parentDirs returns all the parent folders for a file path, e.g. a/b/c.html -> [a, a/b].
+func parentDirs(file string) []string {
+	dirs := []string{}
+	for dir := filepath.Dir(file); dir != "." && dir != "/" && dir != ""; dir = filepath.Dir(dir) {
+		dirs = append([]string{dir}, dirs...)
+	}
+	return dirs
+}
@@ -0,0 +1,54 @@
+package ftpfs
+
+import (
+	"testing"
+
+	"github.com/matryer/is"
+	"github.com/spf13/afero"
+)
+
+func TestManifest(t *testing.T) {
+	is := is.New(t)
+	memFs := afero.NewMemMapFs()
+
+	is.NoErr(afero.WriteFile(memFs, "build/index.html", []byte("<h1>home</h1>"), 0644))
+	is.NoErr(afero.WriteFile(memFs, "build/about/index.html", []byte("<h1>about</h1>"), 0644))
+	is.NoErr(afero.WriteFile(memFs, "static/logo.svg", []byte("<svg/>"), 0644))
+
+	current := NewManifest()
+	is.NoErr(current.AddFiles(memFs, "build", []string{"build/index.html", "build/about/index.html"}, true))
+	is.NoErr(current.AddFiles(memFs, "static", []string{"static/logo.svg"}, false))
+
+	is.Equal(len(current.Files), 3)
+	is.Equal(current.Files["index.html"].Size, int64(13))
+	is.Equal(current.Files["static/logo.svg"].Hash, "d4dc56669143034f31aa309635d4113d9ad76a02b1739da22c965ed2049be9e6")
+	is.Equal(current.LocalFiles([]string{"about/index.html", "static/logo.svg", "missing.html"}),
+		[]string{"build/about/index.html", "static/logo.svg"})
+
+	data, err := current.Bytes()
+	is.NoErr(err)
+	parsed, err := ParseManifest(data)
+	is.NoErr(err)
+	is.Equal(parsed.Files, current.Files)
+
+	previous := NewManifest()
+	previous.Files["index.html"] = current.Files["index.html"]
+	previous.Files["static/logo.svg"] = ManifestEntry{Size: 6, Hash: "outdated"}
+	previous.Files["posts/old/index.html"] = ManifestEntry{Size: 10, Hash: "removed"}
+
+	diff := current.Diff(previous)
+	is.Equal(diff.Added, []string{"about/index.html"})
+	is.Equal(diff.Changed, []string{"static/logo.svg"})
+	is.Equal(diff.Removed, []string{"posts/old/index.html"})
+	is.Equal(diff.Unchanged, []string{"index.html"})
+}
+
+func TestMissingDirs(t *testing.T) {
+	is := is.New(t)
+
+	previous := NewManifest()
+	previous.Files["posts/first/index.html"] = ManifestEntry{}
+
+	dirs := MissingDirs([]string{"index.html", "posts/second/index.html", "_app/immutable/chunks/x.js"}, previous)
+	is.Equal(dirs, []string{"_app", "_app/immutable", "_app/immutable/chunks", "posts/second"})
+}
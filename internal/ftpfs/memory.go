@@ -0,0 +1,164 @@
+/**
+ * Copyright © 2021-present Sveltin contributors <github@sveltin.io>
+ *
+ * Use of this source code is governed by Apache 2.0 license
+ * that can be found in the LICENSE file.
+ */
+
+package ftpfs
+
+import (
+	"sync"
+
+	"github.com/spf13/afero"
+)
+
+// MemOperation is an operation performed on a MemServerConnection, named after the
+// RemoteServer method in lower camel case (e.g. "uploadFiles"), with its arguments.
+type MemOperation struct {
+	Name   string
+	Args   []string
+	DryRun bool
+}
+
+// MemServerConnection is an in-memory RemoteServer backed by an afero.MemMapFs, e.g. to test
+// the deploy without a real server. The remote folder is handled as a local folder within Fs
+// and every operation is recorded, in order, in Operations.
+type MemServerConnection struct {
+	*LocalDirConnection
+	Fs         afero.Fs
+	Operations []MemOperation
+	mu         sync.Mutex
+}
+
+// NewMemServerConnection returns a new MemServerConnection struct with an empty file system.
+func NewMemServerConnection() *MemServerConnection {
+	fs := afero.NewMemMapFs()
+	return &MemServerConnection{
+		LocalDirConnection: NewLocalDirConnection(fs),
+		Fs:                 fs,
+	}
+}
+
+// Recorded returns the recorded operations with the given name, e.g. "uploadFiles".
+func (s *MemServerConnection) Recorded(name string) []MemOperation {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	operations := []MemOperation{}
+	for _, op := range s.Operations {
+		if op.Name == name {
+			operations = append(operations, op)
+		}
+	}
+	return operations
+}
+
+// SetRootFolder sets the remote folder.
+func (s *MemServerConnection) SetRootFolder(name string) {
+	s.record("setRootFolder", false, name)
+	s.LocalDirConnection.SetRootFolder(name)
+}
+
+// Dial contains the logic for the in-memory receiver to handle the dial command.
+func (s *MemServerConnection) Dial() error {
+	s.record("dial", false)
+	return nil
+}
+
+// Login contains the logic for the in-memory receiver to handle the login command.
+func (s *MemServerConnection) Login() error {
+	s.record("login", false)
+	return nil
+}
+
+// Logout contains the logic for the in-memory receiver to handle the logout command.
+func (s *MemServerConnection) Logout() error {
+	s.record("logout", false)
+	return nil
+}
+
+// Idle contains the logic for the in-memory receiver to handle the no-operation (idle) command.
+func (s *MemServerConnection) Idle() error {
+	s.record("idle", false)
+	return nil
+}
+
+// MakeDirs contains the logic for the in-memory receiver to handle the make dirs command.
+func (s *MemServerConnection) MakeDirs(folders []string, dryRun bool) error {
+	s.record("makeDirs", dryRun, folders...)
+	return s.LocalDirConnection.MakeDirs(folders, dryRun)
+}
+
+// UploadFiles contains the logic for the in-memory receiver to handle the upload files command.
+// The recorded arguments are the local paths of the files.
+func (s *MemServerConnection) UploadFiles(appFs afero.Fs, localDir string, files []string, replaceBasePath, dryRun bool) error {
+	s.record("uploadFiles", dryRun, files...)
+	return s.LocalDirConnection.UploadFiles(appFs, localDir, files, replaceBasePath, dryRun)
+}
+
+// DeleteAll contains the logic for the in-memory receiver to handle the delete all command.
+// The recorded arguments are the exclude rules.
+func (s *MemServerConnection) DeleteAll(exclude []string, dryRun bool) error {
+	s.record("deleteAll", dryRun, exclude...)
+	return s.LocalDirConnection.DeleteAll(exclude, dryRun)
+}
+
+// DoBackup contains the logic for the in-memory receiver to handle the backup command.
+func (s *MemServerConnection) DoBackup(appFs afero.Fs, tarballFilePath string, dryRun bool) error {
+	s.record("doBackup", dryRun, tarballFilePath)
+	return s.LocalDirConnection.DoBackup(appFs, tarballFilePath, dryRun)
+}
+
+// ReadFile contains the logic for the in-memory receiver to retrieve a file.
+func (s *MemServerConnection) ReadFile(filename string) ([]byte, error) {
+	s.record("readFile", false, filename)
+	return s.LocalDirConnection.ReadFile(filename)
+}
+
+// WriteFile contains the logic for the in-memory receiver to store a file.
+func (s *MemServerConnection) WriteFile(filename string, data []byte, dryRun bool) error {
+	s.record("writeFile", dryRun, filename)
+	return s.LocalDirConnection.WriteFile(filename, data, dryRun)
+}
+
+// DeleteFiles contains the logic for the in-memory receiver to handle the delete files command.
+func (s *MemServerConnection) DeleteFiles(files []string, dryRun bool) error {
+	s.record("deleteFiles", dryRun, files...)
+	return s.LocalDirConnection.DeleteFiles(files, dryRun)
+}
+
+// Rename contains the logic for the in-memory receiver to handle the rename command.
+func (s *MemServerConnection) Rename(oldPath, newPath string, dryRun bool) error {
+	s.record("rename", dryRun, oldPath, newPath)
+	return s.LocalDirConnection.Rename(oldPath, newPath, dryRun)
+}
+
+// RemoveDir contains the logic for the in-memory receiver to handle the remove dir command.
+func (s *MemServerConnection) RemoveDir(dirname string, dryRun bool) error {
+	s.record("removeDir", dryRun, dirname)
+	return s.LocalDirConnection.RemoveDir(dirname, dryRun)
+}
+
+// Swap contains the logic for the in-memory receiver to handle the swap command.
+func (s *MemServerConnection) Swap(stagingFolder, previousFolder string, dryRun bool) error {
+	s.record("swap", dryRun, stagingFolder, previousFolder)
+	return s.LocalDirConnection.Swap(stagingFolder, previousFolder, dryRun)
+}
+
+// ListFiles returns the files within the remote folder.
+func (s *MemServerConnection) ListFiles() ([]RemoteFile, error) {
+	s.record("listFiles", false)
+	return s.LocalDirConnection.ListFiles()
+}
+
+//=============================================================================
+
+func (s *MemServerConnection) record(name string, dryRun bool, args ...string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.Operations = append(s.Operations, MemOperation{
+		Name:   name,
+		Args:   append([]string{}, args...),
+		DryRun: dryRun,
+	})
+}
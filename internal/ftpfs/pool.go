@@ -0,0 +1,133 @@
+/**
+ * Copyright © 2021-present Sveltin contributors <github@sveltin.io>
+ *
+ * Use of this source code is governed by Apache 2.0 license
+ * that can be found in the LICENSE file.
+ */
+
+package ftpfs
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/sveltinio/prompti/progressbar"
+)
+
+// UploadError is the error collecting the per-file errors occurred while uploading files in parallel.
+type UploadError struct {
+	Files map[string]error
+	mu    sync.Mutex
+}
+
+func (e *UploadError) Error() string {
+	files := make([]string, 0, len(e.Files))
+	for file := range e.Files {
+		files = append(files, file)
+	}
+	sort.Strings(files)
+
+	lines := []string{fmt.Sprintf("%d files failed to upload:", len(files))}
+	for _, file := range files {
+		lines = append(lines, fmt.Sprintf("  %s: %s", file, e.Files[file].Error()))
+	}
+	return strings.Join(lines, "\n")
+}
+
+func (e *UploadError) add(file string, err error) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	e.Files[file] = err
+}
+
+// fileResult is the outcome of a single file operation executed by a worker.
+type fileResult struct {
+	file string
+	err  error
+}
+
+// worker is the function executed for every file taken from the work queue.
+type worker func(file string) error
+
+// startWorkers shares the files queue among the workers and returns the channel
+// receiving one result per file. The channel is closed once all the files are done.
+func startWorkers(files []string, workers []worker) <-chan fileResult {
+	queue := make(chan string, len(files))
+	for _, file := range files {
+		queue <- file
+	}
+	close(queue)
+
+	results := make(chan fileResult, len(files))
+	var wg sync.WaitGroup
+	for _, w := range workers {
+		wg.Add(1)
+		go func(w worker) {
+			defer wg.Done()
+			for file := range queue {
+				results <- fileResult{file: file, err: w(file)}
+			}
+		}(w)
+	}
+	go func() {
+		wg.Wait()
+		close(results)
+	}()
+
+	return results
+}
+
+// uploadInParallel uploads the files with the given workers, keeping the progress bar
+// updated as the uploads complete. Per-file errors do not stop the other uploads and are
+// returned all together as UploadError.
+func uploadInParallel(files []string, workers []worker) error {
+	results := startWorkers(files, workers)
+	uploadErr := &UploadError{Files: map[string]error{}}
+
+	pbConfig := &progressbar.Config{
+		Items:          files,
+		OnCompletesMsg: fmt.Sprintf("Done! %d files uploaded with %d connections", len(files), len(workers)),
+		OnProgressCmd: func(string) tea.Cmd {
+			return func() tea.Msg {
+				result, ok := <-results
+				if ok {
+					trackItem(opUpload, result.file, result.err)
+					if result.err != nil {
+						uploadErr.add(result.file, result.err)
+					}
+				}
+				return progressbar.IncrementMsg(result.file)
+			}
+		},
+	}
+	err := runProgressBar(opParallelUpload, pbConfig)
+
+	// wait for the uploads still running, e.g. when the progress bar has been interrupted
+	for result := range results {
+		trackItem(opUpload, result.file, result.err)
+		if result.err != nil {
+			uploadErr.add(result.file, result.err)
+		}
+	}
+	if err != nil {
+		return err
+	}
+	if len(uploadErr.Files) > 0 {
+		return uploadErr
+	}
+	return nil
+}
+
+// numOfWorkers returns the number of workers to be used for the files.
+func numOfWorkers(parallel, numOfFiles int) int {
+	if parallel > numOfFiles {
+		return numOfFiles
+	}
+	if parallel < 1 {
+		return 1
+	}
+	return parallel
+}
@@ -0,0 +1,55 @@
+package ftpfs
+
+import (
+	"errors"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/matryer/is"
+)
+
+func TestStartWorkers(t *testing.T) {
+	is := is.New(t)
+
+	files := []string{"a.html", "b.html", "c.html", "d.html", "e.html"}
+	var mu sync.Mutex
+	done := map[string]int{}
+	workers := []worker{}
+	for i := 0; i < numOfWorkers(3, len(files)); i++ {
+		workers = append(workers, func(file string) error {
+			mu.Lock()
+			done[file]++
+			mu.Unlock()
+			if file == "c.html" {
+				return errors.New("550 permission denied")
+			}
+			return nil
+		})
+	}
+	is.Equal(len(workers), 3)
+
+	uploadErr := &UploadError{Files: map[string]error{}}
+	count := 0
+	for result := range startWorkers(files, workers) {
+		count++
+		if result.err != nil {
+			uploadErr.add(result.file, result.err)
+		}
+	}
+
+	is.Equal(count, len(files))
+	for _, file := range files {
+		is.Equal(done[file], 1) // every file is processed exactly once
+	}
+	is.Equal(len(uploadErr.Files), 1)
+	is.True(strings.Contains(uploadErr.Error(), "c.html: 550 permission denied"))
+}
+
+func TestNumOfWorkers(t *testing.T) {
+	is := is.New(t)
+
+	is.Equal(numOfWorkers(4, 10), 4)
+	is.Equal(numOfWorkers(4, 2), 2)
+	is.Equal(numOfWorkers(0, 10), 1)
+}
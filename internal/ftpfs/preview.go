@@ -0,0 +1,203 @@
+/**
+ * Copyright © 2021-present Sveltin contributors <github@sveltin.io>
+ *
+ * Use of this source code is governed by Apache 2.0 license
+ * that can be found in the LICENSE file.
+ */
+
+package ftpfs
+
+import (
+	"sort"
+	"strings"
+)
+
+// Changes a deploy makes to a remote file.
+const (
+	ChangeAdded       string = "added"
+	ChangeOverwritten string = "overwritten"
+	ChangeDeleted     string = "deleted"
+	ChangePreserved   string = "preserved"
+	ChangeUnchanged   string = "unchanged"
+)
+
+// PreviewEntry is a remote file with the change the deploy makes to it. The size is the one
+// of the local file for an added or overwritten file, the one of the remote file otherwise.
+type PreviewEntry struct {
+	Path   string `json:"path"`
+	Change string `json:"change"`
+	Size   int64  `json:"size"`
+}
+
+// PreviewSummary is the number of files and their total size for a change.
+type PreviewSummary struct {
+	Files int   `json:"files"`
+	Bytes int64 `json:"bytes"`
+}
+
+// DeployPreview is the struct with the changes a deploy makes to the remote folder.
+type DeployPreview struct {
+	Entries []PreviewEntry            `json:"entries"`
+	Summary map[string]PreviewSummary `json:"summary"`
+}
+
+// PreviewNode is a line of the preview tree: a file or a folder. A folder whose files
+// have all the same change is collapsed, Files being their number. The change is empty
+// for a folder with mixed changes, its content following as deeper nodes.
+type PreviewNode struct {
+	Prefix string
+	Name   string
+	Change string
+	Files  int
+	Size   int64
+}
+
+// NewDeployPreview computes the changes a deploy of the local build makes to the remote files.
+// With a full deploy (previous is nil) every remote file is deleted unless matching the exclude
+// rules, and every local file is uploaded. With an incremental deploy, previous is the manifest
+// of the last deploy: only the changed files are uploaded and the removed ones deleted.
+// The manifest file is not listed.
+func NewDeployPreview(remoteFiles []RemoteFile, local *Manifest, previous *Manifest, rules *ExcludeRules) *DeployPreview {
+	preview := &DeployPreview{
+		Entries: []PreviewEntry{},
+		Summary: map[string]PreviewSummary{},
+	}
+	onRemote := map[string]bool{}
+	for _, remoteFile := range remoteFiles {
+		onRemote[remoteFile.Path] = true
+	}
+
+	for path, entry := range local.Files {
+		change := ChangeAdded
+		if previous != nil && previous.Files[path] == entry && onRemote[path] {
+			change = ChangeUnchanged
+		} else if onRemote[path] {
+			change = ChangeOverwritten
+		}
+		preview.add(PreviewEntry{Path: path, Change: change, Size: entry.Size})
+	}
+
+	for _, remoteFile := range remoteFiles {
+		if _, exists := local.Files[remoteFile.Path]; exists || remoteFile.Path == ManifestFilename {
+			continue
+		}
+		change := ChangeDeleted
+		if rules.Excluded(remoteFile.Path, false) {
+			change = ChangePreserved
+		} else if previous != nil {
+			// the incremental deploy only deletes the files it uploaded before
+			if _, deployed := previous.Files[remoteFile.Path]; !deployed {
+				change = ChangePreserved
+			}
+		}
+		preview.add(PreviewEntry{Path: remoteFile.Path, Change: change, Size: remoteFile.Size})
+	}
+
+	sort.Slice(preview.Entries, func(i, j int) bool {
+		return preview.Entries[i].Path < preview.Entries[j].Path
+	})
+	return preview
+}
+
+// Tree returns the preview as tree lines, the folders first.
+func (p *DeployPreview) Tree() []PreviewNode {
+	root := newPreviewDir()
+	for _, entry := range p.Entries {
+		root.insert(strings.Split(entry.Path, "/"), entry)
+	}
+	return root.render("")
+}
+
+func (p *DeployPreview) add(entry PreviewEntry) {
+	p.Entries = append(p.Entries, entry)
+	summary := p.Summary[entry.Change]
+	summary.Files++
+	summary.Bytes += entry.Size
+	p.Summary[entry.Change] = summary
+}
+
+//=============================================================================
+
+// previewDir is a folder of the preview tree.
+type previewDir struct {
+	dirs  map[string]*previewDir
+	files map[string]PreviewEntry
+}
+
+func newPreviewDir() *previewDir {
+	return &previewDir{dirs: map[string]*previewDir{}, files: map[string]PreviewEntry{}}
+}
+
+func (d *previewDir) insert(parts []string, entry PreviewEntry) {
+	if len(parts) == 1 {
+		d.files[parts[0]] = entry
+		return
+	}
+	child, exists := d.dirs[parts[0]]
+	if !exists {
+		child = newPreviewDir()
+		d.dirs[parts[0]] = child
+	}
+	child.insert(parts[1:], entry)
+}
+
+// summary returns the change shared by all the files within the folder, empty when mixed,
+// the number of files and their total size.
+func (d *previewDir) summary() (string, int, int64) {
+	change, files, size, isFirst := "", 0, int64(0), true
+	merge := func(c string) {
+		if isFirst {
+			change, isFirst = c, false
+		} else if change != c {
+			change = ""
+		}
+	}
+	for _, child := range d.dirs {
+		c, n, s := child.summary()
+		merge(c)
+		files += n
+		size += s
+	}
+	for _, entry := range d.files {
+		merge(entry.Change)
+		files++
+		size += entry.Size
+	}
+	return change, files, size
+}
+
+func (d *previewDir) render(prefix string) []PreviewNode {
+	dirNames := make([]string, 0, len(d.dirs))
+	for name := range d.dirs {
+		dirNames = append(dirNames, name)
+	}
+	sort.Strings(dirNames)
+	fileNames := make([]string, 0, len(d.files))
+	for name := range d.files {
+		fileNames = append(fileNames, name)
+	}
+	sort.Strings(fileNames)
+
+	nodes := []PreviewNode{}
+	count := len(dirNames) + len(fileNames)
+	branch := func(i int) (string, string) {
+		if i == count-1 {
+			return "└── ", "    "
+		}
+		return "├── ", "│   "
+	}
+	for i, name := range dirNames {
+		pointer, indent := branch(i)
+		change, files, size := d.dirs[name].summary()
+		nodes = append(nodes, PreviewNode{Prefix: prefix + pointer, Name: name + "/", Change: change, Files: files, Size: size})
+		if change == "" {
+			nodes = append(nodes, d.dirs[name].render(prefix+indent)...)
+		}
+	}
+	for i, name := range fileNames {
+		pointer, _ := branch(len(dirNames) + i)
+		entry := d.files[name]
+		nodes = append(nodes, PreviewNode{Prefix: prefix + pointer, Name: name, Change: entry.Change, Files: 1, Size: entry.Size})
+	}
+	return nodes
+}
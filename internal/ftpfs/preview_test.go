@@ -0,0 +1,55 @@
+package ftpfs
+
+import (
+	"testing"
+
+	"github.com/matryer/is"
+)
+
+func TestDeployPreview(t *testing.T) {
+	is := is.New(t)
+	local := NewManifest()
+	local.Files["index.html"] = ManifestEntry{Size: 10, Hash: "a"}
+	local.Files["posts/first/index.html"] = ManifestEntry{Size: 20, Hash: "b"}
+	local.Files["posts/second/index.html"] = ManifestEntry{Size: 30, Hash: "c"}
+	remoteFiles := []RemoteFile{
+		{Path: ".htaccess", Size: 5},
+		{Path: ManifestFilename, Size: 100},
+		{Path: "index.html", Size: 8},
+		{Path: "old/page.html", Size: 7},
+		{Path: "old/style.css", Size: 3},
+		{Path: "posts/first/index.html", Size: 20},
+	}
+	rules := NewExcludeRules([]string{".htaccess"})
+
+	preview := NewDeployPreview(remoteFiles, local, nil, rules)
+	is.Equal(preview.Entries, []PreviewEntry{
+		{Path: ".htaccess", Change: ChangePreserved, Size: 5},
+		{Path: "index.html", Change: ChangeOverwritten, Size: 10},
+		{Path: "old/page.html", Change: ChangeDeleted, Size: 7},
+		{Path: "old/style.css", Change: ChangeDeleted, Size: 3},
+		{Path: "posts/first/index.html", Change: ChangeOverwritten, Size: 20},
+		{Path: "posts/second/index.html", Change: ChangeAdded, Size: 30},
+	})
+	is.Equal(preview.Summary[ChangeDeleted], PreviewSummary{Files: 2, Bytes: 10})
+	is.Equal(preview.Summary[ChangeOverwritten], PreviewSummary{Files: 2, Bytes: 30})
+
+	// folders whose files have all the same change are collapsed
+	is.Equal(preview.Tree(), []PreviewNode{
+		{Prefix: "├── ", Name: "old/", Change: ChangeDeleted, Files: 2, Size: 10},
+		{Prefix: "├── ", Name: "posts/", Files: 2, Size: 50},
+		{Prefix: "│   ├── ", Name: "first/", Change: ChangeOverwritten, Files: 1, Size: 20},
+		{Prefix: "│   └── ", Name: "second/", Change: ChangeAdded, Files: 1, Size: 30},
+		{Prefix: "├── ", Name: ".htaccess", Change: ChangePreserved, Files: 1, Size: 5},
+		{Prefix: "└── ", Name: "index.html", Change: ChangeOverwritten, Files: 1, Size: 10},
+	})
+
+	// the incremental deploy skips the unchanged files and only deletes the ones it deployed
+	previous := NewManifest()
+	previous.Files["posts/first/index.html"] = ManifestEntry{Size: 20, Hash: "b"}
+	previous.Files["old/page.html"] = ManifestEntry{Size: 7, Hash: "d"}
+	preview = NewDeployPreview(remoteFiles, local, previous, rules)
+	is.Equal(preview.Summary[ChangeUnchanged], PreviewSummary{Files: 1, Bytes: 20})
+	is.Equal(preview.Summary[ChangeDeleted], PreviewSummary{Files: 1, Bytes: 7})
+	is.Equal(preview.Summary[ChangePreserved], PreviewSummary{Files: 2, Bytes: 8})
+}
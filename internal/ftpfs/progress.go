@@ -0,0 +1,279 @@
+/**
+ * Copyright © 2021-present Sveltin contributors <github@sveltin.io>
+ *
+ * Use of this source code is governed by Apache 2.0 license
+ * that can be found in the LICENSE file.
+ */
+
+package ftpfs
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"sync"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/sveltinio/prompti/progressbar"
+)
+
+// operation identifies the kind of items a progress bar runs over.
+type operation string
+
+const (
+	opMakeDirs       operation = "mkdir"
+	opUpload         operation = "upload"
+	opParallelUpload operation = "parallel upload"
+	opDelete         operation = "delete"
+	opPlannedDelete  operation = "planned delete"
+	opBackup         operation = "backup"
+)
+
+// FileError is the error returned by the remote server for a single file.
+type FileError struct {
+	Operation string `json:"operation"`
+	File      string `json:"file"`
+	Error     string `json:"error"`
+}
+
+// StepReport is the summary for a single step, e.g. an upload progress bar.
+type StepReport struct {
+	Operation  string `json:"operation"`
+	Items      int    `json:"items"`
+	DurationMs int64  `json:"durationMs"`
+}
+
+// Report is the machine-readable summary of the operations run on the remote server.
+type Report struct {
+	Target           string        `json:"target,omitempty"`
+	Environment      string        `json:"environment,omitempty"`
+	DryRun           bool          `json:"dryRun"`
+	Success          bool          `json:"success"`
+	Error            string        `json:"error,omitempty"`
+	StartedAt        time.Time     `json:"startedAt"`
+	DurationMs       int64         `json:"durationMs"`
+	FoldersCreated   []string      `json:"foldersCreated"`
+	FilesUploaded    []string      `json:"filesUploaded"`
+	FilesDeleted     []string      `json:"filesDeleted"`
+	PlannedDeletions []string      `json:"plannedDeletions"`
+	FilesSkipped     []string      `json:"filesSkipped"`
+	BytesTransferred int64         `json:"bytesTransferred"`
+	Steps            []StepReport  `json:"steps"`
+	Errors           []FileError   `json:"errors"`
+	Verification     *VerifyResult `json:"verification,omitempty"`
+	mu               sync.Mutex
+}
+
+// NewReport returns a new Report starting now.
+func NewReport() *Report {
+	return &Report{
+		StartedAt:        time.Now(),
+		FoldersCreated:   []string{},
+		FilesUploaded:    []string{},
+		FilesDeleted:     []string{},
+		PlannedDeletions: []string{},
+		FilesSkipped:     []string{},
+		Steps:            []StepReport{},
+		Errors:           []FileError{},
+	}
+}
+
+// AddSkipped records the files not transferred, e.g. unchanged since the previous deploy.
+func (r *Report) AddSkipped(files ...string) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.FilesSkipped = append(r.FilesSkipped, files...)
+}
+
+// Finish sets the total duration and the outcome for the report.
+func (r *Report) Finish(err error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.DurationMs = time.Since(r.StartedAt).Milliseconds()
+	r.Success = err == nil && len(r.Errors) == 0
+	if err != nil {
+		r.Error = err.Error()
+	}
+}
+
+// WriteJSON writes the report as indented JSON to w.
+func (r *Report) WriteJSON(w io.Writer) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+	return encoder.Encode(r)
+}
+
+func (r *Report) addItem(op operation, item string, err error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if err != nil {
+		r.Errors = append(r.Errors, FileError{Operation: string(op), File: item, Error: err.Error()})
+		return
+	}
+	switch op {
+	case opMakeDirs:
+		r.FoldersCreated = append(r.FoldersCreated, item)
+	case opUpload:
+		r.FilesUploaded = append(r.FilesUploaded, item)
+	case opDelete:
+		r.FilesDeleted = append(r.FilesDeleted, item)
+	case opPlannedDelete:
+		r.PlannedDeletions = append(r.PlannedDeletions, item)
+	}
+}
+
+func (r *Report) addStep(op operation, items int, duration time.Duration) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.Steps = append(r.Steps, StepReport{Operation: string(op), Items: items, DurationMs: duration.Milliseconds()})
+}
+
+func (r *Report) addBytes(n int) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.BytesTransferred += int64(n)
+}
+
+//=============================================================================
+
+// progress holds how the remote operations show their progress and where their outcome is recorded.
+var progress = struct {
+	mu         sync.RWMutex
+	plain      io.Writer
+	report     *Report
+	checkpoint *Checkpoint
+}{}
+
+// SetPlainProgress replaces the progress bars with plain lines written to w, e.g. when not running in a terminal.
+// A nil writer restores the progress bars.
+func SetPlainProgress(w io.Writer) {
+	progress.mu.Lock()
+	defer progress.mu.Unlock()
+	progress.plain = w
+}
+
+// SetReport sets the report recording the outcome of the remote operations. A nil report stops the recording.
+func SetReport(r *Report) {
+	progress.mu.Lock()
+	defer progress.mu.Unlock()
+	progress.report = r
+}
+
+// SetCheckpoint sets the checkpoint logging the folders created and the files uploaded.
+// A nil checkpoint stops the logging.
+func SetCheckpoint(c *Checkpoint) {
+	progress.mu.Lock()
+	defer progress.mu.Unlock()
+	progress.checkpoint = c
+}
+
+func currentCheckpoint() *Checkpoint {
+	progress.mu.RLock()
+	defer progress.mu.RUnlock()
+	return progress.checkpoint
+}
+
+func currentReport() *Report {
+	progress.mu.RLock()
+	defer progress.mu.RUnlock()
+	return progress.report
+}
+
+func plainProgressWriter() io.Writer {
+	progress.mu.RLock()
+	defer progress.mu.RUnlock()
+	return progress.plain
+}
+
+// trackItem records the outcome of the operation for a single item to the report and
+// the checkpoint, if set. The checkpoint is best effort: failing to write it only means
+// the item will be done again when resuming.
+func trackItem(op operation, item string, err error) {
+	if r := currentReport(); r != nil {
+		r.addItem(op, item, err)
+	}
+	if c := currentCheckpoint(); c != nil && err == nil && (op == opMakeDirs || op == opUpload) {
+		c.add(op, item)
+	}
+}
+
+// deleteOperation returns the operation for the deletions: on dry-run nothing is
+// deleted, the items are recorded as planned deletions.
+func deleteOperation(dryRun bool) operation {
+	if dryRun {
+		return opPlannedDelete
+	}
+	return opDelete
+}
+
+// trackBytes records the bytes sent to the remote server, if a report is set.
+func trackBytes(n int) {
+	if r := currentReport(); r != nil {
+		r.addBytes(n)
+	}
+}
+
+// runProgressBar runs the progressbar over the config items and returns the error
+// carried by the first progressbar.IncrementErrMsg sent by the tea commands, if any.
+// Each item outcome is recorded to the report, except for the parallel uploads
+// which record their own results.
+func runProgressBar(op operation, pbConfig *progressbar.Config) error {
+	if len(pbConfig.Items) == 0 {
+		return nil
+	}
+
+	start := time.Now()
+	if r := currentReport(); r != nil {
+		defer func() { r.addStep(op, len(pbConfig.Items), time.Since(start)) }()
+	}
+
+	var cmdErr error
+	onProgressCmd := pbConfig.OnProgressCmd
+	pbConfig.OnProgressCmd = func(item string) tea.Cmd {
+		cmd := onProgressCmd(item)
+		return func() tea.Msg {
+			msg := cmd()
+			errMsg, isErr := msg.(progressbar.IncrementErrMsg)
+			if isErr && cmdErr == nil {
+				cmdErr = errMsg.Err
+			}
+			if op != opParallelUpload {
+				trackItem(op, item, errMsg.Err)
+			}
+			return msg
+		}
+	}
+
+	if w := plainProgressWriter(); w != nil {
+		return runPlainProgress(w, op, pbConfig)
+	}
+
+	if _, err := progressbar.Run(pbConfig); err != nil {
+		return err
+	}
+	return cmdErr
+}
+
+// runPlainProgress runs the tea commands one after the other printing a line for each item.
+// As the progressbar does, it stops at the first error.
+func runPlainProgress(w io.Writer, op operation, pbConfig *progressbar.Config) error {
+	total := len(pbConfig.Items)
+	for i, item := range pbConfig.Items {
+		msg := pbConfig.OnProgressCmd(item)()
+		if errMsg, ok := msg.(progressbar.IncrementErrMsg); ok {
+			fmt.Fprintf(w, "[%d/%d] %s %s: error: %s\n", i+1, total, op, item, errMsg.Err)
+			return errMsg.Err
+		}
+		if name, ok := msg.(progressbar.IncrementMsg); ok && op == opParallelUpload {
+			item = string(name)
+		}
+		fmt.Fprintf(w, "[%d/%d] %s %s\n", i+1, total, op, item)
+	}
+	if pbConfig.OnCompletesMsg != "" {
+		fmt.Fprintln(w, pbConfig.OnCompletesMsg)
+	}
+	return nil
+}
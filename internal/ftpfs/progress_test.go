@@ -0,0 +1,87 @@
+package ftpfs
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"testing"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/matryer/is"
+	"github.com/sveltinio/prompti/progressbar"
+)
+
+func usePlainProgressAndReport(t *testing.T) (*bytes.Buffer, *Report) {
+	t.Helper()
+	var out bytes.Buffer
+	report := NewReport()
+	SetPlainProgress(&out)
+	SetReport(report)
+	t.Cleanup(func() {
+		SetPlainProgress(nil)
+		SetReport(nil)
+	})
+	return &out, report
+}
+
+func TestPlainProgress(t *testing.T) {
+	is := is.New(t)
+	out, report := usePlainProgressAndReport(t)
+
+	pbConfig := &progressbar.Config{
+		Items:          []string{"index.html", "about/index.html"},
+		OnCompletesMsg: "Done! 2 files uploaded",
+		OnProgressCmd: func(path string) tea.Cmd {
+			trackBytes(10)
+			return func() tea.Msg {
+				return progressbar.IncrementMsg(path)
+			}
+		},
+	}
+	is.NoErr(runProgressBar(opUpload, pbConfig))
+	is.Equal(out.String(), "[1/2] upload index.html\n[2/2] upload about/index.html\nDone! 2 files uploaded\n")
+
+	// the first error stops the progress
+	out.Reset()
+	failure := errors.New("550 permission denied")
+	pbConfig = &progressbar.Config{
+		Items: []string{"posts", "private", "tags"},
+		OnProgressCmd: func(path string) tea.Cmd {
+			return func() tea.Msg {
+				if path == "private" {
+					return progressbar.IncrementErrMsg{Err: failure}
+				}
+				return progressbar.IncrementMsg(path)
+			}
+		},
+	}
+	is.Equal(runProgressBar(opMakeDirs, pbConfig), failure)
+	is.Equal(out.String(), "[1/3] mkdir posts\n[2/3] mkdir private: error: 550 permission denied\n")
+
+	report.AddSkipped("favicon.png")
+	report.Finish(nil)
+	is.Equal(report.FilesUploaded, []string{"index.html", "about/index.html"})
+	is.Equal(report.FoldersCreated, []string{"posts"})
+	is.Equal(report.FilesSkipped, []string{"favicon.png"})
+	is.Equal(report.BytesTransferred, int64(20))
+	is.Equal(len(report.Steps), 2)
+	is.Equal(report.Errors, []FileError{{Operation: "mkdir", File: "private", Error: "550 permission denied"}})
+	is.True(!report.Success)
+}
+
+func TestReportJSON(t *testing.T) {
+	is := is.New(t)
+	report := NewReport()
+	report.Environment = "staging"
+	report.Finish(errors.New("connection refused"))
+
+	var buf bytes.Buffer
+	is.NoErr(report.WriteJSON(&buf))
+
+	decoded := map[string]interface{}{}
+	is.NoErr(json.Unmarshal(buf.Bytes(), &decoded))
+	is.Equal(decoded["environment"], "staging")
+	is.Equal(decoded["success"], false)
+	is.Equal(decoded["error"], "connection refused")
+	is.Equal(decoded["filesUploaded"], []interface{}{})
+}
@@ -8,12 +8,33 @@
 package ftpfs
 
 import (
+	"sort"
+	"time"
+
 	"github.com/spf13/afero"
+	"github.com/sveltinio/yinlog"
+)
+
+// Protocols supported to connect to a remote server.
+const (
+	FTPProtocol  string = "ftp"
+	SFTPProtocol string = "sftp"
+	S3Protocol   string = "s3"
+	DirProtocol  string = "dir"
+	GitProtocol  string = "git"
+)
+
+// Suffixes for the sibling folders used by the atomic deploy.
+const (
+	StagingFolderSuffix  string = ".staging"
+	PreviousFolderSuffix string = ".previous"
 )
 
 // RemoteServer is the interface defining the list of actions
 // can be performed on a RemoteServer implementation.
 type RemoteServer interface {
+	SetRootFolder(string)
+	SetLogger(*yinlog.Logger)
 	Dial() error
 	Login() error
 	Logout() error
@@ -22,4 +43,30 @@ type RemoteServer interface {
 	UploadFiles(afero.Fs, string, []string, bool, bool) error
 	DeleteAll([]string, bool) error
 	DoBackup(afero.Fs, string, bool) error
+	ReadFile(string) ([]byte, error)
+	WriteFile(string, []byte, bool) error
+	DeleteFiles([]string, bool) error
+	Rename(string, string, bool) error
+	RemoveDir(string, bool) error
+	Swap(string, string, bool) error
+	ListFiles() ([]RemoteFile, error)
+}
+
+// Cleaner is implemented by the RemoteServer holding local resources set up on dial,
+// e.g. a temporary git worktree, to release them when exiting on error before logout.
+type Cleaner interface {
+	Cleanup() error
+}
+
+// RemoteFile is a file within the remote folder, the path being relative to it.
+type RemoteFile struct {
+	Path    string    `json:"path"`
+	Size    int64     `json:"size"`
+	ModTime time.Time `json:"modTime"`
+}
+
+func sortRemoteFiles(files []RemoteFile) {
+	sort.Slice(files, func(i, j int) bool {
+		return files[i].Path < files[j].Path
+	})
 }
@@ -0,0 +1,83 @@
+/**
+ * Copyright © 2021-present Sveltin contributors <github@sveltin.io>
+ *
+ * Use of this source code is governed by Apache 2.0 license
+ * that can be found in the LICENSE file.
+ */
+
+package ftpfs
+
+import (
+	"errors"
+	"io"
+	"net"
+	"net/textproto"
+	"syscall"
+	"time"
+)
+
+// DefaultRetryDelay is the delay before the first retry. It doubles at every attempt.
+const DefaultRetryDelay = time.Second
+
+// maxRetryDelay caps the exponential backoff.
+const maxRetryDelay = 30 * time.Second
+
+// isTransientError returns true for the errors worth a retry: network timeouts, connections
+// dropped, reset or closed by the server and the FTP 4xx (transient negative) replies.
+// Failing to connect, e.g. an unknown host or a refused connection, comes from a wrong
+// setting as a login refused with a 5xx reply does, so they are not retried.
+func isTransientError(err error) bool {
+	if err == nil {
+		return false
+	}
+	var opErr *net.OpError
+	if errors.As(err, &opErr) && opErr.Op == "dial" {
+		return false
+	}
+	if errors.Is(err, io.EOF) || errors.Is(err, io.ErrUnexpectedEOF) || errors.Is(err, net.ErrClosed) ||
+		errors.Is(err, syscall.ECONNRESET) || errors.Is(err, syscall.EPIPE) {
+		return true
+	}
+	var netErr net.Error
+	if errors.As(err, &netErr) {
+		return netErr.Timeout()
+	}
+	var protoErr *textproto.Error
+	if errors.As(err, &protoErr) {
+		return protoErr.Code >= 400 && protoErr.Code < 500
+	}
+	return false
+}
+
+// backoffDelay returns the delay before the given retry attempt (starting from 0).
+func backoffDelay(base time.Duration, attempt int) time.Duration {
+	if base <= 0 {
+		base = DefaultRetryDelay
+	}
+	delay := base
+	for i := 0; i < attempt && delay < maxRetryDelay; i++ {
+		delay *= 2
+	}
+	if delay > maxRetryDelay {
+		return maxRetryDelay
+	}
+	return delay
+}
+
+// withRetry runs op and, as long as it fails with a transient error, waits for the
+// backoff delay, calls reconnect and runs op again up to retries times.
+func withRetry(retries int, base time.Duration, reconnect func() error, op func() error, onRetry func(attempt int, err error)) error {
+	err := op()
+	for attempt := 0; attempt < retries && isTransientError(err); attempt++ {
+		if onRetry != nil {
+			onRetry(attempt+1, err)
+		}
+		time.Sleep(backoffDelay(base, attempt))
+		if rErr := reconnect(); rErr != nil {
+			err = rErr
+			continue
+		}
+		err = op()
+	}
+	return err
+}
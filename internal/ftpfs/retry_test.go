@@ -0,0 +1,98 @@
+package ftpfs
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/textproto"
+	"syscall"
+	"testing"
+	"time"
+
+	"github.com/matryer/is"
+)
+
+func TestIsTransientError(t *testing.T) {
+	is := is.New(t)
+
+	tests := []struct {
+		name      string
+		err       error
+		transient bool
+	}{
+		{"no error", nil, false},
+		{"connection dropped", io.EOF, true},
+		{"connection dropped mid-transfer", fmt.Errorf("stor: %w", io.ErrUnexpectedEOF), true},
+		{"connection closed", &net.OpError{Op: "read", Net: "tcp", Err: net.ErrClosed}, true},
+		{"connection reset", &net.OpError{Op: "read", Net: "tcp", Err: syscall.ECONNRESET}, true},
+		{"broken pipe", &net.OpError{Op: "write", Net: "tcp", Err: syscall.EPIPE}, true},
+		{"read timeout", &net.OpError{Op: "read", Net: "tcp", Err: &net.DNSError{Err: "i/o timeout", IsTimeout: true}}, true},
+		{"service not available", &textproto.Error{Code: 421, Msg: "Timeout"}, true},
+		{"transfer aborted", &textproto.Error{Code: 426, Msg: "Connection closed; transfer aborted"}, true},
+		{"permission denied", &textproto.Error{Code: 550, Msg: "Permission denied"}, false},
+		{"login refused", &textproto.Error{Code: 530, Msg: "Login incorrect"}, false},
+		{"unknown host", &net.OpError{Op: "dial", Net: "tcp", Err: &net.DNSError{Err: "no such host", Name: "ftp.example.com", IsNotFound: true}}, false},
+		{"connection refused", &net.OpError{Op: "dial", Net: "tcp", Err: syscall.ECONNREFUSED}, false},
+		{"dial timeout", &net.OpError{Op: "dial", Net: "tcp", Err: &net.DNSError{Err: "i/o timeout", IsTimeout: true}}, false},
+		{"other error", errors.New("file not found"), false},
+	}
+	for _, tt := range tests {
+		if isTransientError(tt.err) != tt.transient {
+			t.Errorf("%s: isTransientError(%v) != %v", tt.name, tt.err, tt.transient)
+		}
+	}
+	is.True(!isTransientError(nil))
+}
+
+func TestBackoffDelay(t *testing.T) {
+	is := is.New(t)
+
+	is.Equal(backoffDelay(0, 0), DefaultRetryDelay)
+	is.Equal(backoffDelay(100*time.Millisecond, 0), 100*time.Millisecond)
+	is.Equal(backoffDelay(100*time.Millisecond, 3), 800*time.Millisecond)
+	is.Equal(backoffDelay(time.Second, 10), maxRetryDelay)
+}
+
+func TestWithRetry(t *testing.T) {
+	is := is.New(t)
+
+	// transient errors are retried after reconnecting
+	calls, reconnects := 0, 0
+	err := withRetry(3, time.Millisecond, func() error {
+		reconnects++
+		return nil
+	}, func() error {
+		calls++
+		if calls < 3 {
+			return io.EOF
+		}
+		return nil
+	}, nil)
+	is.NoErr(err)
+	is.Equal(calls, 3)
+	is.Equal(reconnects, 2)
+
+	// permanent errors are not retried
+	calls = 0
+	permanent := &textproto.Error{Code: 550, Msg: "Permission denied"}
+	err = withRetry(3, time.Millisecond, func() error { return nil }, func() error {
+		calls++
+		return permanent
+	}, nil)
+	is.Equal(err, permanent)
+	is.Equal(calls, 1)
+
+	// the last error is returned once the retries are over
+	calls = 0
+	attempts := []int{}
+	err = withRetry(2, time.Millisecond, func() error { return nil }, func() error {
+		calls++
+		return io.EOF
+	}, func(attempt int, err error) {
+		attempts = append(attempts, attempt)
+	})
+	is.Equal(err, io.EOF)
+	is.Equal(calls, 3)
+	is.Equal(attempts, []int{1, 2})
+}
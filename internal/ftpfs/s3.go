@@ -0,0 +1,460 @@
+/**
+ * Copyright © 2021-present Sveltin contributors <github@sveltin.io>
+ *
+ * Use of this source code is governed by Apache 2.0 license
+ * that can be found in the LICENSE file.
+ */
+
+package ftpfs
+
+import (
+	"archive/tar"
+	"bytes"
+	"compress/gzip"
+	"context"
+	"fmt"
+	"io"
+	"mime"
+	"path"
+	"sort"
+	"strings"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/minio/minio-go/v7"
+	"github.com/minio/minio-go/v7/pkg/credentials"
+	"github.com/spf13/afero"
+	"github.com/sveltinio/prompti/progressbar"
+	"github.com/sveltinio/sveltin/utils"
+	"github.com/sveltinio/yinlog"
+)
+
+// Cache-Control headers set on the uploaded objects.
+const (
+	// DefaultCacheControl is used for pages and the other mutable files.
+	DefaultCacheControl string = "public, max-age=0, must-revalidate"
+	// ImmutableCacheControl is used for the SvelteKit fingerprinted files (_app/immutable).
+	ImmutableCacheControl string = "public, max-age=31536000, immutable"
+)
+
+// Folders used by an atomic deploy to the bucket root. There the staging and previous
+// folders are key prefixes among the deployed objects, so they are never listed,
+// backed up, deleted nor moved along with them.
+var rootAtomicFolders = []string{StagingFolderSuffix, PreviousFolderSuffix}
+
+// S3ServerConnection is the struct with all is needed to establish and act on an S3-compatible bucket.
+// The root folder is used as key prefix within the bucket.
+type S3ServerConnection struct {
+	Config       S3ConnectionConfig
+	serverFolder string
+	client       *minio.Client
+	logger       *yinlog.Logger
+}
+
+// NewS3ServerConnection returns a new S3ServerConnection struct.
+func NewS3ServerConnection(config *S3ConnectionConfig) *S3ServerConnection {
+	return &S3ServerConnection{
+		Config: S3ConnectionConfig{
+			Endpoint:     config.Endpoint,
+			Region:       config.Region,
+			Bucket:       config.Bucket,
+			AccessKey:    config.AccessKey,
+			SecretKey:    config.SecretKey,
+			UseSSL:       config.UseSSL,
+			CacheControl: config.CacheControl,
+			Parallel:     config.Parallel,
+		},
+	}
+}
+
+// SetRootFolder sets the key prefix within the bucket.
+func (s *S3ServerConnection) SetRootFolder(name string) {
+	s.serverFolder = name
+}
+
+// SetLogger sets the logger used by the S3 connection.
+func (s *S3ServerConnection) SetLogger(logger *yinlog.Logger) {
+	s.logger = logger
+}
+
+// Dial contains the logic for the S3 receiver to handle the dial command.
+func (s *S3ServerConnection) Dial() error {
+	s.logger.Infof("Connecting to the S3 endpoint (%s) ", s.Config.Endpoint)
+	client, err := minio.New(s.Config.Endpoint, &minio.Options{
+		Creds:  credentials.NewStaticV4(s.Config.AccessKey, s.Config.SecretKey, ""),
+		Secure: s.Config.UseSSL,
+		Region: s.Config.Region,
+	})
+	if err != nil {
+		return err
+	}
+	s.client = client
+	return nil
+}
+
+// Login contains the logic for the S3 receiver to handle the login command.
+// It checks the credentials are valid and the bucket exists.
+func (s *S3ServerConnection) Login() error {
+	s.logger.Infof("Login (bucket %s)\n\n", s.Config.Bucket)
+	exists, err := s.client.BucketExists(context.Background(), s.Config.Bucket)
+	if err != nil {
+		return err
+	}
+	if !exists {
+		return fmt.Errorf("the bucket '%s' does not exist", s.Config.Bucket)
+	}
+	return nil
+}
+
+// Logout contains the logic for the S3 receiver to handle the logout command.
+func (s *S3ServerConnection) Logout() error {
+	s.logger.Info("Closing the connection to the S3 endpoint")
+	return nil
+}
+
+// Idle contains the logic for the S3 receiver to handle the no-operation (idle) command.
+func (s *S3ServerConnection) Idle() error {
+	return nil
+}
+
+// MakeDirs contains the logic for the S3 receiver to handle the make dirs command.
+// Object storage has no folders, keys are created on upload.
+func (s *S3ServerConnection) MakeDirs(folders []string, dryRun bool) error {
+	return nil
+}
+
+// UploadFiles contains the logic for the S3 receiver to handle the upload files command.
+func (s *S3ServerConnection) UploadFiles(appFs afero.Fs, localDir string, files []string, replaceBasePath, dryRun bool) error {
+	sort.Strings(files)
+
+	if s.Config.Parallel > 1 && len(files) > 1 && !dryRun {
+		workers := make([]worker, numOfWorkers(s.Config.Parallel, len(files)))
+		for i := range workers {
+			workers[i] = func(file string) error {
+				return s.uploadLocalFile(appFs, file, localDir, replaceBasePath)
+			}
+		}
+		return uploadInParallel(files, workers)
+	}
+
+	pbConfig := &progressbar.Config{
+		Items:          files,
+		OnCompletesMsg: fmt.Sprintf("Done! %d files uploaded", len(files)),
+		OnProgressCmd: func(path string) tea.Cmd {
+			return s3UploadFileTeaCmd(s, appFs, path, localDir, replaceBasePath, dryRun)
+		},
+	}
+
+	if err := runProgressBar(opUpload, pbConfig); err != nil {
+		return err
+	}
+	return nil
+}
+
+// DeleteAll contains the logic for the S3 receiver to handle the delete all command.
+// The objects matching the gitignore-style exclude rules, or within a matching folder, are kept.
+func (s *S3ServerConnection) DeleteAll(exclude []string, dryrun bool) error {
+	objects, err := s.walkObjects(s.serverFolder)
+	if err != nil {
+		return err
+	}
+
+	if len(objects) > 0 {
+		s.logger.Important("Deleting previous content from the bucket")
+		rules := NewExcludeRules(exclude)
+		for _, object := range objects {
+			if rules.Excluded(object, false) {
+				continue
+			}
+			if !dryrun {
+				if err := s.removeObject(s.objectKey(object)); err != nil {
+					return err
+				}
+			}
+			trackItem(deleteOperation(dryrun), object, nil)
+		}
+	}
+	return nil
+}
+
+// DoBackup contains the logic for the S3 receiver to handle the backup command.
+func (s *S3ServerConnection) DoBackup(appFs afero.Fs, tarballFilePath string, dryRun bool) error {
+	archiveFilename := tarballFilePath + "_" + time.Now().Format("20060102_3:4:5PM") + ".tar.gz"
+	s.logger.Infof("Reading the bucket: %s/%s", s.Config.Bucket, keyPrefix(s.serverFolder))
+	objects, err := s.walkObjects(s.serverFolder)
+	if err != nil {
+		return err
+	}
+
+	if !dryRun {
+		if len(objects) > 0 {
+			if err := s.createTarball(appFs, archiveFilename, objects, dryRun); err != nil {
+				return err
+			}
+		} else {
+			s.logger.Important("Nothing to backup in the bucket!")
+		}
+	}
+	return nil
+}
+
+// ReadFile contains the logic for the S3 receiver to retrieve an object.
+func (s *S3ServerConnection) ReadFile(filename string) ([]byte, error) {
+	object, err := s.client.GetObject(context.Background(), s.Config.Bucket, s.objectKey(filename), minio.GetObjectOptions{})
+	if err != nil {
+		return nil, err
+	}
+	defer object.Close()
+	return io.ReadAll(object)
+}
+
+// WriteFile contains the logic for the S3 receiver to store an object.
+func (s *S3ServerConnection) WriteFile(filename string, data []byte, dryRun bool) error {
+	if dryRun {
+		return nil
+	}
+	return s.putObject(filename, data)
+}
+
+// DeleteFiles contains the logic for the S3 receiver to handle the delete files command.
+func (s *S3ServerConnection) DeleteFiles(files []string, dryRun bool) error {
+	if len(files) == 0 {
+		return nil
+	}
+	sort.Strings(files)
+
+	pbConfig := &progressbar.Config{
+		Items:          files,
+		OnCompletesMsg: fmt.Sprintf("Done! %d objects deleted", len(files)),
+		OnProgressCmd: func(path string) tea.Cmd {
+			return s3DeleteFileTeaCmd(s, path, dryRun)
+		},
+	}
+
+	if err := runProgressBar(deleteOperation(dryRun), pbConfig); err != nil {
+		return err
+	}
+	return nil
+}
+
+// Rename contains the logic for the S3 receiver to handle the rename command.
+// All the objects with the oldPath prefix are copied server side to the newPath one and then deleted.
+func (s *S3ServerConnection) Rename(oldPath, newPath string, dryRun bool) error {
+	s.logger.Infof("Renaming %s to %s", oldPath, newPath)
+	if dryRun {
+		return nil
+	}
+	return s.movePrefix(oldPath, newPath)
+}
+
+// RemoveDir contains the logic for the S3 receiver to handle the remove dir command.
+// All the objects with the dirname prefix are deleted.
+func (s *S3ServerConnection) RemoveDir(dirname string, dryRun bool) error {
+	if dryRun {
+		return nil
+	}
+	objects, err := s.walkObjects(dirname)
+	if err != nil || len(objects) == 0 {
+		return err
+	}
+	s.logger.Infof("Deleting the objects with prefix %s", keyPrefix(dirname))
+	for _, object := range objects {
+		if err := s.removeObject(path.Join(keyPrefix(dirname), object)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Swap contains the logic for the S3 receiver to handle the swap command.
+// Object storage has no rename, so the swap copies the objects and is not atomic.
+func (s *S3ServerConnection) Swap(stagingFolder, previousFolder string, dryRun bool) error {
+	s.logger.Infof("Swapping %s with %s", stagingFolder, s.serverFolder)
+	if dryRun {
+		return nil
+	}
+
+	if err := s.RemoveDir(previousFolder, dryRun); err != nil {
+		return err
+	}
+	if err := s.movePrefix(s.serverFolder, previousFolder); err != nil {
+		return err
+	}
+	return s.movePrefix(stagingFolder, s.serverFolder)
+}
+
+// ListFiles returns the objects within the bucket folder.
+func (s *S3ServerConnection) ListFiles() ([]RemoteFile, error) {
+	prefix := keyPrefix(s.serverFolder)
+	opts := minio.ListObjectsOptions{Recursive: true}
+	if prefix != "" {
+		opts.Prefix = prefix + "/"
+	}
+
+	files := []RemoteFile{}
+	for object := range s.client.ListObjects(context.Background(), s.Config.Bucket, opts) {
+		if object.Err != nil {
+			return nil, object.Err
+		}
+		if isRootAtomicObject(prefix, object.Key) {
+			continue
+		}
+		files = append(files, RemoteFile{
+			Path:    strings.TrimPrefix(object.Key, opts.Prefix),
+			Size:    object.Size,
+			ModTime: object.LastModified,
+		})
+	}
+	sortRemoteFiles(files)
+	return files, nil
+}
+
+//=============================================================================
+
+// keyPrefix returns the folder as key prefix, without leading and trailing slashes.
+func keyPrefix(folder string) string {
+	return strings.Trim(folder, "/")
+}
+
+// objectKey returns the key for a file within the root folder.
+func (s *S3ServerConnection) objectKey(filename string) string {
+	return path.Join(keyPrefix(s.serverFolder), filename)
+}
+
+// objectContentType returns the Content-Type for the file, based on its extension.
+func objectContentType(filename string) string {
+	if contentType := mime.TypeByExtension(path.Ext(filename)); contentType != "" {
+		return contentType
+	}
+	return "application/octet-stream"
+}
+
+// objectCacheControl returns the Cache-Control for the file. Fingerprinted files never change.
+func (s *S3ServerConnection) objectCacheControl(filename string) string {
+	if strings.HasPrefix(filename, "_app/immutable/") || strings.Contains(filename, "/_app/immutable/") {
+		return ImmutableCacheControl
+	}
+	if s.Config.CacheControl != "" {
+		return s.Config.CacheControl
+	}
+	return DefaultCacheControl
+}
+
+// walkObjects returns the keys, relative to the folder, for all the objects with the folder
+// prefix. For the bucket root, the objects within the atomic deploy folders are left out.
+func (s *S3ServerConnection) walkObjects(folder string) ([]string, error) {
+	prefix := keyPrefix(folder)
+	opts := minio.ListObjectsOptions{Recursive: true}
+	if prefix != "" {
+		opts.Prefix = prefix + "/"
+	}
+
+	objects := []string{}
+	for object := range s.client.ListObjects(context.Background(), s.Config.Bucket, opts) {
+		if object.Err != nil {
+			return nil, object.Err
+		}
+		if isRootAtomicObject(prefix, object.Key) {
+			continue
+		}
+		objects = append(objects, strings.TrimPrefix(object.Key, opts.Prefix))
+	}
+	sort.Strings(objects)
+	return objects, nil
+}
+
+// isRootAtomicObject reports whether the key, listed with the given prefix, is within
+// one of the atomic deploy folders at the bucket root.
+func isRootAtomicObject(prefix, key string) bool {
+	if prefix != "" {
+		return false
+	}
+	for _, folder := range rootAtomicFolders {
+		if strings.HasPrefix(key, folder+"/") {
+			return true
+		}
+	}
+	return false
+}
+
+// movePrefix copies the objects within oldFolder to newFolder, deleting them once copied.
+func (s *S3ServerConnection) movePrefix(oldFolder, newFolder string) error {
+	objects, err := s.walkObjects(oldFolder)
+	if err != nil {
+		return err
+	}
+	for _, object := range objects {
+		src := minio.CopySrcOptions{Bucket: s.Config.Bucket, Object: path.Join(keyPrefix(oldFolder), object)}
+		dst := minio.CopyDestOptions{Bucket: s.Config.Bucket, Object: path.Join(keyPrefix(newFolder), object)}
+		if _, err := s.client.CopyObject(context.Background(), dst, src); err != nil {
+			return err
+		}
+		if err := s.removeObject(src.Object); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// removeObject deletes the object with the given key from the bucket.
+func (s *S3ServerConnection) removeObject(key string) error {
+	return s.client.RemoveObject(context.Background(), s.Config.Bucket, key, minio.RemoveObjectOptions{})
+}
+
+// uploadLocalFile reads the file from appFs and uploads it to the bucket.
+func (s *S3ServerConnection) uploadLocalFile(appFs afero.Fs, file, localDir string, replaceBasePath bool) error {
+	fileBytes, err := afero.ReadFile(appFs, file)
+	if err != nil {
+		return err
+	}
+	remoteFile := file
+	if replaceBasePath {
+		remoteFile = utils.ToBasePath(file, localDir)
+	}
+	if err := s.putObject(remoteFile, fileBytes); err != nil {
+		return err
+	}
+	trackBytes(len(fileBytes))
+	return nil
+}
+
+func (s *S3ServerConnection) putObject(filename string, data []byte) error {
+	_, err := s.client.PutObject(context.Background(), s.Config.Bucket, s.objectKey(filename),
+		bytes.NewReader(data), int64(len(data)), minio.PutObjectOptions{
+			ContentType:  objectContentType(filename),
+			CacheControl: s.objectCacheControl(filename),
+		})
+	return err
+}
+
+func (s *S3ServerConnection) createTarball(appFs afero.Fs, tarballFilePath string, filePaths []string, dryRun bool) error {
+	s.logger.Info("Creating the backup archive...")
+	// In-memory file system
+	memFs := afero.NewMemMapFs()
+	// Create a new archive file
+	file, err := appFs.Create(tarballFilePath)
+	if err != nil {
+		return fmt.Errorf("could not create tarball file '%s', got error '%s'", tarballFilePath, err.Error())
+	}
+	defer file.Close()
+
+	gzipWriter := gzip.NewWriter(file)
+	defer gzipWriter.Close()
+
+	tarWriter := tar.NewWriter(gzipWriter)
+	defer tarWriter.Close()
+
+	pbConfig := &progressbar.Config{
+		Items:          filePaths,
+		OnCompletesMsg: fmt.Sprintf("Backup done! Saved as: %s", tarballFilePath),
+		OnProgressCmd: func(path string) tea.Cmd {
+			return s3CreateTarballTeaCmd(s, memFs, tarWriter, path, dryRun)
+		},
+	}
+
+	if err := runProgressBar(opBackup, pbConfig); err != nil {
+		return err
+	}
+
+	return nil
+}
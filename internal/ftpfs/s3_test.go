@@ -0,0 +1,289 @@
+package ftpfs
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/matryer/is"
+	"github.com/spf13/afero"
+	"github.com/sveltinio/yinlog"
+)
+
+const testS3Bucket = "sveltin"
+
+type testS3Object struct {
+	data         []byte
+	contentType  string
+	cacheControl string
+}
+
+// testS3Server is a minimal in-memory S3 API serving a single bucket:
+// head bucket, list objects v2, get, put, copy and delete object.
+type testS3Server struct {
+	mu      sync.Mutex
+	objects map[string]testS3Object
+}
+
+func newTestS3Server(t *testing.T) (*testS3Server, string) {
+	t.Helper()
+	s := &testS3Server{objects: map[string]testS3Object{}}
+	srv := httptest.NewServer(s)
+	t.Cleanup(srv.Close)
+	return s, strings.TrimPrefix(srv.URL, "http://")
+}
+
+func (s *testS3Server) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	bucket, key := splitTestS3Path(r.URL.Path)
+	if bucket != testS3Bucket {
+		http.Error(w, "<Error><Code>NoSuchBucket</Code></Error>", http.StatusNotFound)
+		return
+	}
+
+	switch {
+	case key == "" && r.Method == http.MethodHead:
+		w.WriteHeader(http.StatusOK)
+	case key == "" && r.Method == http.MethodGet:
+		s.listObjects(w, r.URL.Query().Get("prefix"))
+	case r.Method == http.MethodGet:
+		object, exists := s.objects[key]
+		if !exists {
+			w.Header().Set("Content-Type", "application/xml")
+			w.WriteHeader(http.StatusNotFound)
+			fmt.Fprint(w, "<Error><Code>NoSuchKey</Code><Message>not found</Message></Error>")
+			return
+		}
+		w.Header().Set("ETag", `"etag"`)
+		w.Header().Set("Last-Modified", time.Now().UTC().Format(http.TimeFormat))
+		w.Header().Set("Content-Length", strconv.Itoa(len(object.data)))
+		w.Write(object.data)
+	case r.Method == http.MethodPut && r.Header.Get("X-Amz-Copy-Source") != "":
+		_, srcKey := splitTestS3Path(r.Header.Get("X-Amz-Copy-Source"))
+		s.objects[key] = s.objects[srcKey]
+		fmt.Fprintf(w, `<CopyObjectResult><ETag>"etag"</ETag><LastModified>%s</LastModified></CopyObjectResult>`, time.Now().UTC().Format(time.RFC3339))
+	case r.Method == http.MethodPut:
+		data, err := readTestS3Body(r)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		s.objects[key] = testS3Object{data: data, contentType: r.Header.Get("Content-Type"), cacheControl: r.Header.Get("Cache-Control")}
+		w.Header().Set("ETag", `"etag"`)
+	case r.Method == http.MethodDelete:
+		delete(s.objects, key)
+		w.WriteHeader(http.StatusNoContent)
+	default:
+		w.WriteHeader(http.StatusNotImplemented)
+	}
+}
+
+// splitTestS3Path splits the /<bucket>/<key> path style used by the requests.
+func splitTestS3Path(p string) (string, string) {
+	parts := strings.SplitN(strings.TrimPrefix(p, "/"), "/", 2)
+	if len(parts) == 1 {
+		return parts[0], ""
+	}
+	return parts[0], parts[1]
+}
+
+func (s *testS3Server) listObjects(w http.ResponseWriter, prefix string) {
+	keys := []string{}
+	for key := range s.objects {
+		if strings.HasPrefix(key, prefix) {
+			keys = append(keys, key)
+		}
+	}
+	sort.Strings(keys)
+
+	var b strings.Builder
+	fmt.Fprintf(&b, `<ListBucketResult><Name>%s</Name><Prefix>%s</Prefix><KeyCount>%d</KeyCount><MaxKeys>1000</MaxKeys><IsTruncated>false</IsTruncated>`, testS3Bucket, prefix, len(keys))
+	for _, key := range keys {
+		fmt.Fprintf(&b, `<Contents><Key>%s</Key><LastModified>%s</LastModified><ETag>"etag"</ETag><Size>%d</Size><StorageClass>STANDARD</StorageClass></Contents>`,
+			key, time.Now().UTC().Format(time.RFC3339), len(s.objects[key].data))
+	}
+	b.WriteString(`</ListBucketResult>`)
+	w.Header().Set("Content-Type", "application/xml")
+	fmt.Fprint(w, b.String())
+}
+
+// readTestS3Body returns the request payload, decoding the aws-chunked encoding used over plain HTTP.
+func readTestS3Body(r *http.Request) ([]byte, error) {
+	if !strings.HasPrefix(r.Header.Get("X-Amz-Content-Sha256"), "STREAMING-") {
+		return io.ReadAll(r.Body)
+	}
+	var data bytes.Buffer
+	reader := bufio.NewReader(r.Body)
+	for {
+		header, err := reader.ReadString('\n')
+		if err != nil {
+			return nil, err
+		}
+		sizeHex := strings.SplitN(strings.TrimSpace(header), ";", 2)[0]
+		size, err := strconv.ParseInt(sizeHex, 16, 64)
+		if err != nil {
+			return nil, err
+		}
+		if size == 0 {
+			return data.Bytes(), nil
+		}
+		if _, err := io.CopyN(&data, reader, size); err != nil {
+			return nil, err
+		}
+		if _, err := reader.Discard(2); err != nil {
+			return nil, err
+		}
+	}
+}
+
+func newTestS3Connection(t *testing.T, endpoint, folder string) *S3ServerConnection {
+	t.Helper()
+	is := is.New(t)
+	conn := NewS3ServerConnection(&S3ConnectionConfig{
+		Endpoint:  endpoint,
+		Region:    "us-east-1",
+		Bucket:    testS3Bucket,
+		AccessKey: "minioadmin",
+		SecretKey: "minioadmin",
+	})
+	conn.SetRootFolder(folder)
+	conn.SetLogger(yinlog.New())
+	is.NoErr(DialAction(conn).Run())
+	is.NoErr(LoginAction(conn).Run())
+	return conn
+}
+
+func TestS3ObjectHeaders(t *testing.T) {
+	is := is.New(t)
+	conn := NewS3ServerConnection(&S3ConnectionConfig{})
+
+	is.Equal(objectContentType("index.html"), "text/html; charset=utf-8")
+	is.Equal(objectContentType("_app/immutable/start.js"), "text/javascript; charset=utf-8")
+	is.Equal(objectContentType("LICENSE"), "application/octet-stream")
+
+	is.Equal(conn.objectCacheControl("index.html"), DefaultCacheControl)
+	is.Equal(conn.objectCacheControl("_app/immutable/start.js"), ImmutableCacheControl)
+	conn.Config.CacheControl = "public, max-age=60"
+	is.Equal(conn.objectCacheControl("about/index.html"), "public, max-age=60")
+}
+
+func TestS3RemoteOperations(t *testing.T) {
+	is := is.New(t)
+	srv, endpoint := newTestS3Server(t)
+	appFs := afero.NewMemMapFs()
+	is.NoErr(afero.WriteFile(appFs, "build/index.html", []byte("<h1>home</h1>"), 0644))
+	is.NoErr(afero.WriteFile(appFs, "build/_app/immutable/start.js", []byte("start()"), 0644))
+
+	// the bucket must exist
+	missing := NewS3ServerConnection(&S3ConnectionConfig{Endpoint: endpoint, Region: "us-east-1", Bucket: "missing"})
+	missing.SetLogger(yinlog.New())
+	is.NoErr(DialAction(missing).Run())
+	is.True(LoginAction(missing).Run() != nil)
+
+	conn := newTestS3Connection(t, endpoint, "/www/")
+	is.NoErr(conn.uploadLocalFile(appFs, "build/index.html", "build", true))
+	is.NoErr(conn.uploadLocalFile(appFs, "build/_app/immutable/start.js", "build", true))
+	is.NoErr(WriteFileAction(conn, ".htaccess", []byte("Options -Indexes"), false).Run())
+	// dry-run must not write anything
+	is.NoErr(WriteFileAction(conn, "dryrun.html", []byte(""), true).Run())
+
+	is.Equal(srv.objects["www/index.html"].contentType, "text/html; charset=utf-8")
+	is.Equal(srv.objects["www/index.html"].cacheControl, DefaultCacheControl)
+	is.Equal(srv.objects["www/_app/immutable/start.js"].cacheControl, ImmutableCacheControl)
+
+	objects, err := conn.walkObjects("www")
+	is.NoErr(err)
+	is.Equal(objects, []string{".htaccess", "_app/immutable/start.js", "index.html"})
+
+	content, err := conn.ReadFile("index.html")
+	is.NoErr(err)
+	is.Equal(string(content), "<h1>home</h1>")
+	_, err = conn.ReadFile("missing.html")
+	is.True(err != nil)
+
+	is.NoErr(DeleteAllAction(conn, []string{".htaccess"}, false).Run())
+	objects, err = conn.walkObjects("www")
+	is.NoErr(err)
+	is.Equal(objects, []string{".htaccess"})
+}
+
+func TestS3Swap(t *testing.T) {
+	is := is.New(t)
+	_, endpoint := newTestS3Server(t)
+
+	conn := newTestS3Connection(t, endpoint, "www.staging")
+	is.NoErr(conn.WriteFile("index.html", []byte("v1"), false))
+	conn.SetRootFolder("www")
+	is.NoErr(conn.WriteFile("index.html", []byte("v0"), false))
+
+	is.NoErr(SwapAction(conn, "www.staging", "www.previous", false).Run())
+
+	content, err := conn.ReadFile("index.html")
+	is.NoErr(err)
+	is.Equal(string(content), "v1")
+	staging, err := conn.walkObjects("www.staging")
+	is.NoErr(err)
+	is.Equal(len(staging), 0)
+
+	is.NoErr(RemoveDirAction(conn, "www.previous", false).Run())
+	previous, err := conn.walkObjects("www.previous")
+	is.NoErr(err)
+	is.Equal(len(previous), 0)
+}
+
+func TestS3SwapBucketRoot(t *testing.T) {
+	is := is.New(t)
+	_, report := usePlainProgressAndReport(t)
+	_, endpoint := newTestS3Server(t)
+
+	conn := newTestS3Connection(t, endpoint, ".staging")
+	is.NoErr(conn.WriteFile("index.html", []byte("v1"), false))
+	conn.SetRootFolder("")
+	is.NoErr(conn.WriteFile("index.html", []byte("v0"), false))
+	is.NoErr(conn.WriteFile("old.html", []byte("v0"), false))
+
+	// the staging folder is neither listed nor deleted with the root objects
+	files, err := conn.ListFiles()
+	is.NoErr(err)
+	is.Equal(len(files), 2)
+	is.NoErr(DeleteAllAction(conn, nil, true).Run())
+	is.Equal(report.PlannedDeletions, []string{"index.html", "old.html"})
+
+	is.NoErr(SwapAction(conn, ".staging", ".previous", false).Run())
+
+	live, err := conn.walkObjects("")
+	is.NoErr(err)
+	is.Equal(live, []string{"index.html"})
+	content, err := conn.ReadFile("index.html")
+	is.NoErr(err)
+	is.Equal(string(content), "v1")
+	previous, err := conn.walkObjects(".previous")
+	is.NoErr(err)
+	is.Equal(previous, []string{"index.html", "old.html"})
+	staging, err := conn.walkObjects(".staging")
+	is.NoErr(err)
+	is.Equal(len(staging), 0)
+
+	// a second swap replaces the previous release
+	conn.SetRootFolder(".staging")
+	is.NoErr(conn.WriteFile("index.html", []byte("v2"), false))
+	conn.SetRootFolder("")
+	is.NoErr(SwapAction(conn, ".staging", ".previous", false).Run())
+	previous, err = conn.walkObjects(".previous")
+	is.NoErr(err)
+	is.Equal(previous, []string{"index.html"})
+	content, err = conn.ReadFile(".previous/index.html")
+	is.NoErr(err)
+	is.Equal(string(content), "v1")
+}
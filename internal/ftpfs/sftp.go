@@ -0,0 +1,496 @@
+/**
+ * Copyright © 2021-present Sveltin contributors <github@sveltin.io>
+ *
+ * Use of this source code is governed by Apache 2.0 license
+ * that can be found in the LICENSE file.
+ */
+
+package ftpfs
+
+import (
+	"archive/tar"
+	"bytes"
+	"compress/gzip"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/pkg/sftp"
+	"github.com/spf13/afero"
+	"github.com/sveltinio/prompti/progressbar"
+	"github.com/sveltinio/sveltin/utils"
+	"github.com/sveltinio/yinlog"
+	"golang.org/x/crypto/ssh"
+	"golang.org/x/crypto/ssh/knownhosts"
+)
+
+// SFTPServerConnection is the struct with all is needed to establish and act on the SFTP remote server.
+type SFTPServerConnection struct {
+	Config       SFTPConnectionConfig
+	serverFolder string
+	conn         net.Conn
+	sshClient    *ssh.Client
+	client       *sftp.Client
+	logger       *yinlog.Logger
+}
+
+// NewSFTPServerConnection returns a new SFTPServerConnection struct.
+func NewSFTPServerConnection(config *SFTPConnectionConfig) *SFTPServerConnection {
+	return &SFTPServerConnection{
+		Config: SFTPConnectionConfig{
+			Host:                 config.Host,
+			Port:                 config.Port,
+			User:                 config.User,
+			Password:             config.Password,
+			PrivateKeyFile:       config.PrivateKeyFile,
+			PrivateKeyPassphrase: config.PrivateKeyPassphrase,
+			KnownHostsFile:       config.KnownHostsFile,
+			IgnoreHostKey:        config.IgnoreHostKey,
+			Timeout:              config.Timeout,
+			Parallel:             config.Parallel,
+		},
+	}
+}
+
+// SetRootFolder sets the root folder on the SFTP remote server.
+func (s *SFTPServerConnection) SetRootFolder(name string) {
+	s.serverFolder = name
+}
+
+// SetLogger sets the logger used by the SFTP connection.
+func (s *SFTPServerConnection) SetLogger(logger *yinlog.Logger) {
+	s.logger = logger
+}
+
+// Dial contains the logic for the SFTP receiver to handle the dial command.
+func (s *SFTPServerConnection) Dial() error {
+	connStr := s.Config.makeConnectionString()
+	s.logger.Infof("Connecting to the SFTP Server (%s) ", connStr)
+	c, err := net.DialTimeout("tcp", connStr, time.Duration(s.Config.Timeout)*time.Second)
+	if err != nil {
+		return err
+	}
+	s.conn = c
+	return nil
+}
+
+// Login contains the logic for the SFTP receiver to handle the login command.
+// It performs the SSH handshake over the dialed connection and opens the sftp subsystem.
+func (s *SFTPServerConnection) Login() error {
+	s.logger.Infof("Login (as %s)\n\n", s.Config.User)
+	sshConfig, err := s.makeSSHClientConfig()
+	if err != nil {
+		return err
+	}
+
+	c, chans, reqs, err := ssh.NewClientConn(s.conn, s.Config.makeConnectionString(), sshConfig)
+	if err != nil {
+		return err
+	}
+	s.sshClient = ssh.NewClient(c, chans, reqs)
+
+	client, err := sftp.NewClient(s.sshClient)
+	if err != nil {
+		s.sshClient.Close()
+		return err
+	}
+	s.client = client
+	return nil
+}
+
+// Logout contains the logic for the SFTP receiver to handle the logout command.
+func (s *SFTPServerConnection) Logout() error {
+	s.logger.Info("Closing the connection to the SFTP server")
+	if err := s.client.Close(); err != nil {
+		return err
+	}
+	return s.sshClient.Close()
+}
+
+// Idle contains the logic for the SFTP receiver to handle the no-operation (idle) command.
+func (s *SFTPServerConnection) Idle() error {
+	_, _, err := s.sshClient.SendRequest("keepalive@openssh.com", true, nil)
+	return err
+}
+
+// MakeDirs contains the logic for the SFTP receiver to handle the make dirs command.
+func (s *SFTPServerConnection) MakeDirs(folders []string, dryRun bool) error {
+	sort.Strings(folders)
+
+	pbConfig := &progressbar.Config{
+		Items:          folders,
+		OnCompletesMsg: fmt.Sprintf("Done! %d folders created", len(folders)),
+		OnProgressCmd: func(path string) tea.Cmd {
+			return sftpMkDirTeaCmd(s, path, dryRun)
+		},
+	}
+
+	if err := runProgressBar(opMakeDirs, pbConfig); err != nil {
+		return err
+	}
+	return nil
+}
+
+// UploadFiles contains the logic for the SFTP receiver to handle the upload files command.
+func (s *SFTPServerConnection) UploadFiles(appFs afero.Fs, localDir string, files []string, replaceBasePath, dryRun bool) error {
+	sort.Strings(files)
+
+	if s.Config.Parallel > 1 && len(files) > 1 && !dryRun {
+		// the sftp client supports concurrent requests over the same connection
+		workers := make([]worker, numOfWorkers(s.Config.Parallel, len(files)))
+		for i := range workers {
+			workers[i] = func(file string) error {
+				return s.uploadLocalFile(appFs, file, localDir, replaceBasePath)
+			}
+		}
+		return uploadInParallel(files, workers)
+	}
+
+	pbConfig := &progressbar.Config{
+		Items:          files,
+		OnCompletesMsg: fmt.Sprintf("Done! %d files uploaded", len(files)),
+		OnProgressCmd: func(path string) tea.Cmd {
+			return sftpUploadFileTeaCmd(s, appFs, path, localDir, replaceBasePath, dryRun)
+		},
+	}
+
+	if err := runProgressBar(opUpload, pbConfig); err != nil {
+		return err
+	}
+	return nil
+}
+
+// DeleteAll contains the logic for the SFTP receiver to handle the delete all command.
+// The entries matching the gitignore-style exclude rules are kept.
+func (s *SFTPServerConnection) DeleteAll(exclude []string, dryrun bool) error {
+	entries, err := s.client.ReadDir(s.serverFolder)
+	if err != nil {
+		return err
+	}
+	if len(entries) == 0 {
+		return nil
+	}
+
+	s.logger.Important("Deleting previous content from the SFTP remote folder")
+	cleaner := &remoteCleaner{
+		list: func(dir string) ([]remoteEntry, error) {
+			entries, err := s.client.ReadDir(path.Join(s.serverFolder, dir))
+			if err != nil {
+				return nil, err
+			}
+			list := []remoteEntry{}
+			for _, entry := range entries {
+				list = append(list, remoteEntry{name: entry.Name(), isDir: entry.IsDir()})
+			}
+			return list, nil
+		},
+		removeFile: func(name string) error {
+			return s.client.Remove(path.Join(s.serverFolder, name))
+		},
+		removeDir: func(name string) error {
+			return s.client.RemoveDirectory(path.Join(s.serverFolder, name))
+		},
+		removeDirRecur: func(name string) error {
+			return s.removeDirRecur(path.Join(s.serverFolder, name))
+		},
+	}
+	_, err = cleaner.cleanDir(".", NewExcludeRules(exclude), dryrun)
+	return err
+}
+
+// DoBackup contains the logic for the SFTP receiver to handle the backup command.
+func (s *SFTPServerConnection) DoBackup(appFs afero.Fs, tarballFilePath string, dryRun bool) error {
+	archiveFilename := tarballFilePath + "_" + time.Now().Format("20060102_3:4:5PM") + ".tar.gz"
+	s.logger.Infof("Reading the remote folder: %s", s.serverFolder)
+	remoteFiles, err := s.walkRemote()
+	if err != nil {
+		return err
+	}
+
+	if !dryRun {
+		if len(remoteFiles) > 0 {
+			if err := s.createTarball(appFs, archiveFilename, remoteFiles, dryRun); err != nil {
+				return err
+			}
+		} else {
+			s.logger.Important("Nothing to backup on the server!")
+		}
+	}
+	return nil
+}
+
+// ReadFile contains the logic for the SFTP receiver to retrieve a file from the remote folder.
+func (s *SFTPServerConnection) ReadFile(filename string) ([]byte, error) {
+	f, err := s.client.Open(path.Join(s.serverFolder, filename))
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	return io.ReadAll(f)
+}
+
+// WriteFile contains the logic for the SFTP receiver to store a file on the remote folder.
+func (s *SFTPServerConnection) WriteFile(filename string, data []byte, dryRun bool) error {
+	return s.uploadSingle(filename, bytes.NewBuffer(data), dryRun)
+}
+
+// DeleteFiles contains the logic for the SFTP receiver to handle the delete files command.
+func (s *SFTPServerConnection) DeleteFiles(files []string, dryRun bool) error {
+	if len(files) == 0 {
+		return nil
+	}
+	sort.Strings(files)
+
+	pbConfig := &progressbar.Config{
+		Items:          files,
+		OnCompletesMsg: fmt.Sprintf("Done! %d files deleted", len(files)),
+		OnProgressCmd: func(path string) tea.Cmd {
+			return sftpDeleteFileTeaCmd(s, path, dryRun)
+		},
+	}
+
+	if err := runProgressBar(deleteOperation(dryRun), pbConfig); err != nil {
+		return err
+	}
+	return nil
+}
+
+// Rename contains the logic for the SFTP receiver to handle the rename command.
+func (s *SFTPServerConnection) Rename(oldPath, newPath string, dryRun bool) error {
+	s.logger.Infof("Renaming %s to %s", oldPath, newPath)
+	if dryRun {
+		return nil
+	}
+	return s.client.Rename(oldPath, newPath)
+}
+
+// RemoveDir contains the logic for the SFTP receiver to handle the remove dir command.
+// It does nothing when the folder does not exist.
+func (s *SFTPServerConnection) RemoveDir(dirname string, dryRun bool) error {
+	if dryRun || !s.dirExists(dirname) {
+		return nil
+	}
+	s.logger.Infof("Deleting the remote folder %s", dirname)
+	return s.removeDirRecur(dirname)
+}
+
+// Swap contains the logic for the SFTP receiver to handle the swap command.
+// The root folder is renamed as previousFolder and the stagingFolder takes its place.
+func (s *SFTPServerConnection) Swap(stagingFolder, previousFolder string, dryRun bool) error {
+	s.logger.Infof("Swapping %s with %s", stagingFolder, s.serverFolder)
+	if dryRun {
+		return nil
+	}
+
+	if err := s.RemoveDir(previousFolder, dryRun); err != nil {
+		return err
+	}
+	if s.dirExists(s.serverFolder) {
+		if err := s.client.Rename(s.serverFolder, previousFolder); err != nil {
+			return err
+		}
+	}
+	return s.client.Rename(stagingFolder, s.serverFolder)
+}
+
+// ListFiles returns the files within the SFTP remote folder.
+func (s *SFTPServerConnection) ListFiles() ([]RemoteFile, error) {
+	w := s.client.Walk(s.serverFolder)
+	files := []RemoteFile{}
+	for w.Step() {
+		if err := w.Err(); err != nil {
+			return nil, err
+		}
+		if info := w.Stat(); info.Mode().IsRegular() {
+			files = append(files, RemoteFile{
+				Path:    utils.ToBasePath(w.Path(), s.serverFolder),
+				Size:    info.Size(),
+				ModTime: info.ModTime(),
+			})
+		}
+	}
+	sortRemoteFiles(files)
+	return files, nil
+}
+
+//=============================================================================
+
+func (s *SFTPServerConnection) dirExists(dirname string) bool {
+	info, err := s.client.Stat(dirname)
+	if err != nil {
+		return false
+	}
+	return info.IsDir()
+}
+
+func (s *SFTPServerConnection) makeSSHClientConfig() (*ssh.ClientConfig, error) {
+	authMethods := []ssh.AuthMethod{}
+	if s.Config.PrivateKeyFile != "" {
+		signer, err := loadPrivateKey(s.Config.PrivateKeyFile, s.Config.PrivateKeyPassphrase)
+		if err != nil {
+			return nil, err
+		}
+		authMethods = append(authMethods, ssh.PublicKeys(signer))
+	}
+	if s.Config.Password != "" {
+		authMethods = append(authMethods, ssh.Password(s.Config.Password))
+	}
+	if len(authMethods) == 0 {
+		return nil, errors.New("no SFTP authentication method configured, set a password or a private key")
+	}
+
+	hostKeyCallback, err := s.makeHostKeyCallback()
+	if err != nil {
+		return nil, err
+	}
+
+	return &ssh.ClientConfig{
+		User:            s.Config.User,
+		Auth:            authMethods,
+		HostKeyCallback: hostKeyCallback,
+		Timeout:         time.Duration(s.Config.Timeout) * time.Second,
+	}, nil
+}
+
+func (s *SFTPServerConnection) makeHostKeyCallback() (ssh.HostKeyCallback, error) {
+	if s.Config.IgnoreHostKey {
+		return ssh.InsecureIgnoreHostKey(), nil
+	}
+
+	knownHostsFile := s.Config.KnownHostsFile
+	if knownHostsFile == "" {
+		homeDir, err := os.UserHomeDir()
+		if err != nil {
+			return nil, err
+		}
+		knownHostsFile = filepath.Join(homeDir, ".ssh", "known_hosts")
+	}
+	return knownhosts.New(knownHostsFile)
+}
+
+func loadPrivateKey(keyFile, passphrase string) (ssh.Signer, error) {
+	pemBytes, err := os.ReadFile(keyFile)
+	if err != nil {
+		return nil, fmt.Errorf("could not read the private key file '%s', got error '%s'", keyFile, err.Error())
+	}
+	if passphrase != "" {
+		return ssh.ParsePrivateKeyWithPassphrase(pemBytes, []byte(passphrase))
+	}
+	return ssh.ParsePrivateKey(pemBytes)
+}
+
+func (s *SFTPServerConnection) walkRemote() ([]string, error) {
+	w := s.client.Walk(s.serverFolder)
+	var remoteFiles []string
+	for w.Step() {
+		if err := w.Err(); err != nil {
+			return nil, err
+		}
+		if w.Stat().Mode().IsRegular() {
+			remoteFiles = append(remoteFiles, utils.ToBasePath(w.Path(), s.serverFolder))
+		}
+	}
+	return remoteFiles, nil
+}
+
+func (s *SFTPServerConnection) removeDirRecur(dirname string) error {
+	w := s.client.Walk(dirname)
+	var folders []string
+	for w.Step() {
+		if err := w.Err(); err != nil {
+			return err
+		}
+		if w.Stat().IsDir() {
+			folders = append(folders, w.Path())
+			continue
+		}
+		if err := s.client.Remove(w.Path()); err != nil {
+			return err
+		}
+	}
+	// remove nested folders first
+	for i := len(folders) - 1; i >= 0; i-- {
+		if err := s.client.RemoveDirectory(folders[i]); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (s *SFTPServerConnection) makeDir(dirname string) error {
+	return s.client.MkdirAll(path.Join(s.serverFolder, dirname))
+}
+
+// uploadLocalFile reads the file from appFs and uploads it to the remote folder.
+func (s *SFTPServerConnection) uploadLocalFile(appFs afero.Fs, file, localDir string, replaceBasePath bool) error {
+	fileBytes, err := afero.ReadFile(appFs, file)
+	if err != nil {
+		return err
+	}
+	remoteFile := file
+	if replaceBasePath {
+		remoteFile = utils.ToBasePath(file, localDir)
+	}
+	if err := s.uploadSingle(remoteFile, bytes.NewBuffer(fileBytes), false); err != nil {
+		return err
+	}
+	trackBytes(len(fileBytes))
+	return nil
+}
+
+func (s *SFTPServerConnection) uploadSingle(filename string, data *bytes.Buffer, dryRun bool) error {
+	if !dryRun {
+		f, err := s.client.Create(path.Join(s.serverFolder, filename))
+		if err != nil {
+			return err
+		}
+		if _, err := io.Copy(f, data); err != nil {
+			f.Close()
+			return err
+		}
+		// a failed close means the file on the server is truncated
+		return f.Close()
+	}
+	return nil
+}
+
+//=============================================================================
+
+func (s *SFTPServerConnection) createTarball(appFs afero.Fs, tarballFilePath string, filePaths []string, dryRun bool) error {
+	s.logger.Info("Creating the backup archive...")
+	// In-memory file system
+	memFs := afero.NewMemMapFs()
+	// Create a new archive file
+	file, err := appFs.Create(tarballFilePath)
+	if err != nil {
+		return fmt.Errorf("could not create tarball file '%s', got error '%s'", tarballFilePath, err.Error())
+	}
+	defer file.Close()
+
+	gzipWriter := gzip.NewWriter(file)
+	defer gzipWriter.Close()
+
+	tarWriter := tar.NewWriter(gzipWriter)
+	defer tarWriter.Close()
+
+	pbConfig := &progressbar.Config{
+		Items:          filePaths,
+		OnCompletesMsg: fmt.Sprintf("Backup done! Saved as: %s", tarballFilePath),
+		OnProgressCmd: func(path string) tea.Cmd {
+			return sftpCreateTarballTeaCmd(s, memFs, tarWriter, path, dryRun)
+		},
+	}
+
+	if err := runProgressBar(opBackup, pbConfig); err != nil {
+		return err
+	}
+
+	return nil
+}
@@ -0,0 +1,317 @@
+package ftpfs
+
+import (
+	"bytes"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/x509"
+	"encoding/pem"
+	"net"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"testing"
+
+	"github.com/matryer/is"
+	"github.com/pkg/sftp"
+	"github.com/sveltinio/yinlog"
+	"golang.org/x/crypto/ssh"
+	"golang.org/x/crypto/ssh/knownhosts"
+)
+
+const (
+	testSFTPUser     = "sveltin"
+	testSFTPPassword = "secret"
+)
+
+type testSFTPServer struct {
+	host      string
+	port      int
+	hostKey   ssh.PublicKey
+	clientKey string
+}
+
+// newTestSFTPServer starts an in-process SSH server exposing the sftp subsystem.
+// It accepts password authentication and the public key written to clientKey.
+func newTestSFTPServer(t *testing.T) *testSFTPServer {
+	t.Helper()
+	is := is.New(t)
+
+	hostSigner := newTestSigner(t)
+	clientKey, clientSigner := newTestPrivateKeyFile(t)
+
+	config := &ssh.ServerConfig{
+		PasswordCallback: func(c ssh.ConnMetadata, pass []byte) (*ssh.Permissions, error) {
+			if c.User() == testSFTPUser && string(pass) == testSFTPPassword {
+				return nil, nil
+			}
+			return nil, os.ErrPermission
+		},
+		PublicKeyCallback: func(c ssh.ConnMetadata, key ssh.PublicKey) (*ssh.Permissions, error) {
+			if c.User() == testSFTPUser && bytes.Equal(key.Marshal(), clientSigner.PublicKey().Marshal()) {
+				return nil, nil
+			}
+			return nil, os.ErrPermission
+		},
+	}
+	config.AddHostKey(hostSigner)
+
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	is.NoErr(err)
+	t.Cleanup(func() { listener.Close() })
+
+	go func() {
+		for {
+			nConn, err := listener.Accept()
+			if err != nil {
+				return
+			}
+			go serveTestSSHConn(nConn, config)
+		}
+	}()
+
+	host, port, err := net.SplitHostPort(listener.Addr().String())
+	is.NoErr(err)
+	portNum, err := strconv.Atoi(port)
+	is.NoErr(err)
+
+	return &testSFTPServer{
+		host:      host,
+		port:      portNum,
+		hostKey:   hostSigner.PublicKey(),
+		clientKey: clientKey,
+	}
+}
+
+func serveTestSSHConn(nConn net.Conn, config *ssh.ServerConfig) {
+	_, chans, reqs, err := ssh.NewServerConn(nConn, config)
+	if err != nil {
+		return
+	}
+	go ssh.DiscardRequests(reqs)
+
+	for newChannel := range chans {
+		if newChannel.ChannelType() != "session" {
+			newChannel.Reject(ssh.UnknownChannelType, "unknown channel type")
+			continue
+		}
+		channel, requests, err := newChannel.Accept()
+		if err != nil {
+			return
+		}
+		go func(in <-chan *ssh.Request) {
+			for req := range in {
+				ok := req.Type == "subsystem" && len(req.Payload) > 4 && string(req.Payload[4:]) == "sftp"
+				req.Reply(ok, nil)
+			}
+		}(requests)
+
+		server, err := sftp.NewServer(channel)
+		if err != nil {
+			return
+		}
+		server.Serve()
+		server.Close()
+	}
+}
+
+func newTestSigner(t *testing.T) ssh.Signer {
+	t.Helper()
+	is := is.New(t)
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	is.NoErr(err)
+	signer, err := ssh.NewSignerFromKey(key)
+	is.NoErr(err)
+	return signer
+}
+
+func newTestPrivateKeyFile(t *testing.T) (string, ssh.Signer) {
+	t.Helper()
+	is := is.New(t)
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	is.NoErr(err)
+	der, err := x509.MarshalECPrivateKey(key)
+	is.NoErr(err)
+	pemBytes := pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: der})
+
+	keyFile := filepath.Join(t.TempDir(), "id_ecdsa")
+	is.NoErr(os.WriteFile(keyFile, pemBytes, 0600))
+
+	signer, err := ssh.ParsePrivateKey(pemBytes)
+	is.NoErr(err)
+	return keyFile, signer
+}
+
+func newTestSFTPConnection(srv *testSFTPServer, config SFTPConnectionConfig) *SFTPServerConnection {
+	config.Host = srv.host
+	config.Port = srv.port
+	config.Timeout = 5
+	conn := NewSFTPServerConnection(&config)
+	conn.SetLogger(yinlog.New())
+	return conn
+}
+
+func TestSFTPLogin(t *testing.T) {
+	srv := newTestSFTPServer(t)
+
+	knownHostsFile := filepath.Join(t.TempDir(), "known_hosts")
+	line := knownhosts.Line([]string{net.JoinHostPort(srv.host, strconv.Itoa(srv.port))}, srv.hostKey)
+	if err := os.WriteFile(knownHostsFile, []byte(line+"\n"), 0600); err != nil {
+		t.Fatal(err)
+	}
+	otherKnownHostsFile := filepath.Join(t.TempDir(), "known_hosts")
+	otherLine := knownhosts.Line([]string{net.JoinHostPort(srv.host, strconv.Itoa(srv.port))}, newTestSigner(t).PublicKey())
+	if err := os.WriteFile(otherKnownHostsFile, []byte(otherLine+"\n"), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name    string
+		config  SFTPConnectionConfig
+		wantErr bool
+	}{
+		{
+			name:   "password",
+			config: SFTPConnectionConfig{User: testSFTPUser, Password: testSFTPPassword, IgnoreHostKey: true},
+		},
+		{
+			name:    "wrong password",
+			config:  SFTPConnectionConfig{User: testSFTPUser, Password: "wrong", IgnoreHostKey: true},
+			wantErr: true,
+		},
+		{
+			name:   "private key",
+			config: SFTPConnectionConfig{User: testSFTPUser, PrivateKeyFile: srv.clientKey, IgnoreHostKey: true},
+		},
+		{
+			name:    "no auth method",
+			config:  SFTPConnectionConfig{User: testSFTPUser, IgnoreHostKey: true},
+			wantErr: true,
+		},
+		{
+			name:   "known host",
+			config: SFTPConnectionConfig{User: testSFTPUser, Password: testSFTPPassword, KnownHostsFile: knownHostsFile},
+		},
+		{
+			name:    "host key mismatch",
+			config:  SFTPConnectionConfig{User: testSFTPUser, Password: testSFTPPassword, KnownHostsFile: otherKnownHostsFile},
+			wantErr: true,
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			is := is.New(t)
+			conn := newTestSFTPConnection(srv, tc.config)
+
+			is.NoErr(DialAction(conn).Run())
+			err := LoginAction(conn).Run()
+			if tc.wantErr {
+				is.True(err != nil)
+				return
+			}
+			is.NoErr(err)
+			is.NoErr(IdleAction(conn).Run())
+			is.NoErr(LogoutAction(conn).Run())
+		})
+	}
+}
+
+func TestSFTPRemoteOperations(t *testing.T) {
+	is := is.New(t)
+	srv := newTestSFTPServer(t)
+	remoteFolder := t.TempDir()
+
+	conn := newTestSFTPConnection(srv, SFTPConnectionConfig{User: testSFTPUser, Password: testSFTPPassword, IgnoreHostKey: true})
+	conn.SetRootFolder(remoteFolder)
+	is.NoErr(conn.Dial())
+	is.NoErr(conn.Login())
+	defer conn.Logout()
+
+	is.NoErr(conn.makeDir("posts/first"))
+	is.NoErr(conn.uploadSingle("index.html", bytes.NewBufferString("<h1>home</h1>"), false))
+	is.NoErr(conn.uploadSingle(".htaccess", bytes.NewBufferString("Options -Indexes"), false))
+	is.NoErr(conn.uploadSingle("posts/first/index.html", bytes.NewBufferString("<h1>first</h1>"), false))
+	// dry-run must not write anything
+	is.NoErr(conn.uploadSingle("dryrun.html", bytes.NewBufferString(""), true))
+
+	remoteFiles, err := conn.walkRemote()
+	is.NoErr(err)
+	sort.Strings(remoteFiles)
+	is.Equal(remoteFiles, []string{".htaccess", "index.html", "posts/first/index.html"})
+
+	content, err := conn.ReadFile("posts/first/index.html")
+	is.NoErr(err)
+	is.Equal(string(content), "<h1>first</h1>")
+
+	// dry-run must not delete anything
+	is.NoErr(DeleteAllAction(conn, []string{".htaccess"}, true).Run())
+	remoteFiles, err = conn.walkRemote()
+	is.NoErr(err)
+	is.Equal(len(remoteFiles), 3)
+
+	is.NoErr(DeleteAllAction(conn, []string{".htaccess"}, false).Run())
+	remoteFiles, err = conn.walkRemote()
+	is.NoErr(err)
+	is.Equal(remoteFiles, []string{".htaccess"})
+
+	_, err = os.Stat(filepath.Join(remoteFolder, "posts"))
+	is.True(os.IsNotExist(err))
+}
+
+func TestSFTPSwap(t *testing.T) {
+	is := is.New(t)
+	srv := newTestSFTPServer(t)
+	baseFolder := t.TempDir()
+	liveFolder := filepath.Join(baseFolder, "www")
+	stagingFolder := liveFolder + ".staging"
+	previousFolder := liveFolder + ".previous"
+
+	conn := newTestSFTPConnection(srv, SFTPConnectionConfig{User: testSFTPUser, Password: testSFTPPassword, IgnoreHostKey: true})
+	is.NoErr(conn.Dial())
+	is.NoErr(conn.Login())
+	defer conn.Logout()
+
+	deployTo := func(folder, content string) {
+		conn.SetRootFolder(folder)
+		is.NoErr(conn.makeDir(""))
+		is.NoErr(conn.WriteFile("index.html", []byte(content), false))
+	}
+	readFrom := func(folder string) string {
+		content, err := os.ReadFile(filepath.Join(folder, "index.html"))
+		is.NoErr(err)
+		return string(content)
+	}
+
+	// first release: no live folder yet
+	deployTo(stagingFolder, "v1")
+	conn.SetRootFolder(liveFolder)
+	is.NoErr(SwapAction(conn, stagingFolder, previousFolder, false).Run())
+	is.Equal(readFrom(liveFolder), "v1")
+	is.True(!conn.dirExists(stagingFolder))
+	is.True(!conn.dirExists(previousFolder))
+
+	// dry-run does not touch anything
+	deployTo(stagingFolder, "v2")
+	conn.SetRootFolder(liveFolder)
+	is.NoErr(SwapAction(conn, stagingFolder, previousFolder, true).Run())
+	is.Equal(readFrom(liveFolder), "v1")
+
+	is.NoErr(SwapAction(conn, stagingFolder, previousFolder, false).Run())
+	is.Equal(readFrom(liveFolder), "v2")
+	is.Equal(readFrom(previousFolder), "v1")
+
+	// the previous release is replaced on the next swap
+	deployTo(stagingFolder, "v3")
+	conn.SetRootFolder(liveFolder)
+	is.NoErr(SwapAction(conn, stagingFolder, previousFolder, false).Run())
+	is.Equal(readFrom(liveFolder), "v3")
+	is.Equal(readFrom(previousFolder), "v2")
+
+	is.NoErr(RemoveDirAction(conn, previousFolder, false).Run())
+	is.True(!conn.dirExists(previousFolder))
+	// removing a missing folder is not an error
+	is.NoErr(RemoveDirAction(conn, previousFolder, false).Run())
+}
@@ -2,19 +2,18 @@ package ftpfs
 
 import (
 	"archive/tar"
-	"bytes"
 	"io"
+	"path"
 	"path/filepath"
 
 	tea "github.com/charmbracelet/bubbletea"
 	"github.com/spf13/afero"
 	"github.com/sveltinio/prompti/progressbar"
-	"github.com/sveltinio/sveltin/utils"
 )
 
 func mkDirTeaCmd(s *FTPServerConnection, path string, dryRun bool) tea.Cmd {
 	if !dryRun {
-		if err := s.client.MakeDir(path); err != nil {
+		if err := s.retry(func() error { return s.client.MakeDir(path) }); err != nil {
 			return func() tea.Msg {
 				return progressbar.IncrementErrMsg{Err: err}
 			}
@@ -28,28 +27,29 @@ func mkDirTeaCmd(s *FTPServerConnection, path string, dryRun bool) tea.Cmd {
 
 func uploadFileTeaCmd(s *FTPServerConnection, appFs afero.Fs, file, path string, replaceBasePath, dryRun bool) tea.Cmd {
 	if !dryRun {
-		fileBytes, err := afero.ReadFile(appFs, file)
+		err := s.retry(func() error { return s.uploadLocalFile(appFs, file, path, replaceBasePath) })
 		if err != nil {
 			return func() tea.Msg {
 				return progressbar.IncrementErrMsg{Err: err}
 			}
 		}
-		var remoteFile string
-		if replaceBasePath {
-			remoteFile = utils.ToBasePath(file, path)
-		} else {
-			remoteFile = file
-		}
+	}
+	return func() tea.Msg {
+		return progressbar.IncrementMsg(path)
+	}
+}
 
-		if err = s.uploadSingle(remoteFile, bytes.NewBuffer(fileBytes), dryRun); err != nil {
+func deleteFileTeaCmd(s *FTPServerConnection, file string, dryRun bool) tea.Cmd {
+	if !dryRun {
+		if err := s.retry(func() error { return s.client.Delete(filepath.Join(s.serverFolder, file)) }); err != nil {
 			return func() tea.Msg {
 				return progressbar.IncrementErrMsg{Err: err}
 			}
 		}
-
 	}
+
 	return func() tea.Msg {
-		return progressbar.IncrementMsg(path)
+		return progressbar.IncrementMsg(file)
 	}
 }
 
@@ -98,3 +98,187 @@ func createTarballTeaCmd(s *FTPServerConnection, memFs afero.Fs, tarWriter *tar.
 		return progressbar.IncrementMsg(fName)
 	}
 }
+
+//=============================================================================
+
+func sftpMkDirTeaCmd(s *SFTPServerConnection, path string, dryRun bool) tea.Cmd {
+	if !dryRun {
+		if err := s.makeDir(path); err != nil {
+			return func() tea.Msg {
+				return progressbar.IncrementErrMsg{Err: err}
+			}
+		}
+	}
+
+	return func() tea.Msg {
+		return progressbar.IncrementMsg(path)
+	}
+}
+
+func sftpUploadFileTeaCmd(s *SFTPServerConnection, appFs afero.Fs, file, path string, replaceBasePath, dryRun bool) tea.Cmd {
+	if !dryRun {
+		if err := s.uploadLocalFile(appFs, file, path, replaceBasePath); err != nil {
+			return func() tea.Msg {
+				return progressbar.IncrementErrMsg{Err: err}
+			}
+		}
+	}
+	return func() tea.Msg {
+		return progressbar.IncrementMsg(path)
+	}
+}
+
+func sftpDeleteFileTeaCmd(s *SFTPServerConnection, file string, dryRun bool) tea.Cmd {
+	if !dryRun {
+		if err := s.client.Remove(path.Join(s.serverFolder, file)); err != nil {
+			return func() tea.Msg {
+				return progressbar.IncrementErrMsg{Err: err}
+			}
+		}
+	}
+
+	return func() tea.Msg {
+		return progressbar.IncrementMsg(file)
+	}
+}
+
+func sftpCreateTarballTeaCmd(s *SFTPServerConnection, memFs afero.Fs, tarWriter *tar.Writer, file string, dryRun bool) tea.Cmd {
+	fName := filepath.Base(file)
+
+	if !dryRun {
+		// fetch the file from the remote SFTP server
+		buf, err := s.ReadFile(file)
+		if err != nil {
+			return func() tea.Msg {
+				return progressbar.IncrementErrMsg{Err: err}
+			}
+		}
+		// save file in the memory backed filesystem
+		if err := afero.WriteFile(memFs, file, buf, 0777); err != nil {
+			return func() tea.Msg {
+				return progressbar.IncrementErrMsg{Err: err}
+			}
+		}
+		// add the file to the tar archive
+		if err := addToTarWriter(memFs, file, tarWriter); err != nil {
+			return func() tea.Msg {
+				return progressbar.IncrementErrMsg{Err: err}
+			}
+		}
+	}
+
+	return func() tea.Msg {
+		return progressbar.IncrementMsg(fName)
+	}
+}
+
+func localMkDirTeaCmd(s *LocalDirConnection, path string, dryRun bool) tea.Cmd {
+	if !dryRun {
+		if err := s.makeDir(path); err != nil {
+			return func() tea.Msg {
+				return progressbar.IncrementErrMsg{Err: err}
+			}
+		}
+	}
+
+	return func() tea.Msg {
+		return progressbar.IncrementMsg(path)
+	}
+}
+
+func localCopyFileTeaCmd(s *LocalDirConnection, appFs afero.Fs, file, path string, replaceBasePath, dryRun bool) tea.Cmd {
+	if !dryRun {
+		if err := s.copyLocalFile(appFs, file, path, replaceBasePath); err != nil {
+			return func() tea.Msg {
+				return progressbar.IncrementErrMsg{Err: err}
+			}
+		}
+	}
+	return func() tea.Msg {
+		return progressbar.IncrementMsg(path)
+	}
+}
+
+func localDeleteFileTeaCmd(s *LocalDirConnection, file string, dryRun bool) tea.Cmd {
+	if !dryRun {
+		if err := s.fs.Remove(filepath.Join(s.serverFolder, file)); err != nil {
+			return func() tea.Msg {
+				return progressbar.IncrementErrMsg{Err: err}
+			}
+		}
+	}
+
+	return func() tea.Msg {
+		return progressbar.IncrementMsg(file)
+	}
+}
+
+func localCreateTarballTeaCmd(rootFs afero.Fs, tarWriter *tar.Writer, file string, dryRun bool) tea.Cmd {
+	if !dryRun {
+		// add the file to the tar archive
+		if err := addToTarWriter(rootFs, file, tarWriter); err != nil {
+			return func() tea.Msg {
+				return progressbar.IncrementErrMsg{Err: err}
+			}
+		}
+	}
+
+	return func() tea.Msg {
+		return progressbar.IncrementMsg(file)
+	}
+}
+
+func s3UploadFileTeaCmd(s *S3ServerConnection, appFs afero.Fs, file, path string, replaceBasePath, dryRun bool) tea.Cmd {
+	if !dryRun {
+		if err := s.uploadLocalFile(appFs, file, path, replaceBasePath); err != nil {
+			return func() tea.Msg {
+				return progressbar.IncrementErrMsg{Err: err}
+			}
+		}
+	}
+	return func() tea.Msg {
+		return progressbar.IncrementMsg(path)
+	}
+}
+
+func s3DeleteFileTeaCmd(s *S3ServerConnection, file string, dryRun bool) tea.Cmd {
+	if !dryRun {
+		if err := s.removeObject(s.objectKey(file)); err != nil {
+			return func() tea.Msg {
+				return progressbar.IncrementErrMsg{Err: err}
+			}
+		}
+	}
+
+	return func() tea.Msg {
+		return progressbar.IncrementMsg(file)
+	}
+}
+
+func s3CreateTarballTeaCmd(s *S3ServerConnection, memFs afero.Fs, tarWriter *tar.Writer, file string, dryRun bool) tea.Cmd {
+	if !dryRun {
+		// fetch the object from the bucket
+		buf, err := s.ReadFile(file)
+		if err != nil {
+			return func() tea.Msg {
+				return progressbar.IncrementErrMsg{Err: err}
+			}
+		}
+		// save file in the memory backed filesystem
+		if err := afero.WriteFile(memFs, file, buf, 0777); err != nil {
+			return func() tea.Msg {
+				return progressbar.IncrementErrMsg{Err: err}
+			}
+		}
+		// add the file to the tar archive
+		if err := addToTarWriter(memFs, file, tarWriter); err != nil {
+			return func() tea.Msg {
+				return progressbar.IncrementErrMsg{Err: err}
+			}
+		}
+	}
+
+	return func() tea.Msg {
+		return progressbar.IncrementMsg(file)
+	}
+}
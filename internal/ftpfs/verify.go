@@ -0,0 +1,80 @@
+/**
+ * Copyright © 2021-present Sveltin contributors <github@sveltin.io>
+ *
+ * Use of this source code is governed by Apache 2.0 license
+ * that can be found in the LICENSE file.
+ */
+
+package ftpfs
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"sort"
+)
+
+// VerifyResult is the struct with the remote file paths not matching the local build.
+type VerifyResult struct {
+	Missing    []string `json:"missing"`
+	Extra      []string `json:"extra"`
+	Mismatched []string `json:"mismatched"`
+	Verified   int      `json:"verified"`
+}
+
+// HasDrift returns true when the remote folder does not match the local build.
+func (r *VerifyResult) HasDrift() bool {
+	return len(r.Missing) > 0 || len(r.Extra) > 0 || len(r.Mismatched) > 0
+}
+
+// Verify compares the files within the remote folder with the local manifest: files missing
+// on the remote folder, extra remote files and files with a different size. When withChecksums
+// is true, the remote files are downloaded to compare their SHA-256 checksum too.
+// The manifest file and the remote files matching the exclude rules are not reported as extra.
+func Verify(server RemoteServer, local *Manifest, rules *ExcludeRules, withChecksums bool) (*VerifyResult, error) {
+	remoteFiles, err := server.ListFiles()
+	if err != nil {
+		return nil, err
+	}
+
+	result := &VerifyResult{
+		Missing:    []string{},
+		Extra:      []string{},
+		Mismatched: []string{},
+	}
+	found := map[string]bool{}
+	for _, remoteFile := range remoteFiles {
+		found[remoteFile.Path] = true
+		entry, exists := local.Files[remoteFile.Path]
+		if !exists {
+			if remoteFile.Path != ManifestFilename && !rules.Excluded(remoteFile.Path, false) {
+				result.Extra = append(result.Extra, remoteFile.Path)
+			}
+			continue
+		}
+
+		if remoteFile.Size != entry.Size {
+			result.Mismatched = append(result.Mismatched, remoteFile.Path)
+			continue
+		}
+		if withChecksums {
+			content, err := server.ReadFile(remoteFile.Path)
+			if err != nil {
+				return nil, err
+			}
+			sum := sha256.Sum256(content)
+			if hex.EncodeToString(sum[:]) != entry.Hash {
+				result.Mismatched = append(result.Mismatched, remoteFile.Path)
+				continue
+			}
+		}
+		result.Verified++
+	}
+
+	for file := range local.Files {
+		if !found[file] {
+			result.Missing = append(result.Missing, file)
+		}
+	}
+	sort.Strings(result.Missing)
+	return result, nil
+}
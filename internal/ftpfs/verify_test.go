@@ -0,0 +1,52 @@
+package ftpfs
+
+import (
+	"testing"
+
+	"github.com/matryer/is"
+	"github.com/spf13/afero"
+)
+
+func TestVerify(t *testing.T) {
+	is := is.New(t)
+	fs := afero.NewMemMapFs()
+	is.NoErr(afero.WriteFile(fs, "build/index.html", []byte("<h1>home</h1>"), 0644))
+	is.NoErr(afero.WriteFile(fs, "build/about/index.html", []byte("<h1>about</h1>"), 0644))
+	is.NoErr(afero.WriteFile(fs, "build/posts/index.html", []byte("<h1>posts</h1>"), 0644))
+	is.NoErr(afero.WriteFile(fs, "build/logo.svg", []byte("<svg/>"), 0644))
+
+	local := NewManifest()
+	is.NoErr(local.AddFiles(fs, "build", []string{"build/index.html", "build/about/index.html", "build/posts/index.html", "build/logo.svg"}, true))
+
+	conn := newTestLocalDirConnection(fs, "/srv/www/site")
+	is.NoErr(WriteFileAction(conn, "index.html", []byte("<h1>home</h1>"), false).Run())
+	is.NoErr(WriteFileAction(conn, "about/index.html", []byte("<h1>about</h1>"), false).Run())
+	// same size, different content
+	is.NoErr(WriteFileAction(conn, "posts/index.html", []byte("<h1>POSTS</h1>"), false).Run())
+	is.NoErr(WriteFileAction(conn, "old.html", []byte(""), false).Run())
+	is.NoErr(WriteFileAction(conn, ".htaccess", []byte("Options -Indexes"), false).Run())
+	is.NoErr(WriteFileAction(conn, ManifestFilename, []byte("{}"), false).Run())
+
+	rules := NewExcludeRules([]string{".htaccess"})
+	result, err := Verify(conn, local, rules, false)
+	is.NoErr(err)
+	is.True(result.HasDrift())
+	is.Equal(result.Missing, []string{"logo.svg"})
+	is.Equal(result.Extra, []string{"old.html"})
+	is.Equal(result.Mismatched, []string{})
+	is.Equal(result.Verified, 3)
+
+	// checksums catch the changes keeping the same size
+	result, err = Verify(conn, local, rules, true)
+	is.NoErr(err)
+	is.Equal(result.Mismatched, []string{"posts/index.html"})
+	is.Equal(result.Verified, 2)
+
+	is.NoErr(WriteFileAction(conn, "posts/index.html", []byte("<h1>posts</h1>"), false).Run())
+	is.NoErr(WriteFileAction(conn, "logo.svg", []byte("<svg/>"), false).Run())
+	is.NoErr(fs.Remove("/srv/www/site/old.html"))
+	result, err = Verify(conn, local, rules, true)
+	is.NoErr(err)
+	is.True(!result.HasDrift())
+	is.Equal(result.Verified, 4)
+}
@@ -43,15 +43,15 @@ func (m *AddUpdateProjectSettings) MakeMigration(migrationManager *MigrationMana
 func (m *AddUpdateProjectSettings) getServices() *MigrationServices { return m.Services }
 func (m *AddUpdateProjectSettings) getData() *MigrationData         { return m.Data }
 
-// Migrate return error if migration execution over up and down methods fails.
+// Migrate runs up and, when it fails, down to undo the changes made to the target files.
 func (m AddUpdateProjectSettings) Migrate() error {
 	if err := m.up(); err != nil {
+		if downErr := m.down(); downErr != nil {
+			return downErr
+		}
 		return err
 	}
-	if err := m.down(); err != nil {
-		return err
-	}
-	return nil
+	return m.Mediator.notifyAboutCompletion()
 }
 
 func (m *AddUpdateProjectSettings) up() error {
@@ -72,10 +72,7 @@ func (m *AddUpdateProjectSettings) up() error {
 }
 
 func (m *AddUpdateProjectSettings) down() error {
-	if err := m.Mediator.notifyAboutCompletion(); err != nil {
-		return err
-	}
-	return nil
+	return restoreTarget(m)
 }
 
 func (m *AddUpdateProjectSettings) allowUp() error {
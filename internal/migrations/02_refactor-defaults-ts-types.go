@@ -35,15 +35,15 @@ func (m *RefactorDefaultsTSTypes) MakeMigration(migrationManager *MigrationManag
 func (m *RefactorDefaultsTSTypes) getServices() *MigrationServices { return m.Services }
 func (m *RefactorDefaultsTSTypes) getData() *MigrationData         { return m.Data }
 
-// Migrate return error if migration execution over up and down methods fails (IMigration interface).
+// Migrate runs up and, when it fails, down to undo the changes made to the target files (IMigration interface).
 func (m RefactorDefaultsTSTypes) Migrate() error {
 	if err := m.up(); err != nil {
+		if downErr := m.down(); downErr != nil {
+			return downErr
+		}
 		return err
 	}
-	if err := m.down(); err != nil {
-		return err
-	}
-	return nil
+	return m.Mediator.notifyAboutCompletion()
 }
 
 func (m *RefactorDefaultsTSTypes) up() error {
@@ -77,10 +77,7 @@ func (m *RefactorDefaultsTSTypes) up() error {
 }
 
 func (m *RefactorDefaultsTSTypes) down() error {
-	if err := m.Mediator.notifyAboutCompletion(); err != nil {
-		return err
-	}
-	return nil
+	return restoreTarget(m)
 }
 
 func (m *RefactorDefaultsTSTypes) allowUp() error {
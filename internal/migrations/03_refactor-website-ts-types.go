@@ -37,15 +37,15 @@ func (m *RefactorWebSiteTSTypes) MakeMigration(migrationManager *MigrationManage
 func (m *RefactorWebSiteTSTypes) getServices() *MigrationServices { return m.Services }
 func (m *RefactorWebSiteTSTypes) getData() *MigrationData         { return m.Data }
 
-// Migrate return error if migration execution over up and down methods fails (IMigration interface).
+// Migrate runs up and, when it fails, down to undo the changes made to the target files (IMigration interface).
 func (m RefactorWebSiteTSTypes) Migrate() error {
 	if err := m.up(); err != nil {
+		if downErr := m.down(); downErr != nil {
+			return downErr
+		}
 		return err
 	}
-	if err := m.down(); err != nil {
-		return err
-	}
-	return nil
+	return m.Mediator.notifyAboutCompletion()
 }
 
 func (m *RefactorWebSiteTSTypes) up() error {
@@ -121,10 +121,7 @@ func (m *RefactorWebSiteTSTypes) runMigration(content []byte, file string) ([]by
 }
 
 func (m *RefactorWebSiteTSTypes) down() error {
-	if err := m.Mediator.notifyAboutCompletion(); err != nil {
-		return err
-	}
-	return nil
+	return restoreTarget(m)
 }
 
 func (m *RefactorWebSiteTSTypes) allowUp() error {
@@ -35,15 +35,15 @@ func (m *RefactorMenuTSTypes) MakeMigration(migrationManager *MigrationManager,
 func (m *RefactorMenuTSTypes) getServices() *MigrationServices { return m.Services }
 func (m *RefactorMenuTSTypes) getData() *MigrationData         { return m.Data }
 
-// Migrate return error if migration execution over up and down methods fails (IMigration interface).
+// Migrate runs up and, when it fails, down to undo the changes made to the target files (IMigration interface).
 func (m RefactorMenuTSTypes) Migrate() error {
 	if err := m.up(); err != nil {
+		if downErr := m.down(); downErr != nil {
+			return downErr
+		}
 		return err
 	}
-	if err := m.down(); err != nil {
-		return err
-	}
-	return nil
+	return m.Mediator.notifyAboutCompletion()
 }
 
 func (m *RefactorMenuTSTypes) up() error {
@@ -105,10 +105,7 @@ func (m *RefactorMenuTSTypes) runMigration(content []byte, file string) ([]byte,
 }
 
 func (m *RefactorMenuTSTypes) down() error {
-	if err := m.Mediator.notifyAboutCompletion(); err != nil {
-		return err
-	}
-	return nil
+	return restoreTarget(m)
 }
 
 func (m *RefactorMenuTSTypes) allowUp() error {
@@ -36,15 +36,15 @@ func (m *OverwriteSveltinDTS) MakeMigration(migrationManager *MigrationManager,
 func (m *OverwriteSveltinDTS) getServices() *MigrationServices { return m.Services }
 func (m *OverwriteSveltinDTS) getData() *MigrationData         { return m.Data }
 
-// Migrate return error if migration execution over up and down methods fails.
+// Migrate runs up and, when it fails, down to undo the changes made to the target files.
 func (m OverwriteSveltinDTS) Migrate() error {
 	if err := m.up(); err != nil {
+		if downErr := m.down(); downErr != nil {
+			return downErr
+		}
 		return err
 	}
-	if err := m.down(); err != nil {
-		return err
-	}
-	return nil
+	return m.Mediator.notifyAboutCompletion()
 }
 
 func (m *OverwriteSveltinDTS) up() error {
@@ -77,10 +77,7 @@ func (m *OverwriteSveltinDTS) up() error {
 }
 
 func (m *OverwriteSveltinDTS) down() error {
-	if err := m.Mediator.notifyAboutCompletion(); err != nil {
-		return err
-	}
-	return nil
+	return restoreTarget(m)
 }
 
 func (m *OverwriteSveltinDTS) allowUp() error {
@@ -36,15 +36,15 @@ func (m *RefactorResourcesLibsTypes) MakeMigration(migrationManager *MigrationMa
 func (m *RefactorResourcesLibsTypes) getServices() *MigrationServices { return m.Services }
 func (m *RefactorResourcesLibsTypes) getData() *MigrationData         { return m.Data }
 
-// Migrate return error if migration execution over up and down methods fails (IMigration interface).
+// Migrate runs up and, when it fails, down to undo the changes made to the target files (IMigration interface).
 func (m RefactorResourcesLibsTypes) Migrate() error {
 	if err := m.up(); err != nil {
+		if downErr := m.down(); downErr != nil {
+			return downErr
+		}
 		return err
 	}
-	if err := m.down(); err != nil {
-		return err
-	}
-	return nil
+	return m.Mediator.notifyAboutCompletion()
 }
 
 func (m *RefactorResourcesLibsTypes) up() error {
@@ -155,10 +155,7 @@ func (m *RefactorResourcesLibsTypes) runMigration(content []byte, file string) (
 }
 
 func (m *RefactorResourcesLibsTypes) down() error {
-	if err := m.Mediator.notifyAboutCompletion(); err != nil {
-		return err
-	}
-	return nil
+	return restoreTarget(m)
 }
 
 func (m *RefactorResourcesLibsTypes) allowUp() error {
@@ -35,15 +35,15 @@ func (m *AddPrerenderTrailingToLayoutTS) MakeMigration(migrationManager *Migrati
 func (m *AddPrerenderTrailingToLayoutTS) getServices() *MigrationServices { return m.Services }
 func (m *AddPrerenderTrailingToLayoutTS) getData() *MigrationData         { return m.Data }
 
-// Migrate return error if migration execution over up and down methods fails (IMigration interface).
+// Migrate runs up and, when it fails, down to undo the changes made to the target files (IMigration interface).
 func (m AddPrerenderTrailingToLayoutTS) Migrate() error {
 	if err := m.up(); err != nil {
+		if downErr := m.down(); downErr != nil {
+			return downErr
+		}
 		return err
 	}
-	if err := m.down(); err != nil {
-		return err
-	}
-	return nil
+	return m.Mediator.notifyAboutCompletion()
 }
 
 func (m *AddPrerenderTrailingToLayoutTS) up() error {
@@ -81,10 +81,7 @@ func (m *AddPrerenderTrailingToLayoutTS) up() error {
 }
 
 func (m *AddPrerenderTrailingToLayoutTS) down() error {
-	if err := m.Mediator.notifyAboutCompletion(); err != nil {
-		return err
-	}
-	return nil
+	return restoreTarget(m)
 }
 
 func (m *AddPrerenderTrailingToLayoutTS) allowUp() error {
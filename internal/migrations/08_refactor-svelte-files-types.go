@@ -36,15 +36,15 @@ func (m *RefactorSvelteFilesTypes) MakeMigration(migrationManager *MigrationMana
 func (m *RefactorSvelteFilesTypes) getServices() *MigrationServices { return m.Services }
 func (m *RefactorSvelteFilesTypes) getData() *MigrationData         { return m.Data }
 
-// Migrate return error if migration execution over up and down methods fails (IMigration interface).
+// Migrate runs up and, when it fails, down to undo the changes made to the target files (IMigration interface).
 func (m RefactorSvelteFilesTypes) Migrate() error {
 	if err := m.up(); err != nil {
+		if downErr := m.down(); downErr != nil {
+			return downErr
+		}
 		return err
 	}
-	if err := m.down(); err != nil {
-		return err
-	}
-	return nil
+	return m.Mediator.notifyAboutCompletion()
 }
 
 func (m *RefactorSvelteFilesTypes) up() error {
@@ -132,10 +132,7 @@ func (m *RefactorSvelteFilesTypes) runMigration(content []byte, file string) ([]
 }
 
 func (m *RefactorSvelteFilesTypes) down() error {
-	if err := m.Mediator.notifyAboutCompletion(); err != nil {
-		return err
-	}
-	return nil
+	return restoreTarget(m)
 }
 
 func (m *RefactorSvelteFilesTypes) allowUp() error {
@@ -36,15 +36,15 @@ func (m *RefactorPageServerTSTypes) MakeMigration(migrationManager *MigrationMan
 func (m *RefactorPageServerTSTypes) getServices() *MigrationServices { return m.Services }
 func (m *RefactorPageServerTSTypes) getData() *MigrationData         { return m.Data }
 
-// Migrate return error if migration execution over up and down methods fails (IMigration interface).
+// Migrate runs up and, when it fails, down to undo the changes made to the target files (IMigration interface).
 func (m RefactorPageServerTSTypes) Migrate() error {
 	if err := m.up(); err != nil {
+		if downErr := m.down(); downErr != nil {
+			return downErr
+		}
 		return err
 	}
-	if err := m.down(); err != nil {
-		return err
-	}
-	return nil
+	return m.Mediator.notifyAboutCompletion()
 }
 
 func (m *RefactorPageServerTSTypes) up() error {
@@ -137,10 +137,7 @@ func (m *RefactorPageServerTSTypes) runMigration(content []byte, file string) ([
 }
 
 func (m *RefactorPageServerTSTypes) down() error {
-	if err := m.Mediator.notifyAboutCompletion(); err != nil {
-		return err
-	}
-	return nil
+	return restoreTarget(m)
 }
 
 func (m *RefactorPageServerTSTypes) allowUp() error {
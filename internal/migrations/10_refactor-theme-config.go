@@ -35,15 +35,15 @@ func (m *RefactorThemeConfig) MakeMigration(migrationManager *MigrationManager,
 func (m *RefactorThemeConfig) getServices() *MigrationServices { return m.Services }
 func (m *RefactorThemeConfig) getData() *MigrationData         { return m.Data }
 
-// Migrate return error if migration execution over up and down methods fails.
+// Migrate runs up and, when it fails, down to undo the changes made to the target files.
 func (m RefactorThemeConfig) Migrate() error {
 	if err := m.up(); err != nil {
+		if downErr := m.down(); downErr != nil {
+			return downErr
+		}
 		return err
 	}
-	if err := m.down(); err != nil {
-		return err
-	}
-	return nil
+	return m.Mediator.notifyAboutCompletion()
 }
 
 func (m *RefactorThemeConfig) up() error {
@@ -79,10 +79,7 @@ func (m *RefactorThemeConfig) up() error {
 }
 
 func (m *RefactorThemeConfig) down() error {
-	if err := m.Mediator.notifyAboutCompletion(); err != nil {
-		return err
-	}
-	return nil
+	return restoreTarget(m)
 }
 
 func (m *RefactorThemeConfig) allowUp() error {
@@ -38,15 +38,15 @@ func (m *UpdateMDsveXPlugins) MakeMigration(migrationManager *MigrationManager,
 func (m *UpdateMDsveXPlugins) getServices() *MigrationServices { return m.Services }
 func (m *UpdateMDsveXPlugins) getData() *MigrationData         { return m.Data }
 
-// Migrate return error if migration execution over up and down methods fails (IMigration interface).
+// Migrate runs up and, when it fails, down to undo the changes made to the target files (IMigration interface).
 func (m UpdateMDsveXPlugins) Migrate() error {
 	if err := m.up(); err != nil {
+		if downErr := m.down(); downErr != nil {
+			return downErr
+		}
 		return err
 	}
-	if err := m.down(); err != nil {
-		return err
-	}
-	return nil
+	return m.Mediator.notifyAboutCompletion()
 }
 
 func (m *UpdateMDsveXPlugins) up() error {
@@ -89,10 +89,7 @@ func (m *UpdateMDsveXPlugins) up() error {
 }
 
 func (m *UpdateMDsveXPlugins) down() error {
-	if err := m.Mediator.notifyAboutCompletion(); err != nil {
-		return err
-	}
-	return nil
+	return restoreTarget(m)
 }
 
 func (m *UpdateMDsveXPlugins) allowUp() error {
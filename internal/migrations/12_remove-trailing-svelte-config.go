@@ -36,15 +36,15 @@ func (m *RemoveTrailingFromSvelteConfig) MakeMigration(migrationManager *Migrati
 func (m *RemoveTrailingFromSvelteConfig) getServices() *MigrationServices { return m.Services }
 func (m *RemoveTrailingFromSvelteConfig) getData() *MigrationData         { return m.Data }
 
-// Migrate return error if migration execution over up and down methods fails (IMigration interface).
+// Migrate runs up and, when it fails, down to undo the changes made to the target files (IMigration interface).
 func (m RemoveTrailingFromSvelteConfig) Migrate() error {
 	if err := m.up(); err != nil {
+		if downErr := m.down(); downErr != nil {
+			return downErr
+		}
 		return err
 	}
-	if err := m.down(); err != nil {
-		return err
-	}
-	return nil
+	return m.Mediator.notifyAboutCompletion()
 }
 
 func (m *RemoveTrailingFromSvelteConfig) up() error {
@@ -79,10 +79,7 @@ func (m *RemoveTrailingFromSvelteConfig) up() error {
 }
 
 func (m *RemoveTrailingFromSvelteConfig) down() error {
-	if err := m.Mediator.notifyAboutCompletion(); err != nil {
-		return err
-	}
-	return nil
+	return restoreTarget(m)
 }
 
 func (m *RemoveTrailingFromSvelteConfig) allowUp() error {
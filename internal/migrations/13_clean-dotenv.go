@@ -37,15 +37,15 @@ func (m *CleanDotEnv) MakeMigration(migrationManager *MigrationManager, services
 func (m *CleanDotEnv) getServices() *MigrationServices { return m.Services }
 func (m *CleanDotEnv) getData() *MigrationData         { return m.Data }
 
-// Migrate return error if migration execution over up and down methods fails (IMigration interface).
+// Migrate runs up and, when it fails, down to undo the changes made to the target files (IMigration interface).
 func (m CleanDotEnv) Migrate() error {
 	if err := m.up(); err != nil {
+		if downErr := m.down(); downErr != nil {
+			return downErr
+		}
 		return err
 	}
-	if err := m.down(); err != nil {
-		return err
-	}
-	return nil
+	return m.Mediator.notifyAboutCompletion()
 }
 
 func (m *CleanDotEnv) up() error {
@@ -81,10 +81,7 @@ func (m *CleanDotEnv) up() error {
 }
 
 func (m *CleanDotEnv) down() error {
-	if err := m.Mediator.notifyAboutCompletion(); err != nil {
-		return err
-	}
-	return nil
+	return restoreTarget(m)
 }
 
 func (m *CleanDotEnv) allowUp() error {
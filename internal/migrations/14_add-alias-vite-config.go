@@ -36,15 +36,15 @@ func (m *AddAliasToViteConfig) MakeMigration(migrationManager *MigrationManager,
 func (m *AddAliasToViteConfig) getServices() *MigrationServices { return m.Services }
 func (m *AddAliasToViteConfig) getData() *MigrationData         { return m.Data }
 
-// Migrate return error if migration execution over up and down methods fails (IMigration interface).
+// Migrate runs up and, when it fails, down to undo the changes made to the target files (IMigration interface).
 func (m AddAliasToViteConfig) Migrate() error {
 	if err := m.up(); err != nil {
+		if downErr := m.down(); downErr != nil {
+			return downErr
+		}
 		return err
 	}
-	if err := m.down(); err != nil {
-		return err
-	}
-	return nil
+	return m.Mediator.notifyAboutCompletion()
 }
 
 func (m *AddAliasToViteConfig) up() error {
@@ -78,10 +78,7 @@ func (m *AddAliasToViteConfig) up() error {
 }
 
 func (m *AddAliasToViteConfig) down() error {
-	if err := m.Mediator.notifyAboutCompletion(); err != nil {
-		return err
-	}
-	return nil
+	return restoreTarget(m)
 }
 
 func (m *AddAliasToViteConfig) allowUp() error {
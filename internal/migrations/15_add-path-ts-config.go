@@ -36,15 +36,15 @@ func (m *AddSveltinPathToTSConfig) MakeMigration(migrationManager *MigrationMana
 func (m *AddSveltinPathToTSConfig) getServices() *MigrationServices { return m.Services }
 func (m *AddSveltinPathToTSConfig) getData() *MigrationData         { return m.Data }
 
-// Migrate return error if migration execution over up and down methods fails (IMigration interface).
+// Migrate runs up and, when it fails, down to undo the changes made to the target files (IMigration interface).
 func (m AddSveltinPathToTSConfig) Migrate() error {
 	if err := m.up(); err != nil {
+		if downErr := m.down(); downErr != nil {
+			return downErr
+		}
 		return err
 	}
-	if err := m.down(); err != nil {
-		return err
-	}
-	return nil
+	return m.Mediator.notifyAboutCompletion()
 }
 
 func (m *AddSveltinPathToTSConfig) up() error {
@@ -78,10 +78,7 @@ func (m *AddSveltinPathToTSConfig) up() error {
 }
 
 func (m *AddSveltinPathToTSConfig) down() error {
-	if err := m.Mediator.notifyAboutCompletion(); err != nil {
-		return err
-	}
-	return nil
+	return restoreTarget(m)
 }
 
 func (m *AddSveltinPathToTSConfig) allowUp() error {
@@ -58,15 +58,15 @@ func (m *UpdatePackageJson) MakeMigration(migrationManager *MigrationManager, se
 func (m *UpdatePackageJson) getServices() *MigrationServices { return m.Services }
 func (m *UpdatePackageJson) getData() *MigrationData         { return m.Data }
 
-// Migrate return error if migration execution over up and down methods fails (IMigration interface).
+// Migrate runs up and, when it fails, down to undo the changes made to the target files (IMigration interface).
 func (m UpdatePackageJson) Migrate() error {
 	if err := m.up(); err != nil {
+		if downErr := m.down(); downErr != nil {
+			return downErr
+		}
 		return err
 	}
-	if err := m.down(); err != nil {
-		return err
-	}
-	return nil
+	return m.Mediator.notifyAboutCompletion()
 }
 
 func (m *UpdatePackageJson) up() error {
@@ -124,10 +124,7 @@ func (m *UpdatePackageJson) up() error {
 }
 
 func (m *UpdatePackageJson) down() error {
-	if err := m.Mediator.notifyAboutCompletion(); err != nil {
-		return err
-	}
-	return nil
+	return restoreTarget(m)
 }
 
 func (m *UpdatePackageJson) allowUp() error {
@@ -38,15 +38,15 @@ func (m *UnhandledMigration) MakeMigration(migrationManager *MigrationManager, s
 func (m *UnhandledMigration) getServices() *MigrationServices { return m.Services }
 func (m *UnhandledMigration) getData() *MigrationData         { return m.Data }
 
-// Migrate return error if migration execution over up and down methods fails (IMigration interface).
+// Migrate runs up and, when it fails, down to undo the changes made to the target files (IMigration interface).
 func (m UnhandledMigration) Migrate() error {
 	if err := m.up(); err != nil {
+		if downErr := m.down(); downErr != nil {
+			return downErr
+		}
 		return err
 	}
-	if err := m.down(); err != nil {
-		return err
-	}
-	return nil
+	return m.Mediator.notifyAboutCompletion()
 }
 
 func (m *UnhandledMigration) up() error {
@@ -155,10 +155,7 @@ func (m *UnhandledMigration) runMigration(content []byte, file string) ([]byte,
 }
 
 func (m *UnhandledMigration) down() error {
-	if err := m.Mediator.notifyAboutCompletion(); err != nil {
-		return err
-	}
-	return nil
+	return restoreTarget(m)
 }
 
 func (m *UnhandledMigration) allowUp() error {
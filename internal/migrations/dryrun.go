@@ -0,0 +1,135 @@
+/**
+ * Copyright © 2021-present Sveltin contributors <github@sveltin.io>
+ *
+ * Use of this source code is governed by Apache 2.0 license
+ * that can be found in the LICENSE file.
+ */
+
+package migrations
+
+import (
+	"bytes"
+	"os"
+	"sort"
+	"strings"
+
+	"github.com/pmezard/go-difflib/difflib"
+	"github.com/spf13/afero"
+)
+
+// DryRunFs is the file system the migrations run against on dry-run. The project files are
+// read from the base file system while the changes are kept in memory, nothing is written to disk.
+type DryRunFs struct {
+	afero.Fs
+	base  afero.Fs
+	layer afero.Fs
+	// touched maps the path of each written or removed file to whether it still exists.
+	touched map[string]bool
+}
+
+// FileChange is a file created, changed or deleted by the migrations.
+// Before is nil for a created file, After is nil for a deleted one.
+type FileChange struct {
+	Path   string
+	Before []byte
+	After  []byte
+}
+
+// NewDryRunFs returns a new DryRunFs struct reading from the base file system.
+func NewDryRunFs(base afero.Fs) *DryRunFs {
+	layer := afero.NewMemMapFs()
+	return &DryRunFs{
+		Fs:      afero.NewCopyOnWriteFs(afero.NewReadOnlyFs(base), layer),
+		base:    base,
+		layer:   layer,
+		touched: map[string]bool{},
+	}
+}
+
+// Create implements afero.Fs interface.
+func (d *DryRunFs) Create(name string) (afero.File, error) {
+	d.touched[name] = true
+	return d.Fs.Create(name)
+}
+
+// OpenFile implements afero.Fs interface.
+func (d *DryRunFs) OpenFile(name string, flag int, perm os.FileMode) (afero.File, error) {
+	if flag&(os.O_WRONLY|os.O_RDWR|os.O_APPEND|os.O_CREATE|os.O_TRUNC) != 0 {
+		d.touched[name] = true
+	}
+	return d.Fs.OpenFile(name, flag, perm)
+}
+
+// Remove records the file as removed. The migrations remove a file before writing its new content.
+func (d *DryRunFs) Remove(name string) error {
+	if exists, _ := afero.Exists(d.layer, name); exists {
+		if err := d.layer.Remove(name); err != nil {
+			return err
+		}
+	} else if exists, _ := afero.Exists(d.base, name); !exists {
+		return &os.PathError{Op: "remove", Path: name, Err: os.ErrNotExist}
+	}
+	d.touched[name] = false
+	return nil
+}
+
+// Changes returns the files whose content differs from the base file system, sorted by path.
+func (d *DryRunFs) Changes() ([]FileChange, error) {
+	changes := []FileChange{}
+	for path, exists := range d.touched {
+		before, err := afero.ReadFile(d.base, path)
+		if err != nil {
+			before = nil
+		}
+		if !exists {
+			if before != nil {
+				changes = append(changes, FileChange{Path: path, Before: before})
+			}
+			continue
+		}
+		after, err := afero.ReadFile(d.layer, path)
+		if err != nil {
+			return nil, err
+		}
+		if before == nil || !bytes.Equal(before, after) {
+			changes = append(changes, FileChange{Path: path, Before: before, After: after})
+		}
+	}
+	sort.Slice(changes, func(i, j int) bool {
+		return changes[i].Path < changes[j].Path
+	})
+	return changes, nil
+}
+
+// UnifiedDiff returns the unified diff between the content before and after the change,
+// name being the file name shown in the diff header.
+func (c FileChange) UnifiedDiff(name string) (string, error) {
+	fromFile, toFile := "a/"+name, "b/"+name
+	if c.Before == nil {
+		fromFile = "/dev/null"
+	}
+	if c.After == nil {
+		toFile = "/dev/null"
+	}
+	return difflib.GetUnifiedDiffString(difflib.UnifiedDiff{
+		A:        splitLines(c.Before),
+		B:        splitLines(c.After),
+		FromFile: fromFile,
+		ToFile:   toFile,
+		Context:  3,
+	})
+}
+
+// splitLines splits the content into lines, each ending with a newline.
+func splitLines(content []byte) []string {
+	if len(content) == 0 {
+		return []string{}
+	}
+	lines := strings.SplitAfter(string(content), "\n")
+	if last := len(lines) - 1; lines[last] == "" {
+		lines = lines[:last]
+	} else {
+		lines[last] += "\n"
+	}
+	return lines
+}
@@ -0,0 +1,64 @@
+package migrations
+
+import (
+	"testing"
+
+	"github.com/matryer/is"
+	"github.com/spf13/afero"
+)
+
+func TestDryRunFs(t *testing.T) {
+	is := is.New(t)
+	base := afero.NewMemMapFs()
+	is.NoErr(afero.WriteFile(base, "/project/svelte.config.js", []byte("a\nb\nc\n"), 0644))
+	is.NoErr(afero.WriteFile(base, "/project/vite.config.ts", []byte("x\n"), 0644))
+	is.NoErr(afero.WriteFile(base, "/project/.env.production", []byte("KEY=1\n"), 0644))
+
+	fs := NewDryRunFs(base)
+	// the migrations remove the target file before writing its new content
+	is.NoErr(fs.Remove("/project/svelte.config.js"))
+	is.NoErr(afero.WriteFile(fs, "/project/svelte.config.js", []byte("a\nB\nc\n"), 0644))
+	is.NoErr(fs.Remove("/project/vite.config.ts"))
+	is.NoErr(afero.WriteFile(fs, "/project/vite.config.ts", []byte("x\n"), 0644))
+	is.NoErr(fs.Remove("/project/.env.production"))
+	is.NoErr(afero.WriteFile(fs, "sveltin.json", []byte("{}\n"), 0644))
+	appendToFile(fs, "/project/svelte.config.js", []string{"d\n"}, nil)
+
+	content, err := afero.ReadFile(fs, "/project/svelte.config.js")
+	is.NoErr(err)
+	is.Equal(string(content), "a\nB\nc\nd\n")
+	// nothing is written to the base file system
+	content, err = afero.ReadFile(base, "/project/svelte.config.js")
+	is.NoErr(err)
+	is.Equal(string(content), "a\nb\nc\n")
+	exists, _ := afero.Exists(base, "sveltin.json")
+	is.True(!exists)
+
+	changes, err := fs.Changes()
+	is.NoErr(err)
+	is.Equal(len(changes), 3) // vite.config.ts is unchanged
+	is.Equal(changes[0].Path, "/project/.env.production")
+	is.Equal(changes[0].After, nil)
+	is.Equal(changes[1].Path, "/project/svelte.config.js")
+	is.Equal(changes[2].Path, "sveltin.json")
+	is.Equal(changes[2].Before, nil)
+
+	diff, err := changes[1].UnifiedDiff("svelte.config.js")
+	is.NoErr(err)
+	is.Equal(diff, `--- a/svelte.config.js
++++ b/svelte.config.js
+@@ -1,3 +1,4 @@
+ a
+-b
++B
+ c
++d
+`)
+	diff, err = changes[0].UnifiedDiff(".env.production")
+	is.NoErr(err)
+	is.Equal(diff, `--- a/.env.production
++++ /dev/null
+@@ -1 +0,0 @@
+-KEY=1
+`)
+}
@@ -55,6 +55,28 @@ var migrationNameMap = map[Migration]string{
 	PackageJSON:              "package-json",
 }
 
+// migrationVersionMap maps each migration to the range of CLI versions it upgrades a project from and to.
+var migrationVersionMap = map[Migration]VersionRange{
+	ProjectSettings:          {To: "0.11.0"},
+	DefaultsConfig:           {To: "0.11.0"},
+	WebSiteTS:                {To: "0.11.0"},
+	MenuTS:                   {To: "0.11.0"},
+	SveltinDTS:               {To: "0.11.0"},
+	ResourceLibs:             {To: "0.11.0"},
+	Layout:                   {To: "0.11.0"},
+	SvelteFiles:              {To: "0.11.0"},
+	PageServerTS:             {To: "0.11.0"},
+	SveltinioComponent:       {To: "0.11.0"},
+	ThemeConfig:              {To: "0.11.0"},
+	ThemeSveltinioComponents: {To: "0.11.0"},
+	MDsveXConfig:             {To: "0.11.0"},
+	SvelteConfig:             {To: "0.11.0"},
+	DotEnv:                   {To: "0.11.0"},
+	ViteConfig:               {To: "0.11.0"},
+	TSConfig:                 {To: "0.11.0"},
+	PackageJSON:              {To: "0.11.0"},
+}
+
 var migrationMap = map[Migration]IMigrationFactory{
 	ProjectSettings:          &AddUpdateProjectSettings{},
 	DefaultsConfig:           &RefactorDefaultsTSTypes{},
@@ -81,6 +103,16 @@ type IMigrationFactory interface {
 	MakeMigration(*MigrationManager, *MigrationServices, *MigrationData) IMigration
 }
 
+// String returns the migration name.
+func (m Migration) String() string {
+	return migrationNameMap[m]
+}
+
+// Since returns the CLI version which introduced the migration.
+func (m Migration) Since() string {
+	return migrationVersionMap[m].To
+}
+
 //=============================================================================
 
 // GetMigrationFactory picks the migration factory depending on the migration id.
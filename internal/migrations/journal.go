@@ -0,0 +1,170 @@
+/**
+ * Copyright © 2021-present Sveltin contributors <github@sveltin.io>
+ *
+ * Use of this source code is governed by Apache 2.0 license
+ * that can be found in the LICENSE file.
+ */
+
+package migrations
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"io"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/spf13/afero"
+)
+
+// Outcomes of a migration recorded in the journal.
+const (
+	OutcomeMigrated  string = "migrated"
+	OutcomeUnchanged string = "unchanged"
+	OutcomeFailed    string = "failed"
+	OutcomeRefused   string = "refused"
+)
+
+// JournalEntry is a migration run on its target, with the checksums of the target before
+// and after it. A checksum is empty when the target does not exist. Version is the sveltin
+// version the migration upgrades the project to, CliVersion the one of the CLI running it.
+type JournalEntry struct {
+	Migration      string    `json:"migration"`
+	Target         string    `json:"target"`
+	Timestamp      time.Time `json:"timestamp"`
+	Version        string    `json:"version"`
+	CliVersion     string    `json:"cliVersion"`
+	BeforeChecksum string    `json:"beforeChecksum"`
+	AfterChecksum  string    `json:"afterChecksum"`
+	Outcome        string    `json:"outcome"`
+	Error          string    `json:"error,omitempty"`
+}
+
+// JournalRun is a run of the migrations, with the snapshot of the files it modified.
+type JournalRun struct {
+	ID                string         `json:"id"`
+	StartedAt         time.Time      `json:"startedAt"`
+	CliVersion        string         `json:"cliVersion"`
+	ProjectCliVersion string         `json:"projectCliVersion"`
+	Snapshot          string         `json:"snapshot"`
+	Files             []SnapshotFile `json:"files"`
+	Migrations        []JournalEntry `json:"migrations"`
+	RolledBackAt      *time.Time     `json:"rolledBackAt,omitempty"`
+}
+
+// Journal is the list of the migrations runs on the project, the oldest first.
+type Journal struct {
+	Runs []*JournalRun `json:"runs"`
+	fs   afero.Fs
+	path string
+}
+
+// LoadJournal reads the journal file, an empty journal when it does not exist yet.
+func LoadJournal(fs afero.Fs, path string) (*Journal, error) {
+	journal := &Journal{Runs: []*JournalRun{}, fs: fs, path: path}
+	content, err := afero.ReadFile(fs, path)
+	if os.IsNotExist(err) {
+		return journal, nil
+	} else if err != nil {
+		return nil, err
+	}
+	if err := json.Unmarshal(content, journal); err != nil {
+		return nil, err
+	}
+	return journal, nil
+}
+
+// Add appends the run to the journal.
+func (j *Journal) Add(run *JournalRun) {
+	j.Runs = append(j.Runs, run)
+}
+
+// LastRun returns the latest run modifying any file and not rolled back yet, nil when none.
+func (j *Journal) LastRun() *JournalRun {
+	for i := len(j.Runs) - 1; i >= 0; i-- {
+		if j.Runs[i].RolledBackAt == nil && len(j.Runs[i].Files) > 0 {
+			return j.Runs[i]
+		}
+	}
+	return nil
+}
+
+// LastApplied returns the latest entry of the migration which migrated its target to the
+// version, any version when empty, skipping the runs rolled back. It is nil when none.
+func (j *Journal) LastApplied(migration, version string) *JournalEntry {
+	for i := len(j.Runs) - 1; i >= 0; i-- {
+		if j.Runs[i].RolledBackAt != nil {
+			continue
+		}
+		entries := j.Runs[i].Migrations
+		for k := len(entries) - 1; k >= 0; k-- {
+			entry := entries[k]
+			if entry.Migration == migration && entry.Outcome == OutcomeMigrated && (version == "" || entry.Version == version) {
+				return &entries[k]
+			}
+		}
+	}
+	return nil
+}
+
+// Save writes the journal file.
+func (j *Journal) Save() error {
+	content, err := json.MarshalIndent(j, "", "  ")
+	if err != nil {
+		return err
+	}
+	if err := j.fs.MkdirAll(filepath.Dir(j.path), os.ModePerm); err != nil {
+		return err
+	}
+	return afero.WriteFile(j.fs, j.path, append(content, '\n'), 0644)
+}
+
+// TargetChecksum returns the SHA-256 checksum of the target file or, for a folder, of the
+// paths and the content of the files within it. It is empty when the target does not exist.
+func TargetChecksum(fs afero.Fs, target string) (string, error) {
+	info, err := fs.Stat(target)
+	if os.IsNotExist(err) {
+		return "", nil
+	} else if err != nil {
+		return "", err
+	}
+	if !info.IsDir() {
+		return fileChecksum(fs, target)
+	}
+
+	hash := sha256.New()
+	err = afero.Walk(fs, target, func(file string, info os.FileInfo, err error) error {
+		if err != nil || !info.Mode().IsRegular() {
+			return err
+		}
+		relPath, err := filepath.Rel(target, file)
+		if err != nil {
+			return err
+		}
+		checksum, err := fileChecksum(fs, file)
+		if err != nil {
+			return err
+		}
+		_, err = io.WriteString(hash, relPath+"\x00"+checksum+"\n")
+		return err
+	})
+	if err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(hash.Sum(nil)), nil
+}
+
+func fileChecksum(fs afero.Fs, path string) (string, error) {
+	file, err := fs.Open(path)
+	if err != nil {
+		return "", err
+	}
+	defer file.Close()
+	hash := sha256.New()
+	if _, err := io.Copy(hash, file); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(hash.Sum(nil)), nil
+}
@@ -39,6 +39,7 @@ type MigrationServices struct {
 	fsManager *fsm.SveltinFSManager
 	pathMaker *pathmaker.SveltinPathMaker
 	logger    *yinlog.Logger
+	snapshot  *Snapshot
 }
 
 // NewMigrationServices creates an instance of MigrationService struct.
@@ -51,6 +52,19 @@ func NewMigrationServices(fs afero.Fs, fsm *fsm.SveltinFSManager, pathmaker *pat
 	}
 }
 
+// SetSnapshot sets the snapshot the migrations restore their target files from when failing.
+func (s *MigrationServices) SetSnapshot(snapshot *Snapshot) {
+	s.snapshot = snapshot
+}
+
+// Checkpoint saves the content of the migration target before running the migration, restored when failing.
+func (s *MigrationServices) Checkpoint(target string) error {
+	if s.snapshot == nil {
+		return nil
+	}
+	return s.snapshot.Checkpoint(target)
+}
+
 // MigrationData is the struct with data used by migrations.
 type MigrationData struct {
 	TargetPath        string
@@ -127,6 +141,16 @@ func overwriteFile(m IMigration, content []byte) error {
 	return nil
 }
 
+// restoreTarget restores the files within the migration target to their content before the
+// migration ran, leaving the changes made by the previous migrations to the same files.
+func restoreTarget(m IMigration) error {
+	snapshot := m.getServices().snapshot
+	if snapshot == nil {
+		return nil
+	}
+	return snapshot.RestoreCheckpoint()
+}
+
 func appendToFile(fs afero.Fs, filename string, contentToAppend []string, logger *yinlog.Logger) {
 	f, err := fs.OpenFile(filename, os.O_WRONLY|os.O_APPEND, 0644)
 	if err != nil {
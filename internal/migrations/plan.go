@@ -0,0 +1,134 @@
+/**
+ * Copyright © 2021-present Sveltin contributors <github@sveltin.io>
+ *
+ * Use of this source code is governed by Apache 2.0 license
+ * that can be found in the LICENSE file.
+ */
+
+package migrations
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// VersionRange is the range of sveltin CLI versions a migration upgrades a project from and to.
+// The migration runs for projects on a version older than To, which must be From or later.
+// An empty From means any older version, including projects without sveltin.json.
+type VersionRange struct {
+	From string
+	To   string
+}
+
+// MigrationStep is a step of a migration plan: the migrations upgrading the project to Version,
+// in execution order.
+type MigrationStep struct {
+	Version    string
+	Migrations []Migration
+}
+
+// NewMigrationPlan returns the steps upgrading a project from its sveltin version (empty when the
+// project has no sveltin.json yet) to the CLI version, ordered by version. A project already on
+// the CLI version gets no steps. The ProjectSettings migration is not part of the plan, it runs
+// before it to create sveltin.json or bump its version.
+func NewMigrationPlan(projectVersion, cliVersion string) ([]MigrationStep, error) {
+	steps := map[string]*MigrationStep{}
+	for id, versionRange := range migrationVersionMap {
+		if id == ProjectSettings {
+			continue
+		}
+		isNewer, err := IsOlderVersion(projectVersion, versionRange.To)
+		if err != nil {
+			return nil, err
+		}
+		isReleased, err := IsOlderVersion(cliVersion, versionRange.To)
+		if err != nil {
+			return nil, err
+		}
+		if !isNewer || isReleased {
+			continue
+		}
+		step, exists := steps[versionRange.To]
+		if !exists {
+			step = &MigrationStep{Version: versionRange.To}
+			steps[versionRange.To] = step
+		}
+		step.Migrations = append(step.Migrations, id)
+	}
+
+	plan := make([]MigrationStep, 0, len(steps))
+	for _, step := range steps {
+		sort.Slice(step.Migrations, func(i, j int) bool {
+			return step.Migrations[i] < step.Migrations[j]
+		})
+		plan = append(plan, *step)
+	}
+	sort.Slice(plan, func(i, j int) bool {
+		isOlder, _ := IsOlderVersion(plan[i].Version, plan[j].Version)
+		return isOlder
+	})
+
+	// each step upgrades the project from the version the previous one upgraded it to.
+	currentVersion := projectVersion
+	for _, step := range plan {
+		for _, id := range step.Migrations {
+			isTooOld, err := IsOlderVersion(currentVersion, migrationVersionMap[id].From)
+			if err != nil {
+				return nil, err
+			}
+			if isTooOld {
+				return nil, fmt.Errorf("the %s migration upgrades projects from sveltin v%s, the project is on v%s", id, migrationVersionMap[id].From, currentVersion)
+			}
+		}
+		currentVersion = step.Version
+	}
+	return plan, nil
+}
+
+//=============================================================================
+
+// IsOlderVersion reports whether the version v1 is older than v2. An empty version is older
+// than any other one.
+func IsOlderVersion(v1, v2 string) (bool, error) {
+	if v1 == "" || v2 == "" {
+		return v1 == "" && v2 != "", nil
+	}
+	n1, err := parseVersion(v1)
+	if err != nil {
+		return false, err
+	}
+	n2, err := parseVersion(v2)
+	if err != nil {
+		return false, err
+	}
+	for i := range n1 {
+		if n1[i] != n2[i] {
+			return n1[i] < n2[i], nil
+		}
+	}
+	return false, nil
+}
+
+// parseVersion returns the major, minor and patch numbers of a version like v0.11.0 or 0.11,
+// pre-release and build suffixes being ignored.
+func parseVersion(version string) ([3]int, error) {
+	numbers := [3]int{}
+	core := strings.TrimPrefix(version, "v")
+	if i := strings.IndexAny(core, "-+"); i >= 0 {
+		core = core[:i]
+	}
+	parts := strings.Split(core, ".")
+	if len(parts) > 3 {
+		return numbers, fmt.Errorf("not a valid version: %s", version)
+	}
+	for i, part := range parts {
+		n, err := strconv.Atoi(part)
+		if err != nil || n < 0 {
+			return numbers, fmt.Errorf("not a valid version: %s", version)
+		}
+		numbers[i] = n
+	}
+	return numbers, nil
+}
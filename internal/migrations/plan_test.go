@@ -0,0 +1,72 @@
+package migrations
+
+import (
+	"testing"
+
+	"github.com/matryer/is"
+)
+
+func TestNewMigrationPlan(t *testing.T) {
+	is := is.New(t)
+
+	isOlder, err := IsOlderVersion("0.10.1", "v0.11.0")
+	is.NoErr(err)
+	is.True(isOlder)
+	isOlder, err = IsOlderVersion("0.11.0-rc.1", "0.11")
+	is.NoErr(err)
+	is.True(!isOlder)
+	isOlder, err = IsOlderVersion("", "0.1.0")
+	is.NoErr(err)
+	is.True(isOlder)
+	_, err = IsOlderVersion("latest", "0.11.0")
+	is.True(err != nil)
+
+	plan, err := NewMigrationPlan("0.10.1", "0.11.0")
+	is.NoErr(err)
+	is.Equal(len(plan), 1)
+	is.Equal(plan[0].Version, "0.11.0")
+	is.Equal(len(plan[0].Migrations), len(migrationVersionMap)-1) // but ProjectSettings
+	is.Equal(plan[0].Migrations[0], DefaultsConfig)
+
+	// projects without sveltin.json are on the oldest version
+	plan, err = NewMigrationPlan("", "0.11.0")
+	is.NoErr(err)
+	is.Equal(len(plan), 1)
+
+	// already-current projects run nothing
+	plan, err = NewMigrationPlan("0.11.0", "0.11.0")
+	is.NoErr(err)
+	is.Equal(len(plan), 0)
+}
+
+func TestNewMigrationPlanSteps(t *testing.T) {
+	is := is.New(t)
+	registry := migrationVersionMap
+	t.Cleanup(func() { migrationVersionMap = registry })
+	migrationVersionMap = map[Migration]VersionRange{
+		ProjectSettings: {To: "0.11.0"},
+		SvelteConfig:    {To: "0.11.0"},
+		ViteConfig:      {From: "0.11.0", To: "0.13.0"},
+		DefaultsConfig:  {From: "0.11.0", To: "0.12.0"},
+		TSConfig:        {From: "0.12.0", To: "0.13.0"},
+	}
+
+	// projects several versions behind migrate step by step
+	plan, err := NewMigrationPlan("0.10.0", "0.13.0")
+	is.NoErr(err)
+	is.Equal(plan, []MigrationStep{
+		{Version: "0.11.0", Migrations: []Migration{SvelteConfig}},
+		{Version: "0.12.0", Migrations: []Migration{DefaultsConfig}},
+		{Version: "0.13.0", Migrations: []Migration{ViteConfig, TSConfig}},
+	})
+
+	// the steps newer than the CLI are not planned
+	plan, err = NewMigrationPlan("0.11.0", "0.12.0")
+	is.NoErr(err)
+	is.Equal(plan, []MigrationStep{{Version: "0.12.0", Migrations: []Migration{DefaultsConfig}}})
+
+	// a migration cannot upgrade a project older than its range
+	migrationVersionMap[MenuTS] = VersionRange{From: "0.12.0", To: "0.12.0"}
+	_, err = NewMigrationPlan("0.10.0", "0.13.0")
+	is.True(err != nil)
+}
@@ -0,0 +1,273 @@
+/**
+ * Copyright © 2021-present Sveltin contributors <github@sveltin.io>
+ *
+ * Use of this source code is governed by Apache 2.0 license
+ * that can be found in the LICENSE file.
+ */
+
+package migrations
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/spf13/afero"
+)
+
+// SnapshotFile is a file saved in a snapshot, its path relative to the project root.
+// Existed is false for a file created by the migrations, removed when restoring.
+type SnapshotFile struct {
+	Path    string `json:"path"`
+	Existed bool   `json:"existed"`
+}
+
+// Snapshot is the copy of the migration target files taken before running the migrations,
+// saved within its own folder with the same layout as the project.
+type Snapshot struct {
+	fs     afero.Fs
+	root   string
+	folder string
+	// files maps the path of each target file to whether it existed.
+	files map[string]bool
+	dirs  []string
+	// checkpoint is the target of the running migration with the content of its files.
+	checkpoint *checkpoint
+}
+
+// checkpoint is the content of the files within a migration target before the migration
+// runs, so that a failing migration undoes its own changes only.
+type checkpoint struct {
+	target string
+	files  map[string][]byte
+}
+
+// NewSnapshot returns a new Snapshot struct saving the files within the root folder to the snapshot folder.
+func NewSnapshot(fs afero.Fs, root, folder string) *Snapshot {
+	return &Snapshot{
+		fs:     fs,
+		root:   root,
+		folder: folder,
+		files:  map[string]bool{},
+	}
+}
+
+// Add saves the target file, or the files within the target folder, to the snapshot.
+func (s *Snapshot) Add(target string) error {
+	relPath, err := filepath.Rel(s.root, target)
+	if err != nil {
+		return err
+	}
+	if isDir, _ := afero.IsDir(s.fs, target); isDir {
+		// several migrations target the same folder, e.g. src/routes
+		for _, dir := range s.dirs {
+			if dir == relPath {
+				return nil
+			}
+		}
+		s.dirs = append(s.dirs, relPath)
+		return afero.Walk(s.fs, target, func(file string, info os.FileInfo, err error) error {
+			if err != nil || !info.Mode().IsRegular() {
+				return err
+			}
+			return s.Add(file)
+		})
+	}
+	if _, exists := s.files[relPath]; exists {
+		return nil
+	}
+
+	content, err := afero.ReadFile(s.fs, target)
+	if os.IsNotExist(err) {
+		s.files[relPath] = false
+		return nil
+	} else if err != nil {
+		return err
+	}
+	if err := s.fs.MkdirAll(filepath.Dir(filepath.Join(s.folder, relPath)), os.ModePerm); err != nil {
+		return err
+	}
+	s.files[relPath] = true
+	return afero.WriteFile(s.fs, filepath.Join(s.folder, relPath), content, 0644)
+}
+
+// Modified returns the files changed, created or deleted since they were saved, sorted by path.
+func (s *Snapshot) Modified() ([]SnapshotFile, error) {
+	modified := []SnapshotFile{}
+	for relPath, existed := range s.files {
+		current, err := afero.ReadFile(s.fs, filepath.Join(s.root, relPath))
+		if os.IsNotExist(err) {
+			if existed {
+				modified = append(modified, SnapshotFile{Path: relPath, Existed: true})
+			}
+			continue
+		} else if err != nil {
+			return nil, err
+		}
+		if !existed {
+			modified = append(modified, SnapshotFile{Path: relPath})
+			continue
+		}
+		saved, err := afero.ReadFile(s.fs, filepath.Join(s.folder, relPath))
+		if err != nil {
+			return nil, err
+		}
+		if !bytes.Equal(saved, current) {
+			modified = append(modified, SnapshotFile{Path: relPath, Existed: true})
+		}
+	}
+
+	// files created within the target folders, once when the folders are nested
+	created := map[string]bool{}
+	for _, dir := range s.dirs {
+		err := afero.Walk(s.fs, filepath.Join(s.root, dir), func(file string, info os.FileInfo, err error) error {
+			if err != nil || !info.Mode().IsRegular() {
+				return err
+			}
+			relPath, err := filepath.Rel(s.root, file)
+			if err != nil {
+				return err
+			}
+			if _, saved := s.files[relPath]; !saved && !created[relPath] {
+				created[relPath] = true
+				modified = append(modified, SnapshotFile{Path: relPath})
+			}
+			return nil
+		})
+		if err != nil && !os.IsNotExist(err) {
+			return nil, err
+		}
+	}
+
+	sort.Slice(modified, func(i, j int) bool {
+		return modified[i].Path < modified[j].Path
+	})
+	return modified, nil
+}
+
+// Commit returns the modified files and removes from the snapshot the copies of the other ones.
+func (s *Snapshot) Commit() ([]SnapshotFile, error) {
+	modified, err := s.Modified()
+	if err != nil {
+		return nil, err
+	}
+	isModified := map[string]bool{}
+	for _, file := range modified {
+		isModified[file.Path] = true
+	}
+	for relPath, existed := range s.files {
+		if existed && !isModified[relPath] {
+			if err := s.fs.Remove(filepath.Join(s.folder, relPath)); err != nil {
+				return nil, err
+			}
+		}
+	}
+	return modified, nil
+}
+
+// Restore restores the files to their saved content, removing the ones created by the migrations.
+func (s *Snapshot) Restore(files []SnapshotFile) error {
+	for _, file := range files {
+		target := filepath.Join(s.root, file.Path)
+		if !file.Existed {
+			if err := s.fs.Remove(target); err != nil && !os.IsNotExist(err) {
+				return err
+			}
+			continue
+		}
+		content, err := afero.ReadFile(s.fs, filepath.Join(s.folder, file.Path))
+		if err != nil {
+			return err
+		}
+		if err := s.fs.MkdirAll(filepath.Dir(target), os.ModePerm); err != nil {
+			return err
+		}
+		if err := afero.WriteFile(s.fs, target, content, 0644); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// RestoreWithin restores the modified files within the target file or folder.
+func (s *Snapshot) RestoreWithin(target string) error {
+	relPath, err := filepath.Rel(s.root, target)
+	if err != nil {
+		return err
+	}
+	modified, err := s.Modified()
+	if err != nil {
+		return err
+	}
+	within := []SnapshotFile{}
+	for _, file := range modified {
+		if file.Path == relPath || strings.HasPrefix(file.Path, relPath+string(filepath.Separator)) {
+			within = append(within, file)
+		}
+	}
+	return s.Restore(within)
+}
+
+// Checkpoint saves the content of the files within the target file or folder, the state the
+// migration running on it restores when failing.
+func (s *Snapshot) Checkpoint(target string) error {
+	s.checkpoint = &checkpoint{target: target, files: map[string][]byte{}}
+	if isDir, _ := afero.IsDir(s.fs, target); isDir {
+		return afero.Walk(s.fs, target, func(file string, info os.FileInfo, err error) error {
+			if err != nil || !info.Mode().IsRegular() {
+				return err
+			}
+			content, err := afero.ReadFile(s.fs, file)
+			if err != nil {
+				return err
+			}
+			s.checkpoint.files[file] = content
+			return nil
+		})
+	}
+	content, err := afero.ReadFile(s.fs, target)
+	if err != nil && !os.IsNotExist(err) {
+		return err
+	}
+	s.checkpoint.files[target] = content
+	return nil
+}
+
+// RestoreCheckpoint restores the files within the checkpoint target to their content when
+// it was taken, removing the ones created since then.
+func (s *Snapshot) RestoreCheckpoint() error {
+	if s.checkpoint == nil {
+		return nil
+	}
+	if isDir, _ := afero.IsDir(s.fs, s.checkpoint.target); isDir {
+		err := afero.Walk(s.fs, s.checkpoint.target, func(file string, info os.FileInfo, err error) error {
+			if err != nil || !info.Mode().IsRegular() {
+				return err
+			}
+			if _, saved := s.checkpoint.files[file]; !saved {
+				return s.fs.Remove(file)
+			}
+			return nil
+		})
+		if err != nil {
+			return err
+		}
+	}
+	for file, content := range s.checkpoint.files {
+		if content == nil {
+			if err := s.fs.Remove(file); err != nil && !os.IsNotExist(err) {
+				return err
+			}
+			continue
+		}
+		if err := s.fs.MkdirAll(filepath.Dir(file), os.ModePerm); err != nil {
+			return err
+		}
+		if err := afero.WriteFile(s.fs, file, content, 0644); err != nil {
+			return err
+		}
+	}
+	return nil
+}
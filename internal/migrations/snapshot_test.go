@@ -0,0 +1,142 @@
+package migrations
+
+import (
+	"testing"
+
+	"github.com/matryer/is"
+	"github.com/spf13/afero"
+)
+
+func TestSnapshot(t *testing.T) {
+	is := is.New(t)
+	fs := afero.NewMemMapFs()
+	is.NoErr(afero.WriteFile(fs, "/project/svelte.config.js", []byte("old"), 0644))
+	is.NoErr(afero.WriteFile(fs, "/project/vite.config.ts", []byte("same"), 0644))
+	is.NoErr(afero.WriteFile(fs, "/project/src/routes/+page.svelte", []byte("page"), 0644))
+
+	snapshot := NewSnapshot(fs, "/project", "/project/.sveltin/snapshots/1")
+	is.NoErr(snapshot.Add("/project/svelte.config.js"))
+	is.NoErr(snapshot.Add("/project/vite.config.ts"))
+	is.NoErr(snapshot.Add("/project/sveltin.json"))
+	is.NoErr(snapshot.Add("/project/src/routes"))
+
+	is.NoErr(afero.WriteFile(fs, "/project/svelte.config.js", []byte("new"), 0644))
+	is.NoErr(afero.WriteFile(fs, "/project/sveltin.json", []byte("{}"), 0644))
+	is.NoErr(afero.WriteFile(fs, "/project/src/routes/+page.ts", []byte("load"), 0644))
+	is.NoErr(fs.Remove("/project/src/routes/+page.svelte"))
+
+	modified, err := snapshot.Modified()
+	is.NoErr(err)
+	is.Equal(modified, []SnapshotFile{
+		{Path: "src/routes/+page.svelte", Existed: true},
+		{Path: "src/routes/+page.ts"},
+		{Path: "svelte.config.js", Existed: true},
+		{Path: "sveltin.json"},
+	})
+
+	// aborting a run restores the files within the target only
+	is.NoErr(snapshot.RestoreWithin("/project/src/routes"))
+	content, err := afero.ReadFile(fs, "/project/src/routes/+page.svelte")
+	is.NoErr(err)
+	is.Equal(string(content), "page")
+	exists, _ := afero.Exists(fs, "/project/src/routes/+page.ts")
+	is.True(!exists)
+
+	// the copies of the unmodified files are removed
+	modified, err = snapshot.Commit()
+	is.NoErr(err)
+	is.Equal(len(modified), 2)
+	exists, _ = afero.Exists(fs, "/project/.sveltin/snapshots/1/vite.config.ts")
+	is.True(!exists)
+
+	is.NoErr(NewSnapshot(fs, "/project", "/project/.sveltin/snapshots/1").Restore(modified))
+	content, err = afero.ReadFile(fs, "/project/svelte.config.js")
+	is.NoErr(err)
+	is.Equal(string(content), "old")
+	exists, _ = afero.Exists(fs, "/project/sveltin.json")
+	is.True(!exists)
+}
+
+func TestSnapshotSameFolder(t *testing.T) {
+	is := is.New(t)
+	fs := afero.NewMemMapFs()
+	is.NoErr(afero.WriteFile(fs, "/project/src/routes/+page.svelte", []byte("page"), 0644))
+
+	// the svelte files, page server and components migrations all target src/routes
+	snapshot := NewSnapshot(fs, "/project", "/project/.sveltin/snapshots/1")
+	is.NoErr(snapshot.Add("/project/src/routes"))
+	is.NoErr(snapshot.Add("/project/src/routes"))
+	is.NoErr(snapshot.Add("/project/src"))
+	is.Equal(snapshot.dirs, []string{"src/routes", "src"})
+
+	is.NoErr(afero.WriteFile(fs, "/project/src/routes/+page.ts", []byte("load"), 0644))
+	modified, err := snapshot.Commit()
+	is.NoErr(err)
+	is.Equal(modified, []SnapshotFile{{Path: "src/routes/+page.ts"}})
+}
+
+func TestSnapshotCheckpoint(t *testing.T) {
+	is := is.New(t)
+	fs := afero.NewMemMapFs()
+	is.NoErr(afero.WriteFile(fs, "/project/src/routes/+layout.ts", []byte("layout"), 0644))
+	is.NoErr(afero.WriteFile(fs, "/project/src/routes/+page.svelte", []byte("page"), 0644))
+
+	snapshot := NewSnapshot(fs, "/project", "/project/.sveltin/snapshots/1")
+	is.NoErr(snapshot.Add("/project/src/routes/+layout.ts"))
+	is.NoErr(snapshot.Add("/project/src/routes"))
+	// a previous migration changed a file within the folder
+	is.NoErr(afero.WriteFile(fs, "/project/src/routes/+layout.ts", []byte("migrated"), 0644))
+
+	is.NoErr(snapshot.Checkpoint("/project/src/routes"))
+	is.NoErr(afero.WriteFile(fs, "/project/src/routes/+page.svelte", []byte("half migrated"), 0644))
+	is.NoErr(afero.WriteFile(fs, "/project/src/routes/+page.ts", []byte("load"), 0644))
+	is.NoErr(snapshot.RestoreCheckpoint())
+
+	content, err := afero.ReadFile(fs, "/project/src/routes/+layout.ts")
+	is.NoErr(err)
+	is.Equal(string(content), "migrated")
+	content, err = afero.ReadFile(fs, "/project/src/routes/+page.svelte")
+	is.NoErr(err)
+	is.Equal(string(content), "page")
+	exists, _ := afero.Exists(fs, "/project/src/routes/+page.ts")
+	is.True(!exists)
+}
+
+func TestJournal(t *testing.T) {
+	is := is.New(t)
+	fs := afero.NewMemMapFs()
+	journal, err := LoadJournal(fs, ".sveltin/migrations.json")
+	is.NoErr(err)
+	is.Equal(journal.LastRun(), nil)
+
+	journal.Add(&JournalRun{ID: "1", Files: []SnapshotFile{{Path: "svelte.config.js", Existed: true}}})
+	journal.Add(&JournalRun{ID: "2", Files: []SnapshotFile{}})
+	is.NoErr(journal.Save())
+
+	loaded, err := LoadJournal(fs, ".sveltin/migrations.json")
+	is.NoErr(err)
+	is.Equal(len(loaded.Runs), 2)
+	// a run not modifying any file has nothing to roll back
+	is.Equal(loaded.LastRun().ID, "1")
+}
+
+func TestTargetChecksum(t *testing.T) {
+	is := is.New(t)
+	fs := afero.NewMemMapFs()
+	is.NoErr(afero.WriteFile(fs, "/project/src/routes/+page.svelte", []byte("page"), 0644))
+
+	checksum, err := TargetChecksum(fs, "/project/src/routes/+page.svelte")
+	is.NoErr(err)
+	is.Equal(checksum, "3660315a9af3df255d8f19ab077e4797822b41488a0e2a04bc6af71213c23274")
+
+	before, err := TargetChecksum(fs, "/project/src/routes")
+	is.NoErr(err)
+	is.NoErr(afero.WriteFile(fs, "/project/src/routes/+page.ts", []byte("load"), 0644))
+	after, err := TargetChecksum(fs, "/project/src/routes")
+	is.NoErr(err)
+	is.True(before != after)
+
+	checksum, err = TargetChecksum(fs, "/project/missing.js")
+	is.NoErr(err)
+	is.Equal(checksum, "")
+}
@@ -0,0 +1,74 @@
+/**
+ * Copyright © 2021-present Sveltin contributors <github@sveltin.io>
+ *
+ * Use of this source code is governed by Apache 2.0 license
+ * that can be found in the LICENSE file.
+ */
+
+package migrations
+
+import (
+	"io"
+	"time"
+
+	"github.com/spf13/afero"
+	"github.com/sveltinio/yinlog"
+)
+
+// States of a migration for a project.
+const (
+	StatePending       string = "pending"
+	StateApplied       string = "applied"
+	StateNotApplicable string = "not-applicable"
+)
+
+// MigrationStatus is the state of a migration for a project. AppliedAt is the time the
+// migration last migrated its target according to the journal, if any.
+type MigrationStatus struct {
+	Name      string     `json:"name"`
+	Target    string     `json:"target"`
+	State     string     `json:"state"`
+	Since     string     `json:"since"`
+	AppliedAt *time.Time `json:"appliedAt,omitempty"`
+}
+
+// StatusReport is the state of all the migrations for a project.
+type StatusReport struct {
+	CliVersion        string            `json:"cliVersion"`
+	ProjectCliVersion string            `json:"projectCliVersion"`
+	Migrations        []MigrationStatus `json:"migrations"`
+}
+
+// CheckMigration returns the state of the migration: pending when its checks (e.g. mustMigrate
+// and patternsMatched) match the target and running it would change any file, not-applicable
+// when the target does not exist, applied otherwise. The migration runs against a DryRunFs
+// with its log discarded, nothing is written.
+func CheckMigration(id Migration, services *MigrationServices, data *MigrationData) (string, error) {
+	migrationFactory, err := GetMigrationFactory(id)
+	if err != nil {
+		return "", err
+	}
+
+	quietLogger := yinlog.New()
+	quietLogger.SetPrinter(&yinlog.TextPrinter{Writer: io.Discard, Options: &yinlog.PrinterOptions{}})
+	dryRunFs := NewDryRunFs(services.fs)
+	dryRunServices := NewMigrationServices(dryRunFs, services.fsManager, services.pathMaker, quietLogger)
+
+	exists, _ := afero.Exists(services.fs, data.TargetPath)
+	migrationErr := migrationFactory.MakeMigration(NewMigrationManager(), dryRunServices, data).Migrate()
+	changes, err := dryRunFs.Changes()
+	if err != nil {
+		return "", err
+	}
+
+	switch {
+	case len(changes) > 0:
+		return StatePending, nil
+	case !exists:
+		return StateNotApplicable, nil
+	case migrationErr != nil:
+		return "", migrationErr
+	default:
+		return StateApplied, nil
+	}
+}
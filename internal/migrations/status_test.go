@@ -0,0 +1,35 @@
+package migrations
+
+import (
+	"testing"
+
+	"github.com/matryer/is"
+	"github.com/spf13/afero"
+	"github.com/sveltinio/yinlog"
+)
+
+func TestCheckMigration(t *testing.T) {
+	is := is.New(t)
+	fs := afero.NewMemMapFs()
+	svelteConfig := "const config = {\n\tkit: {\n\t\ttrailingSlash: 'always'\n\t}\n};\n"
+	is.NoErr(afero.WriteFile(fs, "/project/svelte.config.js", []byte(svelteConfig), 0644))
+	is.NoErr(afero.WriteFile(fs, "/other/svelte.config.js", []byte("const config = {};\n"), 0644))
+	services := NewMigrationServices(fs, nil, nil, yinlog.New())
+
+	state, err := CheckMigration(SvelteConfig, services, &MigrationData{TargetPath: "/project/svelte.config.js"})
+	is.NoErr(err)
+	is.Equal(state, StatePending)
+	// nothing is written
+	content, err := afero.ReadFile(fs, "/project/svelte.config.js")
+	is.NoErr(err)
+	is.Equal(string(content), svelteConfig)
+
+	state, err = CheckMigration(SvelteConfig, services, &MigrationData{TargetPath: "/other/svelte.config.js"})
+	is.NoErr(err)
+	is.Equal(state, StateApplied)
+
+	state, err = CheckMigration(SvelteConfig, services, &MigrationData{TargetPath: "/missing/svelte.config.js"})
+	is.NoErr(err)
+	is.Equal(state, StateNotApplicable)
+	is.Equal(SvelteConfig.Since(), "0.11.0")
+}
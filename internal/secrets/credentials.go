@@ -0,0 +1,139 @@
+/**
+ * Copyright © 2021-present Sveltin contributors <github@sveltin.io>
+ *
+ * Use of this source code is governed by Apache 2.0 license
+ * that can be found in the LICENSE file.
+ */
+
+package secrets
+
+import (
+	"github.com/spf13/afero"
+)
+
+// Credentials is the user and password pair used to log in the remote server.
+type Credentials struct {
+	User     string
+	Password string
+}
+
+// Source is a place the credentials for a host can be looked up in.
+type Source interface {
+	// Name describes the source, e.g. in the log messages.
+	Name() string
+	// Lookup returns the credentials for the host and user (if not empty), nil when not found.
+	Lookup(host, user string) (*Credentials, error)
+}
+
+// Resolve looks up the credentials in the sources, in order, and returns the first ones
+// with a password together with the name of the source. The user is the given one when
+// the source does not set it. It returns nil when no source has the credentials.
+func Resolve(sources []Source, host, user string) (*Credentials, string, error) {
+	for _, source := range sources {
+		credentials, err := source.Lookup(host, user)
+		if err != nil {
+			return nil, source.Name(), err
+		}
+		if credentials == nil || credentials.Password == "" {
+			continue
+		}
+		if credentials.User == "" {
+			credentials.User = user
+		}
+		return credentials, source.Name(), nil
+	}
+	return nil, "", nil
+}
+
+//=============================================================================
+
+// EnvSource reads the credentials from the process environment.
+type EnvSource struct {
+	UserKey     string
+	PasswordKey string
+	Getenv      func(string) string
+}
+
+// Name implements the Source interface.
+func (s *EnvSource) Name() string {
+	return "the process environment"
+}
+
+// Lookup implements the Source interface.
+func (s *EnvSource) Lookup(host, user string) (*Credentials, error) {
+	password := s.Getenv(s.PasswordKey)
+	if password == "" {
+		return nil, nil
+	}
+	return &Credentials{User: s.Getenv(s.UserKey), Password: password}, nil
+}
+
+// NetrcSource reads the credentials from the netrc file.
+type NetrcSource struct {
+	Fs   afero.Fs
+	Path string
+}
+
+// Name implements the Source interface.
+func (s *NetrcSource) Name() string {
+	return s.Path
+}
+
+// Lookup implements the Source interface.
+func (s *NetrcSource) Lookup(host, user string) (*Credentials, error) {
+	entries, err := readNetrc(s.Fs, s.Path)
+	if err != nil {
+		return nil, err
+	}
+	entry := FindNetrcEntry(entries, host, user)
+	if entry == nil {
+		return nil, nil
+	}
+	return &Credentials{User: entry.Login, Password: entry.Password}, nil
+}
+
+// StoreSource reads the credentials from the secrets store of the environment.
+// Open is called only when the lookup reaches this source, so that the passphrase
+// is asked for only when needed. It returns a nil Store when there is no secrets file.
+type StoreSource struct {
+	Environment string
+	UserKey     string
+	PasswordKey string
+	Open        func() (*Store, error)
+}
+
+// Name implements the Source interface.
+func (s *StoreSource) Name() string {
+	return "the secrets store"
+}
+
+// Lookup implements the Source interface.
+func (s *StoreSource) Lookup(host, user string) (*Credentials, error) {
+	store, err := s.Open()
+	if err != nil || store == nil {
+		return nil, err
+	}
+	password, exists := store.Get(s.Environment, s.PasswordKey)
+	if !exists {
+		return nil, nil
+	}
+	storedUser, _ := store.Get(s.Environment, s.UserKey)
+	return &Credentials{User: storedUser, Password: password}, nil
+}
+
+// StaticSource returns fixed credentials, e.g. the ones from the env file.
+type StaticSource struct {
+	Description string
+	Credentials Credentials
+}
+
+// Name implements the Source interface.
+func (s *StaticSource) Name() string {
+	return s.Description
+}
+
+// Lookup implements the Source interface.
+func (s *StaticSource) Lookup(host, user string) (*Credentials, error) {
+	credentials := s.Credentials
+	return &credentials, nil
+}
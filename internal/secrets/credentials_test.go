@@ -0,0 +1,89 @@
+package secrets
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/matryer/is"
+	"github.com/spf13/afero"
+)
+
+const netrcContent = `# deploy credentials
+machine ftp.example.com
+  login deployer
+  password from-netrc
+
+machine ftp.example.com login other password other#pwd # staging account
+macdef init
+  cd /www
+  ls
+
+default login anonymous password guest@example.com
+`
+
+func TestParseNetrc(t *testing.T) {
+	is := is.New(t)
+	entries := ParseNetrc([]byte(netrcContent))
+	is.Equal(entries, []NetrcEntry{
+		{Machine: "ftp.example.com", Login: "deployer", Password: "from-netrc"},
+		{Machine: "ftp.example.com", Login: "other", Password: "other#pwd"},
+		{Login: "anonymous", Password: "guest@example.com"},
+	})
+
+	is.Equal(FindNetrcEntry(entries, "ftp.example.com", "").Password, "from-netrc")
+	is.Equal(FindNetrcEntry(entries, "ftp.example.com", "other").Password, "other#pwd")
+	is.Equal(FindNetrcEntry(entries, "sftp.example.com", "").Login, "anonymous")
+	is.True(FindNetrcEntry(entries[:2], "sftp.example.com", "") == nil)
+}
+
+func TestResolve(t *testing.T) {
+	is := is.New(t)
+	fs := afero.NewMemMapFs()
+	is.NoErr(afero.WriteFile(fs, "/home/me/.netrc", []byte(netrcContent), 0600))
+
+	processEnv := map[string]string{}
+	storeOpened := false
+	store := NewStore()
+	store.Set("staging", "FTP_PASSWORD", "from-store")
+	sources := []Source{
+		&EnvSource{UserKey: "FTP_USER", PasswordKey: "FTP_PASSWORD", Getenv: func(key string) string { return processEnv[key] }},
+		&NetrcSource{Fs: fs, Path: "/home/me/.netrc"},
+		&StoreSource{Environment: "staging", UserKey: "FTP_USER", PasswordKey: "FTP_PASSWORD", Open: func() (*Store, error) {
+			storeOpened = true
+			return store, nil
+		}},
+		&StaticSource{Description: ".env.staging", Credentials: Credentials{User: "envfile", Password: "from-env-file"}},
+	}
+
+	// the process environment comes first
+	processEnv["FTP_PASSWORD"] = "from-process"
+	credentials, source, err := Resolve(sources, "ftp.example.com", "deployer")
+	is.NoErr(err)
+	is.Equal(credentials, &Credentials{User: "deployer", Password: "from-process"})
+	is.Equal(source, "the process environment")
+
+	delete(processEnv, "FTP_PASSWORD")
+	credentials, source, err = Resolve(sources, "ftp.example.com", "other")
+	is.NoErr(err)
+	is.Equal(credentials.Password, "other#pwd")
+	is.Equal(source, "/home/me/.netrc")
+	// the secrets store is unlocked only when needed
+	is.True(!storeOpened)
+
+	sources[1] = &NetrcSource{Fs: fs, Path: "/home/me/missing"}
+	credentials, source, err = Resolve(sources, "ftp.example.com", "deployer")
+	is.NoErr(err)
+	is.Equal(credentials, &Credentials{User: "deployer", Password: "from-store"})
+	is.Equal(source, "the secrets store")
+	is.True(storeOpened)
+
+	credentials, _, err = Resolve(sources[:2], "ftp.example.com", "deployer")
+	is.NoErr(err)
+	is.True(credentials == nil)
+
+	sources[2] = &StoreSource{Environment: "staging", PasswordKey: "FTP_PASSWORD", Open: func() (*Store, error) {
+		return nil, ErrWrongPassphrase
+	}}
+	_, _, err = Resolve(sources, "ftp.example.com", "deployer")
+	is.True(errors.Is(err, ErrWrongPassphrase))
+}
@@ -0,0 +1,133 @@
+/**
+ * Copyright © 2021-present Sveltin contributors <github@sveltin.io>
+ *
+ * Use of this source code is governed by Apache 2.0 license
+ * that can be found in the LICENSE file.
+ */
+
+package secrets
+
+import (
+	"bufio"
+	"bytes"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+
+	"github.com/spf13/afero"
+)
+
+// NetrcEntry is a machine (or default) entry from the netrc file.
+type NetrcEntry struct {
+	Machine  string
+	Login    string
+	Password string
+}
+
+// ParseNetrc parses the content of a netrc file. The default entry has an empty Machine.
+// Macro definitions (macdef) and the comments, from a token starting with '#' to the end of
+// the line, are skipped.
+func ParseNetrc(content []byte) []NetrcEntry {
+	entries := []NetrcEntry{}
+	var current *NetrcEntry
+
+	scanner := bufio.NewScanner(bytes.NewReader(content))
+	inMacro := false
+	for scanner.Scan() {
+		line := scanner.Text()
+		if inMacro {
+			// a macro definition ends with an empty line
+			inMacro = strings.TrimSpace(line) != ""
+			continue
+		}
+
+		fields := strings.Fields(line)
+		for i := 0; i < len(fields); i++ {
+			// a comment starts a token, a '#' within a value (e.g. a password) is kept
+			if strings.HasPrefix(fields[i], "#") {
+				break
+			}
+			next := func() string {
+				if i+1 < len(fields) {
+					i++
+					return fields[i]
+				}
+				return ""
+			}
+			switch fields[i] {
+			case "machine":
+				entries = append(entries, NetrcEntry{Machine: next()})
+				current = &entries[len(entries)-1]
+			case "default":
+				entries = append(entries, NetrcEntry{})
+				current = &entries[len(entries)-1]
+			case "login":
+				if value := next(); current != nil {
+					current.Login = value
+				}
+			case "password":
+				if value := next(); current != nil {
+					current.Password = value
+				}
+			case "account":
+				next()
+			case "macdef":
+				next()
+				inMacro = true
+				i = len(fields)
+			}
+		}
+	}
+	return entries
+}
+
+// FindNetrcEntry returns the entry for the host and, when not empty, the login.
+// The default entry is used when no machine entry matches.
+func FindNetrcEntry(entries []NetrcEntry, host, login string) *NetrcEntry {
+	var fallback *NetrcEntry
+	for i := range entries {
+		entry := &entries[i]
+		if login != "" && entry.Login != "" && entry.Login != login {
+			continue
+		}
+		if entry.Machine == host {
+			return entry
+		}
+		if entry.Machine == "" && fallback == nil {
+			fallback = entry
+		}
+	}
+	return fallback
+}
+
+// DefaultNetrcPath returns the path set by the NETRC environment variable or
+// the .netrc file (_netrc on Windows) in the user home folder.
+func DefaultNetrcPath() string {
+	if path := os.Getenv("NETRC"); path != "" {
+		return path
+	}
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return ""
+	}
+	if runtime.GOOS == "windows" {
+		return filepath.Join(home, "_netrc")
+	}
+	return filepath.Join(home, ".netrc")
+}
+
+// readNetrc reads and parses the netrc file. A missing file has no entries.
+func readNetrc(fs afero.Fs, path string) ([]NetrcEntry, error) {
+	if path == "" {
+		return nil, nil
+	}
+	content, err := afero.ReadFile(fs, path)
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return ParseNetrc(content), nil
+}
@@ -0,0 +1,163 @@
+/**
+ * Copyright © 2021-present Sveltin contributors <github@sveltin.io>
+ *
+ * Use of this source code is governed by Apache 2.0 license
+ * that can be found in the LICENSE file.
+ */
+
+// Package secrets handles the credentials used to connect to the remote servers:
+// the encrypted local secrets store, the netrc file and the lookup order between them.
+package secrets
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"encoding/json"
+	"errors"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+
+	"github.com/spf13/afero"
+	"golang.org/x/crypto/scrypt"
+)
+
+// PassphraseEnv is the environment variable the secrets store passphrase is read from, if set.
+const PassphraseEnv string = "SVELTIN_SECRETS_PASSPHRASE"
+
+// ErrWrongPassphrase is returned when the secrets store cannot be decrypted with the passphrase.
+var ErrWrongPassphrase = errors.New("wrong passphrase or corrupted secrets file")
+
+const storeVersion = 1
+
+// scrypt parameters, as recommended for interactive logins.
+const (
+	scryptN      = 32768
+	scryptR      = 8
+	scryptP      = 1
+	scryptKeyLen = 32
+	saltLen      = 16
+)
+
+// storeFile is the on-disk representation of the secrets store.
+type storeFile struct {
+	Version int    `json:"version"`
+	Salt    []byte `json:"salt"`
+	Nonce   []byte `json:"nonce"`
+	Data    []byte `json:"data"`
+}
+
+// Store is the local secrets store, mapping each environment to its secrets.
+// On disk, the content is encrypted with AES-256-GCM using a key derived from
+// the passphrase with scrypt.
+type Store struct {
+	secrets map[string]map[string]string
+}
+
+// NewStore returns a new empty Store.
+func NewStore() *Store {
+	return &Store{secrets: map[string]map[string]string{}}
+}
+
+// OpenStore reads and decrypts the secrets store file.
+func OpenStore(fs afero.Fs, path, passphrase string) (*Store, error) {
+	content, err := afero.ReadFile(fs, path)
+	if err != nil {
+		return nil, err
+	}
+	file := storeFile{}
+	if err := json.Unmarshal(content, &file); err != nil {
+		return nil, ErrWrongPassphrase
+	}
+	if file.Version != storeVersion {
+		return nil, errors.New("unsupported secrets file version")
+	}
+
+	gcm, err := newGCM(passphrase, file.Salt)
+	if err != nil {
+		return nil, err
+	}
+	plain, err := gcm.Open(nil, file.Nonce, file.Data, nil)
+	if err != nil {
+		return nil, ErrWrongPassphrase
+	}
+
+	s := NewStore()
+	if err := json.Unmarshal(plain, &s.secrets); err != nil {
+		return nil, ErrWrongPassphrase
+	}
+	return s, nil
+}
+
+// Get returns the secret for the environment.
+func (s *Store) Get(env, key string) (string, bool) {
+	value, exists := s.secrets[env][key]
+	return value, exists
+}
+
+// Set adds or replaces the secret for the environment.
+func (s *Store) Set(env, key, value string) {
+	if s.secrets[env] == nil {
+		s.secrets[env] = map[string]string{}
+	}
+	s.secrets[env][key] = value
+}
+
+// Keys returns the sorted names of the secrets for the environment.
+func (s *Store) Keys(env string) []string {
+	keys := []string{}
+	for key := range s.secrets[env] {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// Save encrypts the store with the passphrase and writes it to the file, readable by the owner only.
+// A new salt and nonce are generated each time.
+func (s *Store) Save(fs afero.Fs, path, passphrase string) error {
+	plain, err := json.Marshal(s.secrets)
+	if err != nil {
+		return err
+	}
+
+	file := storeFile{Version: storeVersion, Salt: make([]byte, saltLen)}
+	if _, err := io.ReadFull(rand.Reader, file.Salt); err != nil {
+		return err
+	}
+	gcm, err := newGCM(passphrase, file.Salt)
+	if err != nil {
+		return err
+	}
+	file.Nonce = make([]byte, gcm.NonceSize())
+	if _, err := io.ReadFull(rand.Reader, file.Nonce); err != nil {
+		return err
+	}
+	file.Data = gcm.Seal(nil, file.Nonce, plain, nil)
+
+	content, err := json.MarshalIndent(file, "", "  ")
+	if err != nil {
+		return err
+	}
+	if err := fs.MkdirAll(filepath.Dir(path), os.ModePerm); err != nil {
+		return err
+	}
+	return afero.WriteFile(fs, path, content, 0600)
+}
+
+func newGCM(passphrase string, salt []byte) (cipher.AEAD, error) {
+	if passphrase == "" {
+		return nil, errors.New("the passphrase cannot be empty")
+	}
+	key, err := scrypt.Key([]byte(passphrase), salt, scryptN, scryptR, scryptP, scryptKeyLen)
+	if err != nil {
+		return nil, err
+	}
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	return cipher.NewGCM(block)
+}
@@ -0,0 +1,42 @@
+package secrets
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/matryer/is"
+	"github.com/spf13/afero"
+)
+
+func TestStore(t *testing.T) {
+	is := is.New(t)
+	fs := afero.NewMemMapFs()
+	path := ".sveltin/secrets.enc"
+
+	store := NewStore()
+	store.Set("production", "FTP_PASSWORD", "s3cr3t")
+	store.Set("staging", "FTP_PASSWORD", "staging-pwd")
+	store.Set("staging", "FTP_USER", "deployer")
+	is.NoErr(store.Save(fs, path, "correct horse"))
+
+	// the secrets are not stored in plaintext
+	content, err := afero.ReadFile(fs, path)
+	is.NoErr(err)
+	is.True(!strings.Contains(string(content), "s3cr3t"))
+	info, err := fs.Stat(path)
+	is.NoErr(err)
+	is.Equal(info.Mode().Perm().String(), "-rw-------")
+
+	opened, err := OpenStore(fs, path, "correct horse")
+	is.NoErr(err)
+	value, exists := opened.Get("production", "FTP_PASSWORD")
+	is.True(exists)
+	is.Equal(value, "s3cr3t")
+	_, exists = opened.Get("production", "FTP_USER")
+	is.True(!exists)
+	is.Equal(opened.Keys("staging"), []string{"FTP_PASSWORD", "FTP_USER"})
+
+	_, err = OpenStore(fs, path, "wrong horse")
+	is.Equal(err, ErrWrongPassphrase)
+	is.True(NewStore().Save(fs, path, "") != nil)
+}
@@ -10,6 +10,7 @@ package shell
 import (
 	"os"
 	"path/filepath"
+	"strconv"
 	"strings"
 
 	sveltinerr "github.com/sveltinio/sveltin/internal/errors"
@@ -93,6 +94,123 @@ func (s *GitShell) RunSubmodule(repoURL, inpath string, silentMode bool) error {
 	return nil
 }
 
+// RemoteBranchExists returns true when the branch exists on the remote, using 'git ls-remote'.
+func (s *GitShell) RemoteBranchExists(repoPath, remote, branch string) (bool, error) {
+	if repoPath == "" || remote == "" || branch == "" {
+		return false, sveltinerr.NewNotValidArgumentsError()
+	}
+	args := []string{"-C", repoPath, "ls-remote", "--heads", remote, branch}
+	output, err := s.GetShell().Output(GitBin, args)
+	if err != nil {
+		return false, sveltinerr.NewExecSystemCommandError(GitBin, strings.Join(args, " "))
+	}
+	return strings.TrimSpace(string(output)) != "", nil
+}
+
+// LocalBranchExists returns true when the branch exists in the repository, using 'git for-each-ref'.
+func (s *GitShell) LocalBranchExists(repoPath, branch string) (bool, error) {
+	if repoPath == "" || branch == "" {
+		return false, sveltinerr.NewNotValidArgumentsError()
+	}
+	args := []string{"-C", repoPath, "for-each-ref", "--format=%(refname)", "refs/heads/" + branch}
+	output, err := s.GetShell().Output(GitBin, args)
+	if err != nil {
+		return false, sveltinerr.NewExecSystemCommandError(GitBin, strings.Join(args, " "))
+	}
+	return strings.TrimSpace(string(output)) != "", nil
+}
+
+// CountCommitsAhead returns the number of commits on the branch not reachable from upstream, using 'git rev-list'.
+func (s *GitShell) CountCommitsAhead(repoPath, branch, upstream string) (int, error) {
+	if repoPath == "" || branch == "" || upstream == "" {
+		return 0, sveltinerr.NewNotValidArgumentsError()
+	}
+	args := []string{"-C", repoPath, "rev-list", "--count", upstream + ".." + branch}
+	output, err := s.GetShell().Output(GitBin, args)
+	if err != nil {
+		return 0, sveltinerr.NewExecSystemCommandError(GitBin, strings.Join(args, " "))
+	}
+	return strconv.Atoi(strings.TrimSpace(string(output)))
+}
+
+// RunFetch execute 'git fetch' command for the branch.
+func (s *GitShell) RunFetch(repoPath, remote, branch string, silentMode bool) error {
+	return s.runGit(silentMode, "-C", repoPath, "fetch", "--quiet", remote, branch)
+}
+
+// RunWorktreeAdd execute 'git worktree add' command to check out the branch, reset to startPoint, into worktreePath.
+// It fails when the branch is already checked out in another worktree.
+func (s *GitShell) RunWorktreeAdd(repoPath, worktreePath, branch, startPoint string, silentMode bool) error {
+	if worktreePath == "" || branch == "" || startPoint == "" {
+		return sveltinerr.NewNotValidArgumentsError()
+	}
+	if err := s.runGit(silentMode, "-C", repoPath, "worktree", "prune"); err != nil {
+		return err
+	}
+	return s.runGit(silentMode, "-C", repoPath, "worktree", "add", "--quiet", "-B", branch, worktreePath, startPoint)
+}
+
+// RunWorktreeAddOrphan execute 'git worktree add' command to create a new branch with no history
+// and no files into worktreePath.
+func (s *GitShell) RunWorktreeAddOrphan(repoPath, worktreePath, branch string, silentMode bool) error {
+	if worktreePath == "" || branch == "" {
+		return sveltinerr.NewNotValidArgumentsError()
+	}
+	if err := s.runGit(silentMode, "-C", repoPath, "worktree", "prune"); err != nil {
+		return err
+	}
+	if err := s.runGit(silentMode, "-C", repoPath, "worktree", "add", "--quiet", "--detach", worktreePath); err != nil {
+		return err
+	}
+	if err := s.runGit(silentMode, "-C", worktreePath, "checkout", "--quiet", "--orphan", branch); err != nil {
+		return err
+	}
+	return s.runGit(silentMode, "-C", worktreePath, "rm", "-r", "-f", "--quiet", "--ignore-unmatch", ".")
+}
+
+// RunWorktreeRemove execute 'git worktree remove' command.
+func (s *GitShell) RunWorktreeRemove(repoPath, worktreePath string, silentMode bool) error {
+	return s.runGit(silentMode, "-C", repoPath, "worktree", "remove", "--force", worktreePath)
+}
+
+// RunAddAll execute 'git add --all' command within the working tree.
+func (s *GitShell) RunAddAll(worktreePath string, silentMode bool) error {
+	return s.runGit(silentMode, "-C", worktreePath, "add", "--all")
+}
+
+// HasStagedChanges returns true when there is something to commit within the working tree.
+func (s *GitShell) HasStagedChanges(worktreePath string) (bool, error) {
+	args := []string{"-C", worktreePath, "status", "--porcelain"}
+	output, err := s.GetShell().Output(GitBin, args)
+	if err != nil {
+		return false, sveltinerr.NewExecSystemCommandError(GitBin, strings.Join(args, " "))
+	}
+	return strings.TrimSpace(string(output)) != "", nil
+}
+
+// RunCommit execute 'git commit' command within the working tree.
+func (s *GitShell) RunCommit(worktreePath, message string, silentMode bool) error {
+	if message == "" {
+		return sveltinerr.NewNotValidArgumentsError()
+	}
+	return s.runGit(silentMode, "-C", worktreePath, "commit", "--quiet", "-m", message)
+}
+
+// RunPush execute 'git push' command to update the remote branch with the working tree HEAD.
+func (s *GitShell) RunPush(worktreePath, remote, branch string, silentMode bool) error {
+	if remote == "" || branch == "" {
+		return sveltinerr.NewNotValidArgumentsError()
+	}
+	return s.runGit(silentMode, "-C", worktreePath, "push", "--quiet", remote, "HEAD:refs/heads/"+branch)
+}
+
+func (s *GitShell) runGit(silentMode bool, args ...string) error {
+	if err := s.GetShell().ExecuteArgs(GitBin, args, silentMode); err != nil {
+		return sveltinerr.NewExecSystemCommandError(GitBin, strings.Join(args, " "))
+	}
+	return nil
+}
+
 func cleanGitRepository(inpath string, foldersToRemove []string) error {
 	var err error
 	for _, folder := range foldersToRemove {
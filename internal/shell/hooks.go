@@ -0,0 +1,65 @@
+/**
+ * Copyright © 2021-present Sveltin contributors <github@sveltin.io>
+ *
+ * Use of this source code is governed by Apache 2.0 license
+ * that can be found in the LICENSE file.
+ */
+
+package shell
+
+import (
+	"io"
+	"os"
+	"os/exec"
+	"runtime"
+
+	sveltinerr "github.com/sveltinio/sveltin/internal/errors"
+)
+
+// Names of the lifecycle hooks that can be set in the sveltin.json file.
+const (
+	PreBuildHook    string = "pre-build"
+	PostBuildHook   string = "post-build"
+	PreDeployHook   string = "pre-deploy"
+	PostDeployHook  string = "post-deploy"
+	PostMigrateHook string = "post-migrate"
+)
+
+// HookShell is used to run the lifecycle hook commands through the system shell.
+type HookShell struct {
+	dir    string
+	env    []string
+	Stdout io.Writer
+	Stderr io.Writer
+}
+
+// NewHookShell returns a pointer to a HookShell running the commands within dir.
+// The env variables (KEY=value) are added to the ones of the current process.
+func NewHookShell(dir string, env []string) *HookShell {
+	return &HookShell{
+		dir:    dir,
+		env:    env,
+		Stdout: os.Stdout,
+		Stderr: os.Stderr,
+	}
+}
+
+// Run executes the hook command with sh -c (cmd /C on Windows). The hook name is
+// exposed to the command as SVELTIN_HOOK. A non-zero exit code is returned as error.
+func (s *HookShell) Run(hook, command string) error {
+	var cmd *exec.Cmd
+	if runtime.GOOS == "windows" {
+		cmd = exec.Command("cmd", "/C", command)
+	} else {
+		cmd = exec.Command("sh", "-c", command)
+	}
+	cmd.Dir = s.dir
+	cmd.Env = append(append(os.Environ(), s.env...), "SVELTIN_HOOK="+hook)
+	cmd.Stdout = s.Stdout
+	cmd.Stderr = s.Stderr
+
+	if err := cmd.Run(); err != nil {
+		return sveltinerr.NewHookFailedError(hook, command, err)
+	}
+	return nil
+}
@@ -0,0 +1,30 @@
+package shell
+
+import (
+	"bytes"
+	"runtime"
+	"testing"
+
+	"github.com/matryer/is"
+	sveltinerr "github.com/sveltinio/sveltin/internal/errors"
+)
+
+func TestHookShell(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("the hook commands are written for sh")
+	}
+	is := is.New(t)
+	dir := t.TempDir()
+
+	var out bytes.Buffer
+	hookShell := NewHookShell(dir, []string{"SVELTIN_ENV=staging"})
+	hookShell.Stdout = &out
+
+	is.NoErr(hookShell.Run(PreDeployHook, `echo "$SVELTIN_HOOK $SVELTIN_ENV" && pwd`))
+	is.Equal(out.String(), "pre-deploy staging\n"+dir+"\n")
+
+	err := hookShell.Run(PostDeployHook, "exit 3")
+	re, ok := err.(*sveltinerr.SveltinError)
+	is.True(ok)
+	is.Equal(re.Message, "the post-deploy hook failed running 'exit 3': exit status 3")
+}
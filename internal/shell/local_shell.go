@@ -52,6 +52,22 @@ func (s *LocalShell) Execute(cmdName string, cmdOptions string, silentMode bool)
 	return cmd.Run()
 }
 
+// ExecuteArgs runs command on the local system with the arguments as they are,
+// e.g. when an argument contains spaces.
+func (s *LocalShell) ExecuteArgs(cmdName string, args []string, silentMode bool) error {
+	cmd := exec.Command(cmdName, args...)
+	if !silentMode {
+		cmd.Stdout = os.Stdout
+	}
+	cmd.Stderr = os.Stderr
+	return cmd.Run()
+}
+
+// Output runs command on the local system and returns its standard output.
+func (s *LocalShell) Output(cmdName string, args []string) ([]byte, error) {
+	return exec.Command(cmdName, args...).Output()
+}
+
 // BackgroundExecute runs an action on the npm client in background.
 func (s *LocalShell) BackgroundExecute(ctx context.Context, cmdName string, cmdOptions string, packageList string) ([]byte, error) {
 	args := strings.Split(cmdOptions, " ")
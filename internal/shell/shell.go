@@ -13,5 +13,7 @@ import "context"
 // Shell is the interface defining the methods to be implemented by a shell instance.
 type Shell interface {
 	Execute(string, string, bool) error
+	ExecuteArgs(string, []string, bool) error
+	Output(string, []string) ([]byte, error)
 	BackgroundExecute(context.Context, string, string, string) ([]byte, error)
 }
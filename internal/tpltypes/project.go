@@ -8,26 +8,47 @@
 // Package tpltypes defines structs used to define data shared with template files.
 package tpltypes
 
-// EnvProductionData is the struct used to map the env.production file props.
+// EnvProductionData is the struct used to map the .env.production (or .env.<name>) file props.
 type EnvProductionData struct {
-	BaseURL         string `mapstructure:"VITE_PUBLIC_BASE_PATH"`
-	FTPHost         string `mapstructure:"FTP_HOST"`
-	FTPPort         int    `mapstructure:"FTP_PORT"`
-	FTPUser         string `mapstructure:"FTP_USER"`
-	FTPPassword     string `mapstructure:"FTP_PASSWORD"`
-	FTPServerFolder string `mapstructure:"FTP_SERVER_FOLDER"`
-	FTPDialTimeout  int    `mapstructure:"FTP_DIAL_TIMEOUT"`
-	FTPEPSVMode     bool   `mapstructure:"FTP_EPSV"`
+	BaseURL                  string `mapstructure:"VITE_PUBLIC_BASE_PATH"`
+	DeployProtocol           string `mapstructure:"DEPLOY_PROTOCOL"`
+	FTPHost                  string `mapstructure:"FTP_HOST"`
+	FTPPort                  int    `mapstructure:"FTP_PORT"`
+	FTPUser                  string `mapstructure:"FTP_USER"`
+	FTPPassword              string `mapstructure:"FTP_PASSWORD"`
+	FTPServerFolder          string `mapstructure:"FTP_SERVER_FOLDER"`
+	FTPDialTimeout           int    `mapstructure:"FTP_DIAL_TIMEOUT"`
+	FTPEPSVMode              bool   `mapstructure:"FTP_EPSV"`
+	FTPTLSMode               string `mapstructure:"FTP_TLS"`
+	FTPTLSCAFile             string `mapstructure:"FTP_TLS_CA_FILE"`
+	FTPTLSSkipVerify         bool   `mapstructure:"FTP_TLS_SKIP_VERIFY"`
+	SFTPPrivateKey           string `mapstructure:"SFTP_PRIVATE_KEY"`
+	SFTPPrivateKeyPassphrase string `mapstructure:"SFTP_PRIVATE_KEY_PASSPHRASE"`
+	SFTPKnownHosts           string `mapstructure:"SFTP_KNOWN_HOSTS"`
+	SFTPIgnoreHostKey        bool   `mapstructure:"SFTP_IGNORE_HOST_KEY"`
+	S3Endpoint               string `mapstructure:"S3_ENDPOINT"`
+	S3Region                 string `mapstructure:"S3_REGION"`
+	S3Bucket                 string `mapstructure:"S3_BUCKET"`
+	S3AccessKey              string `mapstructure:"S3_ACCESS_KEY"`
+	S3SecretKey              string `mapstructure:"S3_SECRET_KEY"`
+	S3UseSSL                 bool   `mapstructure:"S3_USE_SSL"`
+	S3CacheControl           string `mapstructure:"S3_CACHE_CONTROL"`
+	GitRemote                string `mapstructure:"GIT_REMOTE"`
+	GitBranch                string `mapstructure:"GIT_BRANCH"`
+	GitCommitMessage         string `mapstructure:"GIT_COMMIT_MESSAGE"`
 }
 
 // ProjectSettings is the struct used to map the sveltin.json file props.
 type ProjectSettings struct {
-	Name      string         `mapstructure:"name" json:"name" validate:"required"`
-	BaseURL   string         `mapstructure:"baseurl" json:"baseurl" validate:"required,url"`
-	SvelteKit SvelteKitData  `mapstructure:"sveltekit" json:"sveltekit" validate:"required"`
-	Theme     ThemeData      `mapstructure:"theme" json:"theme" validate:"required"`
-	Sitemap   SitemapData    `mapstructure:"sitemap" json:"sitemap" validate:"required"`
-	Sveltin   SveltinCLIData `mapstructure:"sveltin" json:"sveltin" validate:"required"`
+	Name         string         `mapstructure:"name" json:"name" validate:"required"`
+	BaseURL      string         `mapstructure:"baseurl" json:"baseurl" validate:"required,url"`
+	SvelteKit    SvelteKitData  `mapstructure:"sveltekit" json:"sveltekit" validate:"required"`
+	Theme        ThemeData      `mapstructure:"theme" json:"theme" validate:"required"`
+	Sitemap      SitemapData    `mapstructure:"sitemap" json:"sitemap" validate:"required"`
+	Sveltin      SveltinCLIData `mapstructure:"sveltin" json:"sveltin" validate:"required"`
+	Environments []string       `mapstructure:"environments" json:"environments,omitempty"`
+	Backups      BackupsData    `mapstructure:"backups" json:"backups"`
+	Hooks        HooksData      `mapstructure:"hooks" json:"hooks"`
 }
 
 // SvelteKitData is the struct used to map sveltekit config props.
@@ -43,6 +64,24 @@ type SvelteKitAdapterData struct {
 	Assets string `mapstructure:"assets" json:"assets" validate:"required"`
 }
 
+// BackupsData is the struct used to map the retention policy for the deploy backups.
+// Keep is the number of archives to be kept, MaxAge the max age for an archive (e.g. 30d).
+// A zero value disables the rule.
+type BackupsData struct {
+	Keep   int    `mapstructure:"keep" json:"keep"`
+	MaxAge string `mapstructure:"maxAge" json:"maxAge"`
+}
+
+// HooksData is the struct used to map the lifecycle hooks. Each hook is a list
+// of shell commands executed in order from the project root.
+type HooksData struct {
+	PreBuild    []string `mapstructure:"pre-build" json:"pre-build"`
+	PostBuild   []string `mapstructure:"post-build" json:"post-build"`
+	PreDeploy   []string `mapstructure:"pre-deploy" json:"pre-deploy"`
+	PostDeploy  []string `mapstructure:"post-deploy" json:"post-deploy"`
+	PostMigrate []string `mapstructure:"post-migrate" json:"post-migrate"`
+}
+
 // SveltinCLIData is the struct used to map the sveltin cli props.
 type SveltinCLIData struct {
 	Version string `mapstructure:"version" json:"version" validate:"required,semver"`
@@ -9,9 +9,14 @@ package feedbacks
 
 import (
 	"fmt"
+	"os"
+	"strings"
+	"text/tabwriter"
 
 	"github.com/sveltinio/sveltin/config"
+	"github.com/sveltinio/sveltin/internal/ftpfs"
 	"github.com/sveltinio/sveltin/internal/markup"
+	"github.com/sveltinio/sveltin/internal/migrations"
 	"github.com/sveltinio/sveltin/internal/tpltypes"
 	"github.com/sveltinio/sveltin/utils"
 	logger "github.com/sveltinio/yinlog"
@@ -97,7 +102,7 @@ func ShowDryRunMessage() {
 }
 
 // ShowDeployCommandWarningMessages display a set of useful information for the deploy over FTP process.
-func ShowDeployCommandWarningMessages(isBackup bool) {
+func ShowDeployCommandWarningMessages(isBackup, isIncremental, isAtomic bool) {
 	listLogger := logger.NewListLogger()
 	listLogger.Logger.Printer.SetPrinterOptions(&logger.PrinterOptions{
 		Timestamp: false,
@@ -110,11 +115,160 @@ func ShowDeployCommandWarningMessages(isBackup bool) {
 	if isBackup {
 		listLogger.Append(logger.WarningLevel, "Create a backup of the existing content on the remote folder")
 	}
+	if isAtomic {
+		listLogger.Append(logger.WarningLevel, "Upload content to a staging folder next to the remote one")
+		listLogger.Append(logger.WarningLevel, "Swap the staging folder into place and keep the replaced content as previous release")
+	} else if isIncremental {
+		listLogger.Append(logger.WarningLevel, "Delete files removed since the previous deploy except what specified with --exclude or --withExcludeFile flags")
+		listLogger.Append(logger.WarningLevel, "Upload new and changed content to the remote folder")
+	} else {
+		listLogger.Append(logger.WarningLevel, "Delete existing content except what specified with --exclude or --withExcludeFile flags")
+		listLogger.Append(logger.WarningLevel, "Upload content to the remote folder")
+	}
+	listLogger.Render()
+}
+
+// ShowRollbackCommandWarningMessages display a set of useful information for the rollback process.
+func ShowRollbackCommandWarningMessages(archiveName string) {
+	listLogger := logger.NewListLogger()
+	listLogger.Logger.Printer.SetPrinterOptions(&logger.PrinterOptions{
+		Timestamp: false,
+		Colors:    true,
+		Labels:    true,
+		Icons:     true,
+	})
+
+	listLogger.Title("Be aware! The rollback command will perform the following actions")
 	listLogger.Append(logger.WarningLevel, "Delete existing content except what specified with --exclude or --withExcludeFile flags")
-	listLogger.Append(logger.WarningLevel, "Upload content to the remote folder")
+	listLogger.Append(logger.WarningLevel, fmt.Sprintf("Upload the content of the backup archive '%s' to the remote folder", archiveName))
+	listLogger.Render()
+}
+
+// ShowBackupsList prints the backup archives with their date and size.
+func ShowBackupsList(backupsFolder string, archives []ftpfs.BackupArchive) {
+	listLogger := logger.NewListLogger()
+	listLogger.Logger.Printer.SetPrinterOptions(&logger.PrinterOptions{
+		Timestamp: false,
+		Colors:    true,
+		Labels:    false,
+		Icons:     true,
+	})
+
+	listLogger.Title(fmt.Sprintf("%d backup archives in %s", len(archives), backupsFolder))
+	for _, archive := range archives {
+		listLogger.Append(logger.DefaultLevel, fmt.Sprintf("%s  %s  %s",
+			archive.Name, markup.Faint(archive.ModTime.Format("2006-01-02 15:04:05")), utils.HumanizeBytes(archive.Size)))
+	}
+	listLogger.Render()
+}
+
+// ShowBackupContent prints the files within a backup archive with their size.
+func ShowBackupContent(archive *ftpfs.BackupArchive, entries []ftpfs.BackupEntry) {
+	listLogger := logger.NewListLogger()
+	listLogger.Logger.Printer.SetPrinterOptions(&logger.PrinterOptions{
+		Timestamp: false,
+		Colors:    true,
+		Labels:    false,
+		Icons:     true,
+	})
+
+	var totalSize int64
+	for _, entry := range entries {
+		totalSize += entry.Size
+	}
+	listLogger.Title(fmt.Sprintf("%s: %d files, %s", archive.Name, len(entries), utils.HumanizeBytes(totalSize)))
+	for _, entry := range entries {
+		listLogger.Append(logger.DefaultLevel, fmt.Sprintf("%s  %s", entry.Name, markup.Faint(utils.HumanizeBytes(entry.Size))))
+	}
+	listLogger.Render()
+}
+
+// ShowRemoteDir prints the entries of a remote folder with their size and last modification time.
+func ShowRemoteDir(title string, entries []ftpfs.RemoteDirEntry) {
+	listLogger := logger.NewListLogger()
+	listLogger.Logger.Printer.SetPrinterOptions(&logger.PrinterOptions{
+		Timestamp: false,
+		Colors:    true,
+		Labels:    false,
+		Icons:     true,
+	})
+
+	listLogger.Title(title)
+	for _, entry := range entries {
+		name := entry.Name
+		if entry.IsDir {
+			name = markup.Blue(entry.Name+"/") + markup.Faint(fmt.Sprintf(" (%s)", filesCount(entry.Files)))
+		}
+		listLogger.Append(logger.DefaultLevel, fmt.Sprintf("%s  %9s  %s",
+			markup.Faint(entry.ModTime.Format("2006-01-02 15:04:05")), utils.HumanizeBytes(entry.Size), name))
+	}
+	listLogger.Render()
+}
+
+// ShowVerifyResult prints the remote files missing, extra or mismatched compared to the local build.
+func ShowVerifyResult(result *ftpfs.VerifyResult) {
+	listLogger := logger.NewListLogger()
+	listLogger.Logger.Printer.SetPrinterOptions(&logger.PrinterOptions{
+		Timestamp: false,
+		Colors:    true,
+		Labels:    true,
+		Icons:     true,
+	})
+
+	listLogger.Title(fmt.Sprintf("Verification: %d files verified, %d missing, %d extra, %d mismatched",
+		result.Verified, len(result.Missing), len(result.Extra), len(result.Mismatched)))
+	for _, file := range result.Missing {
+		listLogger.Append(logger.ErrorLevel, fmt.Sprintf("missing     %s", file))
+	}
+	for _, file := range result.Extra {
+		listLogger.Append(logger.WarningLevel, fmt.Sprintf("extra       %s", file))
+	}
+	for _, file := range result.Mismatched {
+		listLogger.Append(logger.ErrorLevel, fmt.Sprintf("mismatched  %s", file))
+	}
 	listLogger.Render()
 }
 
+// ShowDeployPreview prints the changes the deploy makes to the remote folder as a tree, with summary counts and sizes.
+func ShowDeployPreview(remoteFolder string, preview *ftpfs.DeployPreview) {
+	styles := map[string]struct {
+		marker string
+		render func(string) string
+	}{
+		ftpfs.ChangeAdded:       {"+", markup.Green},
+		ftpfs.ChangeOverwritten: {"~", markup.Yellow},
+		ftpfs.ChangeDeleted:     {"-", markup.Amber},
+		ftpfs.ChangePreserved:   {"=", markup.Blue},
+		ftpfs.ChangeUnchanged:   {" ", markup.Faint},
+	}
+
+	fmt.Println(markup.H2(fmt.Sprintf("Changes to the remote folder: %s", remoteFolder)))
+	if len(preview.Entries) == 0 {
+		fmt.Println(markup.Faint("Nothing to deploy"))
+		return
+	}
+	for _, node := range preview.Tree() {
+		style, isUniform := styles[node.Change]
+		if !isUniform {
+			fmt.Println(markup.Faint(node.Prefix) + node.Name)
+			continue
+		}
+		line := fmt.Sprintf("%s %s", style.marker, node.Name)
+		if node.Files > 1 || strings.HasSuffix(node.Name, "/") {
+			line = fmt.Sprintf("%s (%s, %s)", line, filesCount(node.Files), utils.HumanizeBytes(node.Size))
+		}
+		fmt.Println(markup.Faint(node.Prefix) + style.render(line))
+	}
+
+	summary := []string{}
+	for _, change := range []string{ftpfs.ChangeAdded, ftpfs.ChangeOverwritten, ftpfs.ChangeDeleted, ftpfs.ChangePreserved, ftpfs.ChangeUnchanged} {
+		if total, exists := preview.Summary[change]; exists {
+			summary = append(summary, styles[change].render(fmt.Sprintf("%d %s (%s)", total.Files, change, utils.HumanizeBytes(total.Bytes))))
+		}
+	}
+	fmt.Printf("\n%s\n", strings.Join(summary, ", "))
+}
+
 // ShowUpgradeCommandMessage display a set of useful information when running the upgrade command.
 func ShowUpgradeCommandMessage() {
 	listLogger := logger.NewListLogger()
@@ -157,3 +311,88 @@ func themingInfoMessage() string {
 				markup.A("https://docs.sveltin.io/theming"),
 		})
 }
+
+// filesCount returns the number of files as text, e.g. "1 file" or "3 files".
+func filesCount(n int) string {
+	if n == 1 {
+		return "1 file"
+	}
+	return fmt.Sprintf("%d files", n)
+}
+
+// ShowUnifiedDiff prints a unified diff, the added lines in green and the removed ones in amber.
+func ShowUnifiedDiff(diff string) {
+	for _, line := range strings.Split(strings.TrimSuffix(diff, "\n"), "\n") {
+		switch {
+		case strings.HasPrefix(line, "---"), strings.HasPrefix(line, "+++"):
+			fmt.Println(markup.Bold(line))
+		case strings.HasPrefix(line, "@@"):
+			fmt.Println(markup.Blue(line))
+		case strings.HasPrefix(line, "+"):
+			fmt.Println(markup.Green(line))
+		case strings.HasPrefix(line, "-"):
+			fmt.Println(markup.Amber(line))
+		default:
+			fmt.Println(line)
+		}
+	}
+	fmt.Println()
+}
+
+// ShowMigrationsSummary prints the migrations, in execution order, with the files each one would change.
+func ShowMigrationsSummary(names []string, changedFiles map[string][]string) {
+	fmt.Println(markup.H2("Migrations"))
+	fired := 0
+	for _, name := range names {
+		files := changedFiles[name]
+		if len(files) == 0 {
+			fmt.Println(markup.Faint(fmt.Sprintf("  %s (nothing to migrate)", name)))
+			continue
+		}
+		fired++
+		fmt.Println(markup.Green(fmt.Sprintf("+ %s (%s)", name, filesCount(len(files)))))
+		for _, file := range files {
+			fmt.Println(markup.Faint("    " + file))
+		}
+	}
+	fmt.Printf("\n%d of %d migrations would run\n", fired, len(names))
+}
+
+// ShowMigrateRollback prints the files a rollback restores and the ones it removes.
+func ShowMigrateRollback(run *migrations.JournalRun) {
+	fmt.Println(markup.H2(fmt.Sprintf("%s to be restored from %s", filesCount(len(run.Files)), run.Snapshot)))
+	for _, file := range run.Files {
+		if file.Existed {
+			fmt.Println(markup.Yellow("~ " + file.Path))
+		} else {
+			fmt.Println(markup.Amber("- " + file.Path + " (created by the migrations)"))
+		}
+	}
+	fmt.Println()
+}
+
+// ShowMigrationsStatus prints the migrations as a table with their target and state.
+func ShowMigrationsStatus(projectCliVersion, cliVersion string, statuses []migrations.MigrationStatus) {
+	styles := map[string]func(string) string{
+		migrations.StatePending:       markup.Yellow,
+		migrations.StateApplied:       markup.Green,
+		migrations.StateNotApplicable: markup.Faint,
+	}
+
+	fmt.Println(markup.H2(fmt.Sprintf("Migrations from sveltin v%s to v%s", projectCliVersion, cliVersion)))
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "MIGRATION\tTARGET\tSINCE\tAPPLIED\tSTATE")
+	pending := 0
+	for _, status := range statuses {
+		if status.State == migrations.StatePending {
+			pending++
+		}
+		appliedAt := "-"
+		if status.AppliedAt != nil {
+			appliedAt = status.AppliedAt.Format("2006-01-02 15:04:05")
+		}
+		fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\n", status.Name, status.Target, status.Since, appliedAt, styles[status.State](status.State))
+	}
+	w.Flush()
+	fmt.Printf("\n%d of %d migrations pending\n", pending, len(statuses))
+}
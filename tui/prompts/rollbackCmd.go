@@ -0,0 +1,41 @@
+package prompts
+
+import (
+	"github.com/charmbracelet/bubbles/list"
+	"github.com/sveltinio/prompti/choose"
+	sveltinerr "github.com/sveltinio/sveltin/internal/errors"
+	"github.com/sveltinio/sveltin/internal/ftpfs"
+	"github.com/sveltinio/sveltin/utils"
+)
+
+// SelectBackupHandler if not flag passed, prompts the user to select the backup archive to restore.
+// Archives are expected to be not empty and sorted newest first.
+func SelectBackupHandler(archives []ftpfs.BackupArchive, isLatest bool) (*ftpfs.BackupArchive, error) {
+	if isLatest {
+		return &archives[0], nil
+	}
+
+	entries := []list.Item{}
+	for _, archive := range archives {
+		entries = append(entries, choose.Item{
+			Name: archive.Name,
+			Desc: archive.ModTime.Format("2006-01-02 15:04:05") + " · " + utils.HumanizeBytes(archive.Size),
+		})
+	}
+
+	backupPromptContent := &choose.Config{
+		Title:    "Which backup do you want to restore?",
+		ErrorMsg: "Please, select a backup archive.",
+	}
+	result, err := choose.Run(backupPromptContent, entries)
+	if err != nil {
+		return nil, err
+	}
+
+	for i := range archives {
+		if archives[i].Name == result {
+			return &archives[i], nil
+		}
+	}
+	return nil, sveltinerr.NewFileNotFoundError(result)
+}
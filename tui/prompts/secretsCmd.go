@@ -0,0 +1,46 @@
+package prompts
+
+import (
+	"errors"
+
+	"github.com/sveltinio/prompti/input"
+	sveltinerr "github.com/sveltinio/sveltin/internal/errors"
+)
+
+// AskPassphraseHandler prompts the user to type the passphrase for the secrets store.
+// When isNew is true, the passphrase must be typed twice.
+func AskPassphraseHandler(isNew bool) (string, error) {
+	passphrasePromptContent := &input.Config{
+		Placeholder: "What's the passphrase for the secrets store?",
+		ErrorMsg:    "Please, provide the passphrase.",
+		Password:    true,
+	}
+	passphrase, err := input.Run(passphrasePromptContent)
+	if err != nil || !isNew {
+		return passphrase, err
+	}
+
+	confirmPromptContent := &input.Config{
+		Placeholder: "Please, type the passphrase again",
+		ErrorMsg:    "Please, confirm the passphrase.",
+		Password:    true,
+	}
+	confirmation, err := input.Run(confirmPromptContent)
+	if err != nil {
+		return "", err
+	}
+	if confirmation != passphrase {
+		return "", sveltinerr.NewDefaultError(errors.New("the passphrases do not match"))
+	}
+	return passphrase, nil
+}
+
+// AskSecretValueHandler prompts the user to type the value for the secret.
+func AskSecretValueHandler(key string) (string, error) {
+	valuePromptContent := &input.Config{
+		Placeholder: "What's the value for " + key + "?",
+		ErrorMsg:    "Please, provide a value.",
+		Password:    true,
+	}
+	return input.Run(valuePromptContent)
+}
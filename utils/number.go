@@ -1,5 +1,7 @@
 package utils
 
+import "fmt"
+
 // PlusOne adds one to the integer parameter.
 func PlusOne(x int) int {
 	return x + 1
@@ -14,3 +16,17 @@ func MinusOne(x int) int {
 func Sum(x int, y int) int {
 	return x + y
 }
+
+// HumanizeBytes returns the size in bytes as human readable string (e.g. 1.5 KB).
+func HumanizeBytes(size int64) string {
+	const unit = 1024
+	if size < unit {
+		return fmt.Sprintf("%d B", size)
+	}
+	div, exp := int64(unit), 0
+	for n := size / unit; n >= unit; n /= unit {
+		div *= unit
+		exp++
+	}
+	return fmt.Sprintf("%.1f %cB", float64(size)/float64(div), "KMGTPE"[exp])
+}
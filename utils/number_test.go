@@ -12,4 +12,7 @@ func TestNumberUtils(t *testing.T) {
 	is.Equal(2, PlusOne(1))
 	is.Equal(3, Sum(1, 2))
 	is.Equal(4, MinusOne(5))
+	is.Equal("512 B", HumanizeBytes(512))
+	is.Equal("1.5 KB", HumanizeBytes(1536))
+	is.Equal("2.0 MB", HumanizeBytes(2*1024*1024))
 }